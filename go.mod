@@ -4,13 +4,14 @@ go 1.24.0
 
 require (
 	github.com/ethereum/go-ethereum v1.15.11
+	github.com/hdevalence/ed25519consensus v0.2.0
 	github.com/pkg/errors v0.9.1
-	github.com/shunsukew/scale-codec-go v0.0.0-20250510130205-b49457a7fc38
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/crypto v0.37.0
 )
 
 require (
+	filippo.io/edwards25519 v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/holiman/uint256 v1.3.2 // indirect
@@ -1,5 +1,68 @@
 package accumulate
 
-type AccumulationQueue struct{}
+import (
+	"maps"
+	"slices"
 
-type AccumulationHistory struct{}
+	"github.com/shunsukew/gojam/internal/jamtime"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// (12.3) θ ∈ ⟦⟦(W, {H})⟧⟧E
+// Work reports which are ready to be accumulated but waiting for their dependencies, indexed by timeslot in epoch.
+type AccumulationQueue [jamtime.TimeSlotsPerEpoch][]*QueuedWorkReport
+
+// (W, {H}): a work report with the work package hashes it still depends on.
+type QueuedWorkReport struct {
+	WorkReport   *workreport.WorkReport
+	Dependencies map[common.Hash]struct{}
+}
+
+func (q *AccumulationQueue) ContainsWorkPackage(workPackageHash common.Hash) bool {
+	for _, queued := range q {
+		for _, record := range queued {
+			if record.WorkReport.AvailabilitySpecification.WorkPackageHash == workPackageHash {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (q *AccumulationQueue) Clone() AccumulationQueue {
+	var cloned AccumulationQueue
+	for i, queued := range q {
+		if queued == nil {
+			continue
+		}
+
+		cloned[i] = make([]*QueuedWorkReport, len(queued))
+		for j, record := range queued {
+			cloned[i][j] = &QueuedWorkReport{WorkReport: record.WorkReport, Dependencies: maps.Clone(record.Dependencies)}
+		}
+	}
+
+	return cloned
+}
+
+// (12.1) ξ ∈ ⟦{H}⟧E
+// Work package hashes accumulated in each of the last E timeslots.
+type AccumulationHistory [jamtime.TimeSlotsPerEpoch]map[common.Hash]struct{}
+
+func (h *AccumulationHistory) ContainsWorkPackage(workPackageHash common.Hash) bool {
+	return slices.ContainsFunc(h[:], func(accumulated map[common.Hash]struct{}) bool {
+		_, ok := accumulated[workPackageHash]
+		return ok
+	})
+}
+
+func (h *AccumulationHistory) Clone() AccumulationHistory {
+	var cloned AccumulationHistory
+	for i, accumulated := range h {
+		cloned[i] = maps.Clone(accumulated)
+	}
+
+	return cloned
+}
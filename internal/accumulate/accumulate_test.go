@@ -0,0 +1,225 @@
+package accumulate
+
+import (
+	"slices"
+	"testing"
+
+	authqueue "github.com/shunsukew/gojam/internal/authorizer/queue"
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/work"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/shunsukew/gojam/pkg/merkle"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func newQueuedWorkReport(workPackageHash common.Hash, dependencies ...common.Hash) *QueuedWorkReport {
+	record := &QueuedWorkReport{
+		WorkReport: &workreport.WorkReport{
+			AvailabilitySpecification: &workreport.AvailabilitySpecification{WorkPackageHash: workPackageHash},
+		},
+		Dependencies: make(map[common.Hash]struct{}, len(dependencies)),
+	}
+	for _, dependency := range dependencies {
+		record.Dependencies[dependency] = struct{}{}
+	}
+	return record
+}
+
+func TestAccumulationQueueContainsWorkPackage(t *testing.T) {
+	queue := &AccumulationQueue{}
+	queue[3] = []*QueuedWorkReport{newQueuedWorkReport(common.Hash{1}, common.Hash{2})}
+
+	require.True(t, queue.ContainsWorkPackage(common.Hash{1}))
+	require.False(t, queue.ContainsWorkPackage(common.Hash{2}), "dependencies are not queued work packages")
+
+	cloned := queue.Clone()
+	delete(cloned[3][0].Dependencies, common.Hash{2})
+	require.Contains(t, queue[3][0].Dependencies, common.Hash{2})
+}
+
+func TestAccumulationHistoryContainsWorkPackage(t *testing.T) {
+	history := &AccumulationHistory{}
+	history[len(history)-1] = map[common.Hash]struct{}{{1}: {}}
+
+	require.True(t, history.ContainsWorkPackage(common.Hash{1}))
+	require.False(t, history.ContainsWorkPackage(common.Hash{2}))
+
+	cloned := history.Clone()
+	cloned[len(cloned)-1][common.Hash{2}] = struct{}{}
+	require.False(t, history.ContainsWorkPackage(common.Hash{2}))
+}
+
+func TestAccumulationHistoryEncode(t *testing.T) {
+	history := AccumulationHistory{}
+	history[0] = map[common.Hash]struct{}{{2}: {}, {1}: {}}
+
+	encoded, err := codec.Encode(history)
+	require.NoError(t, err)
+
+	// E([↕[x^ ∣ x ∈ i] ∣ i <− ξ]): each set is encoded as a length prefixed sorted sequence.
+	first, second := common.Hash{1}, common.Hash{2}
+	expected := slices.Concat([]byte{2}, first[:], second[:], make([]byte, len(history)-1))
+	require.Equal(t, expected, encoded)
+
+	decoded := AccumulationHistory{}
+	require.NoError(t, codec.Decode(encoded, &decoded))
+	require.Equal(t, history[0], decoded[0])
+}
+
+func newWorkReport(workPackageHash common.Hash, gas service.Gas, prerequisites ...common.Hash) *workreport.WorkReport {
+	return &workreport.WorkReport{
+		AvailabilitySpecification: &workreport.AvailabilitySpecification{WorkPackageHash: workPackageHash},
+		RefinementContext:         &work.RefinementContext{PreRequisiteWorkPackageHashes: prerequisites},
+		WorkResults:               []*workreport.WorkResult{{Gas: gas}},
+	}
+}
+
+func TestEditQueue(t *testing.T) {
+	queue := []*QueuedWorkReport{
+		newQueuedWorkReport(common.Hash{1}, common.Hash{2}, common.Hash{3}),
+		newQueuedWorkReport(common.Hash{2}),
+	}
+
+	edited := editQueue(queue, map[common.Hash]struct{}{{2}: {}})
+	require.Len(t, edited, 1, "accumulated work package must be dropped")
+	require.Equal(t, common.Hash{1}, edited[0].WorkReport.AvailabilitySpecification.WorkPackageHash)
+	require.Equal(t, map[common.Hash]struct{}{{3}: {}}, edited[0].Dependencies)
+	require.Len(t, queue[0].Dependencies, 2, "original queue must not be modified")
+}
+
+func TestAccumulationPriority(t *testing.T) {
+	queue := []*QueuedWorkReport{
+		newQueuedWorkReport(common.Hash{3}, common.Hash{2}),
+		newQueuedWorkReport(common.Hash{2}, common.Hash{1}),
+		newQueuedWorkReport(common.Hash{1}),
+		newQueuedWorkReport(common.Hash{4}, common.Hash{5}),
+	}
+
+	accumulatable := accumulationPriority(queue)
+	require.Equal(t, []common.Hash{{1}, {2}, {3}}, workPackageHashList(accumulatable), "reports must be ordered by resolution of dependencies")
+}
+
+func TestUpdate(t *testing.T) {
+	const timeSlot = jamtime.TimeSlot(jamtime.TimeSlotsPerEpoch + 2)
+
+	queue := &AccumulationQueue{}
+	// Waits for {1}, which is available in this block.
+	queue[5] = []*QueuedWorkReport{newQueuedWorkReport(common.Hash{2}, common.Hash{1})}
+	// Waits for {9}, which is never accumulated.
+	queue[6] = []*QueuedWorkReport{newQueuedWorkReport(common.Hash{3}, common.Hash{9})}
+	history := &AccumulationHistory{}
+	history[1] = map[common.Hash]struct{}{{8}: {}}
+
+	availableReports := []*workreport.WorkReport{
+		newWorkReport(common.Hash{4}, 1, common.Hash{8}), // prerequisite is already accumulated
+		newWorkReport(common.Hash{1}, 1),
+		newWorkReport(common.Hash{5}, 1, common.Hash{9}),
+	}
+
+	outcome, err := Update(queue, history, timeSlot, timeSlot-1, availableReports, &PartialState{}, common.Hash{})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{{1}, {2}, {4}}, workPackageHashList(outcome.Accumulated), "queued reports come before newly available ones")
+
+	require.Equal(t, map[common.Hash]struct{}{{8}: {}}, history[0], "history must be shifted")
+	require.Equal(t, map[common.Hash]struct{}{{1}: {}, {2}: {}, {4}: {}}, history[len(history)-1])
+
+	m := timeSlot.ToTimeSlotInEpoch()
+	require.Len(t, queue[m], 1)
+	require.Equal(t, common.Hash{5}, queue[m][0].WorkReport.AvailabilitySpecification.WorkPackageHash)
+	require.Nil(t, queue[5], "resolved report must be removed from the queue")
+	require.Len(t, queue[6], 1)
+
+	// Timeslots skipped since the prior block are cleared.
+	_, err = Update(queue, history, timeSlot+jamtime.TimeSlotsPerEpoch-1, timeSlot, nil, &PartialState{}, common.Hash{})
+	require.NoError(t, err)
+	require.Nil(t, queue[6])
+	require.Len(t, queue[m], 1)
+}
+
+func TestAccumulatablePrefix(t *testing.T) {
+	reports := []*workreport.WorkReport{newWorkReport(common.Hash{1}, 5), newWorkReport(common.Hash{2}, 5), newWorkReport(common.Hash{3}, 1)}
+
+	require.Equal(t, 0, AccumulatablePrefix(4, reports))
+	require.Equal(t, 1, AccumulatablePrefix(9, reports))
+	require.Equal(t, 2, AccumulatablePrefix(10, reports))
+	require.Equal(t, 3, AccumulatablePrefix(11, reports))
+}
+
+func TestGasLimit(t *testing.T) {
+	require.Equal(t, service.Gas(max(service.TotalAccumulationGasLimit, service.WorkReportAccumulationGasLimit*common.NumOfCores)), GasLimit(nil))
+
+	alwaysAccumulate := map[service.ServiceId]service.Gas{1: service.TotalAccumulationGasLimit}
+	require.Equal(t, service.Gas(service.WorkReportAccumulationGasLimit*common.NumOfCores+service.TotalAccumulationGasLimit), GasLimit(alwaysAccumulate))
+}
+
+func workPackageHashList(reports []*workreport.WorkReport) []common.Hash {
+	hashes := make([]common.Hash, len(reports))
+	for i, report := range reports {
+		hashes[i] = report.AvailabilitySpecification.WorkPackageHash
+	}
+	return hashes
+}
+
+func TestUpdateIntegratesPrivilegedServices(t *testing.T) {
+	queue := make([]byte, common.HashLength*authqueue.AuthorizerQueueSize)
+	for i := range queue {
+		queue[i] = byte(i)
+	}
+
+	code := standardProgram(queue,
+		[]byte{51, 0x07},                   // load_imm ω7 0
+		[]byte{51, 0x08, 0x00, 0x00, 0x01}, // load_imm ω8 0x10000
+		[]byte{10, byte(hostcall.Assign)},
+		[]byte{51, 0x07, 7},                // load_imm ω7 7
+		[]byte{51, 0x08, 0x00, 0x00, 0x01}, // load_imm ω8 0x10000
+		[]byte{51, 0x09, 7},                // load_imm ω9 7
+		[]byte{51, 0x0a, 0x00, 0x00, 0x01}, // load_imm ω10 0x10000
+		[]byte{51, 0x0b},                   // load_imm ω11 0
+		[]byte{10, byte(hostcall.Bless)},
+		pvmtest.Halt)
+	state := newTestPartialState(code)
+	state.PrivilegedServices.Assigners[0] = testServiceId
+
+	report := newWorkReport(common.Hash{1}, 1000)
+	report.WorkResults[0].ServiceId = testServiceId
+	report.WorkResults[0].ExecResult = &workreport.ExecResult{Output: []byte{}}
+
+	outcome, err := Update(&AccumulationQueue{}, &AccumulationHistory{}, 1, 0, []*workreport.WorkReport{report}, state, common.Hash{})
+	require.NoError(t, err)
+	require.Len(t, outcome.Accumulated, 1)
+	require.NotZero(t, outcome.GasUsed[testServiceId])
+
+	var expected authqueue.AuthorizerQueue
+	for i := range expected {
+		copy(expected[i][:], queue[i*common.HashLength:])
+	}
+	require.Equal(t, &expected, outcome.State.AuthorizerQueues[0], "the assigner of the core alters its queue")
+	require.Nil(t, state.AuthorizerQueues[0], "the prior state is not mutated")
+
+	require.Equal(t, state.PrivilegedServices, outcome.State.PrivilegedServices, "only the manager alters the privileged services")
+}
+
+func TestResultRoot(t *testing.T) {
+	require.Equal(t, common.Hash{}, (&Outcome{}).ResultRoot())
+
+	// The pairs are ordered by service, then by hash, and each is encoded as E4(s) ⌢ E(h).
+	outcome := &Outcome{Yields: map[ServiceYield]struct{}{
+		{ServiceId: 2, Hash: common.Hash{1}}: {},
+		{ServiceId: 1, Hash: common.Hash{3}}: {},
+		{ServiceId: 1, Hash: common.Hash{2}}: {},
+	}}
+	encode := func(serviceId service.ServiceId, hash common.Hash) []byte {
+		return append(codec.EncodeUint(uint64(serviceId), 4), hash[:]...)
+	}
+	expected := merkle.Binary([][]byte{encode(1, common.Hash{2}), encode(1, common.Hash{3}), encode(2, common.Hash{1})}, merkle.Keccak)
+	require.Equal(t, expected, outcome.ResultRoot())
+
+	single := &Outcome{Yields: map[ServiceYield]struct{}{{ServiceId: 1, Hash: common.Hash{2}}: {}}}
+	require.Equal(t, crypto.Keccak256Hash(encode(1, common.Hash{2})), single.ResultRoot())
+}
@@ -0,0 +1,130 @@
+package accumulate
+
+import (
+	"bytes"
+	"slices"
+
+	authqueue "github.com/shunsukew/gojam/internal/authorizer/queue"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	minServiceId         = 1 << 8       // Service ids below this are reserved.
+	serviceIdSpace       = 1<<32 - 1<<9 // The range new service ids are chosen from.
+	newServiceIdBumpStep = 42           // The step between new service ids created in an accumulation.
+)
+
+// (12.13) U ≡ (d ∈ D⟨N_S → A⟩, i ∈ ⟦K⟧V, q ∈ ⟦⟦H⟧Q⟧C, x ∈ (N_S, ⟦N_S⟧C, N_S, D⟨N_S → N_G⟩))
+// The partial state, the components of the state which accumulation may alter.
+type PartialState struct {
+	Services           service.Services                            // d
+	StagingValidators  *[common.NumOfValidators]*keys.ValidatorKey // i
+	AuthorizerQueues   authqueue.AuthorizerQueues                  // q
+	PrivilegedServices service.PrivilegedServices                  // x
+}
+
+// Clone returns a deep copy of the partial state, so that it can be rolled back to.
+// Validator keys and authorizer queues are replaced as a whole by accumulation, so they are shared.
+func (u *PartialState) Clone() *PartialState {
+	return &PartialState{
+		Services:           u.Services.Clone(),
+		StagingValidators:  u.StagingValidators,
+		AuthorizerQueues:   u.AuthorizerQueues,
+		PrivilegedServices: u.PrivilegedServices.Clone(),
+	}
+}
+
+// (12.14) T ≡ (s ∈ N_S, d ∈ N_S, a ∈ N_B, m ∈ Y_WT, g ∈ N_G)
+type DeferredTransfer struct {
+	Sender      service.ServiceId              // s
+	Destination service.ServiceId              // d
+	Amount      service.Balance                // a
+	Memo        [service.TransferMemoSize]byte // m
+	Gas         service.Gas                    // g
+}
+
+// (12.18) O ≡ (h ∈ H, e ∈ H, a ∈ H, o ∈ Y, y ∈ H, d ∈ Y ∪ J)
+// The accumulation operand of a work result, given to the accumulate code.
+type Operand struct {
+	WorkPackageHash  common.Hash            // h
+	SegmentRoot      common.Hash            // e
+	AuthorizerHash   common.Hash            // a
+	AuthorizerOutput []byte                 // o
+	PayloadHash      common.Hash            // y
+	Result           *workreport.ExecResult // d
+}
+
+// A preimage provided to a service during accumulation, (N_S, Y).
+type ProvidedPreimage struct {
+	ServiceId service.ServiceId
+	Preimage  []byte
+}
+
+// (B.7) L ≡ (s ∈ N_S, u ∈ U, i ∈ N_S, t ∈ ⟦T⟧, y ∈ H?, p ∈ {(N_S, Y)})
+// The accumulation context of a service, mutated by host calls.
+type Context struct {
+	ServiceId     service.ServiceId  // s
+	State         *PartialState      // u
+	NextServiceId service.ServiceId  // i
+	Transfers     []DeferredTransfer // t
+	Yield         *common.Hash       // y
+	Provided      []ProvidedPreimage // p
+}
+
+// (B.9) I(u, s) ≡ (s, u, i, [], ∅, {}) where i = check((E4^-1(H(E4(s) ⌢ η′0 ⌢ E4(Ht))) mod (2³² − 2⁹)) + 2⁸)
+func newContext(state *PartialState, serviceId service.ServiceId, entropy common.Hash, timeSlot jamtime.TimeSlot) *Context {
+	seed := codec.EncodeUint(uint64(serviceId), 4)
+	seed = append(seed, entropy[:]...)
+	seed = append(seed, codec.EncodeUint(uint64(timeSlot), 4)...)
+	hash := blake2b.Sum256(seed)
+
+	d := codec.NewDecoder(hash[:4])
+	n, _ := d.DecodeUint(4)
+
+	return &Context{
+		ServiceId:     serviceId,
+		State:         state,
+		NextServiceId: checkServiceId(state, service.ServiceId(n%serviceIdSpace+minServiceId)),
+	}
+}
+
+// Clone returns a deep copy of the context, to checkpoint it as the exceptional context.
+func (x *Context) Clone() *Context {
+	cloned := *x
+	cloned.State = x.State.Clone()
+	cloned.Transfers = slices.Clone(x.Transfers)
+	cloned.Provided = slices.Clone(x.Provided)
+	return &cloned
+}
+
+func (x *Context) account() *service.ServiceAccount {
+	account, _ := x.State.Services.Get(x.ServiceId)
+	return account
+}
+
+func (x *Context) isProvided(serviceId service.ServiceId, preimage []byte) bool {
+	return slices.ContainsFunc(x.Provided, func(p ProvidedPreimage) bool {
+		return p.ServiceId == serviceId && bytes.Equal(p.Preimage, preimage)
+	})
+}
+
+// (B.10) check(i) ≡ i if i ∉ K(u_d), check((i − 2⁸ + 1) mod (2³² − 2⁹) + 2⁸) otherwise
+func checkServiceId(state *PartialState, serviceId service.ServiceId) service.ServiceId {
+	for {
+		if _, ok := state.Services.Get(serviceId); !ok {
+			return serviceId
+		}
+		serviceId = service.ServiceId((uint64(serviceId)-minServiceId+1)%serviceIdSpace + minServiceId)
+	}
+}
+
+// bump(i) ≡ 2⁸ + (i − 2⁸ + 42) mod (2³² − 2⁹)
+func bumpServiceId(serviceId service.ServiceId) service.ServiceId {
+	return service.ServiceId(minServiceId + (uint64(serviceId)-minServiceId+newServiceIdBumpStep)%serviceIdSpace)
+}
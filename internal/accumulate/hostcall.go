@@ -0,0 +1,465 @@
+package accumulate
+
+import (
+	"math"
+
+	authqueue "github.com/shunsukew/gojam/internal/authorizer/queue"
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"golang.org/x/crypto/blake2b"
+)
+
+// hostCalls dispatches the host calls of Ψ_A over the regular context x and the exceptional context y, Gray Paper (B.8).
+// The exceptional context is what remains after a panic or running out of gas, and is only advanced by checkpoint.
+type hostCalls struct {
+	regular     *Context         // x
+	exceptional *Context         // y
+	timeSlot    jamtime.TimeSlot // t
+}
+
+func (h *hostCalls) handle(id uint64, m *pvm.Machine) (pvm.ExitReason, bool) {
+	cost := hostcall.GasCost
+	if id == hostcall.Transfer {
+		// The gas limit of the transfer is charged in addition.
+		if m.Registers[9] > math.MaxInt64-uint64(cost) {
+			return hostcall.Exit(pvm.OutOfGas)
+		}
+		cost += pvm.Gas(m.Registers[9])
+	}
+	if !m.ConsumeGas(cost) {
+		return hostcall.Exit(pvm.OutOfGas)
+	}
+
+	x := h.regular
+	switch id {
+	case hostcall.Gas:
+		return hostcall.HandleGas(m)
+	case hostcall.Lookup:
+		return hostcall.HandleLookup(m, x.ServiceId, &x.State.Services)
+	case hostcall.Read:
+		return hostcall.HandleRead(m, x.ServiceId, &x.State.Services)
+	case hostcall.Write:
+		return hostcall.HandleWrite(m, x.ServiceId, &x.State.Services)
+	case hostcall.Info:
+		return hostcall.HandleInfo(m, x.ServiceId, &x.State.Services)
+	case hostcall.Bless:
+		return h.bless(m)
+	case hostcall.Assign:
+		return h.assign(m)
+	case hostcall.Designate:
+		return h.designate(m)
+	case hostcall.Checkpoint:
+		return h.checkpoint(m)
+	case hostcall.New:
+		return h.new(m)
+	case hostcall.Upgrade:
+		return h.upgrade(m)
+	case hostcall.Transfer:
+		return h.transfer(m)
+	case hostcall.Eject:
+		return h.eject(m)
+	case hostcall.Query:
+		return h.query(m)
+	case hostcall.Solicit:
+		return h.solicit(m)
+	case hostcall.Forget:
+		return h.forget(m)
+	case hostcall.Yield:
+		return h.yield(m)
+	case hostcall.Provide:
+		return h.provide(m)
+	}
+
+	return hostcall.Unknown(m)
+}
+
+// bless sets the privileged services to the manager ω7, the assigners µ[ω8...+4C], the designator ω9
+// and the always-accumulate services {(s ↦ g) ∣ E4(s) ⌢ E8(g) = µ[ω10 + 12i...+12], i ∈ N_ω11}, if the service is the manager.
+func (h *hostCalls) bless(m *pvm.Machine) (pvm.ExitReason, bool) {
+	manager, assignersAddress, designator, address, n := m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10], m.Registers[11]
+
+	encodedAssigners, err := m.ReadMemory(assignersAddress, 4*common.NumOfCores)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+	if n > math.MaxUint32 {
+		return hostcall.Exit(pvm.Panic)
+	}
+	encodedAlwaysAccumulate, err := m.ReadMemory(address, 12*n)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	if h.regular.ServiceId != h.regular.State.PrivilegedServices.Manager {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+	if manager > math.MaxUint32 || designator > math.MaxUint32 {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+
+	var assigners [common.NumOfCores]service.ServiceId
+	d := codec.NewDecoder(encodedAssigners)
+	for i := range assigners {
+		assigner, _ := d.DecodeUint(4)
+		assigners[i] = service.ServiceId(assigner)
+	}
+
+	alwaysAccumulate := make(map[service.ServiceId]service.Gas, n)
+	d = codec.NewDecoder(encodedAlwaysAccumulate)
+	for range n {
+		serviceId, _ := d.DecodeUint(4)
+		gas, _ := d.DecodeUint(8)
+		alwaysAccumulate[service.ServiceId(serviceId)] = service.Gas(gas)
+	}
+
+	h.regular.State.PrivilegedServices = service.PrivilegedServices{
+		Manager:          service.ServiceId(manager),
+		Assigners:        assigners,
+		Designator:       service.ServiceId(designator),
+		AlwaysAccumulate: alwaysAccumulate,
+	}
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// assign sets the authorizer queue of the core ω7 to µ[ω8...+32Q], if the service is the assigner of the core.
+func (h *hostCalls) assign(m *pvm.Machine) (pvm.ExitReason, bool) {
+	coreIndex := m.Registers[7]
+
+	encoded, err := m.ReadMemory(m.Registers[8], common.HashLength*authqueue.AuthorizerQueueSize)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	x := h.regular
+	if coreIndex >= common.NumOfCores {
+		m.Registers[7] = hostcall.CORE
+		return hostcall.Continue()
+	}
+	if x.ServiceId != x.State.PrivilegedServices.Assigners[coreIndex] {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	var queue authqueue.AuthorizerQueue
+	for i := range queue {
+		copy(queue[i][:], encoded[i*common.HashLength:])
+	}
+	x.State.AuthorizerQueues[coreIndex] = &queue
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// designate sets the staging validator keys to µ[ω7...+336V], if the service is the designator.
+func (h *hostCalls) designate(m *pvm.Machine) (pvm.ExitReason, bool) {
+	encoded, err := m.ReadMemory(m.Registers[7], keys.ValidatorKeySize*common.NumOfValidators)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	x := h.regular
+	if x.ServiceId != x.State.PrivilegedServices.Designator {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	var validators [common.NumOfValidators]*keys.ValidatorKey
+	for i := range validators {
+		key := &keys.ValidatorKey{}
+		if err := codec.Decode(encoded[i*keys.ValidatorKeySize:(i+1)*keys.ValidatorKeySize], key); err != nil {
+			return hostcall.Exit(pvm.Panic)
+		}
+		validators[i] = key
+	}
+	x.State.StagingValidators = &validators
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// checkpoint commits the regular context as the exceptional context, and sets ω7 to the remaining gas.
+func (h *hostCalls) checkpoint(m *pvm.Machine) (pvm.ExitReason, bool) {
+	h.exceptional = h.regular.Clone()
+
+	m.Registers[7] = uint64(m.Gas)
+	return hostcall.Continue()
+}
+
+// new creates a service with the code hash µ[ω7...+32] of length ω8, the accumulate gas ω9 and the on-transfer gas ω10.
+// ω7 is set to the new service id.
+func (h *hostCalls) new(m *pvm.Machine) (pvm.ExitReason, bool) {
+	codeHash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok || m.Registers[8] > math.MaxUint32 {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	x := h.regular
+	account := &service.ServiceAccount{
+		CodeHash: codeHash,
+		PreimageMeta: map[service.PreimageMeta]service.PreimageAvailabilityHistory{
+			{Hash: codeHash, BlobLength: common.BlobLength(m.Registers[8])}: {},
+		},
+		AccumulateGas: service.Gas(m.Registers[9]),
+		OnTransferGas: service.Gas(m.Registers[10]),
+	}
+
+	// The new service is endowed with its threshold balance a_t, which must leave the service above its own threshold.
+	account.Balance = account.ThresholdBalance()
+	creator := x.account()
+	if creator.Balance < account.Balance || creator.Balance-account.Balance < creator.ThresholdBalance() {
+		m.Registers[7] = hostcall.CASH
+		return hostcall.Continue()
+	}
+	creator.Balance -= account.Balance
+
+	m.Registers[7] = uint64(x.NextServiceId)
+	x.State.Services.Save(x.NextServiceId, account)
+	x.NextServiceId = checkServiceId(x.State, bumpServiceId(x.NextServiceId))
+
+	return hostcall.Continue()
+}
+
+// upgrade sets the code hash of the service to µ[ω7...+32], the accumulate gas to ω8 and the on-transfer gas to ω9.
+func (h *hostCalls) upgrade(m *pvm.Machine) (pvm.ExitReason, bool) {
+	codeHash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	account := h.regular.account()
+	account.CodeHash = codeHash
+	account.AccumulateGas = service.Gas(m.Registers[8])
+	account.OnTransferGas = service.Gas(m.Registers[9])
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// transfer defers a transfer of the amount ω8 to the service ω7 with the gas limit ω9 and the memo µ[ω10...+W_T].
+func (h *hostCalls) transfer(m *pvm.Machine) (pvm.ExitReason, bool) {
+	destinationId, amount, gas := m.Registers[7], m.Registers[8], m.Registers[9]
+
+	memo, err := m.ReadMemory(m.Registers[10], service.TransferMemoSize)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	x := h.regular
+	destination, ok := x.State.Services.Get(service.ServiceId(destinationId))
+	if destinationId > math.MaxUint32 || !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	if service.Gas(gas) < destination.OnTransferGas {
+		m.Registers[7] = hostcall.LOW
+		return hostcall.Continue()
+	}
+
+	// b = (x_s)_b − a, CASH if b < (x_s)_t
+	account := x.account()
+	if uint64(account.Balance) < amount || account.Balance-service.Balance(amount) < account.ThresholdBalance() {
+		m.Registers[7] = hostcall.CASH
+		return hostcall.Continue()
+	}
+
+	transfer := DeferredTransfer{
+		Sender:      x.ServiceId,
+		Destination: service.ServiceId(destinationId),
+		Amount:      service.Balance(amount),
+		Gas:         service.Gas(gas),
+	}
+	copy(transfer.Memo[:], memo)
+	x.Transfers = append(x.Transfers, transfer)
+	account.Balance -= service.Balance(amount)
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// eject removes the service ω7, whose code hash is the id of the service, and of which only the preimage µ[ω8...+32] is expired.
+// Its balance is transferred to the service.
+func (h *hostCalls) eject(m *pvm.Machine) (pvm.ExitReason, bool) {
+	targetId := m.Registers[7]
+
+	hash, ok := hostcall.ReadHash(m, m.Registers[8])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	x := h.regular
+	target, ok := x.State.Services.Get(service.ServiceId(targetId))
+	var ejectorCodeHash common.Hash
+	copy(ejectorCodeHash[:], codec.EncodeUint(uint64(x.ServiceId), 4))
+	if targetId > math.MaxUint32 || service.ServiceId(targetId) == x.ServiceId || !ok || target.CodeHash != ejectorCodeHash {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+
+	// l = max(81, d_o) − 81
+	footprint := target.Footprint()
+	length := max(81, footprint.SizeOfStorageItems) - 81
+	history, ok := target.PreimageMeta[service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(length)}]
+	if footprint.NumOfStorageItems != 2 || !ok || len(history) != 2 || !isExpired(history[1], h.timeSlot) {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	x.State.Services.Remove(service.ServiceId(targetId))
+	x.account().Balance += target.Balance
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// query sets ω7 and ω8 to the availability history of the preimage µ[ω7...+32] of length ω8 of the service.
+func (h *hostCalls) query(m *pvm.Machine) (pvm.ExitReason, bool) {
+	hash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	history, ok := h.regular.account().PreimageMeta[service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(m.Registers[8])}]
+	switch {
+	case !ok || m.Registers[8] > math.MaxUint32:
+		m.Registers[7], m.Registers[8] = hostcall.NONE, 0
+	case len(history) == 0:
+		m.Registers[7], m.Registers[8] = 0, 0
+	case len(history) == 1:
+		m.Registers[7], m.Registers[8] = 1+uint64(history[0])<<32, 0
+	case len(history) == 2:
+		m.Registers[7], m.Registers[8] = 2+uint64(history[0])<<32, uint64(history[1])
+	default:
+		m.Registers[7], m.Registers[8] = 3+uint64(history[0])<<32, uint64(history[1])+uint64(history[2])<<32
+	}
+
+	return hostcall.Continue()
+}
+
+// solicit requests the preimage µ[ω7...+32] of length ω8, or requests it again after it became unavailable.
+func (h *hostCalls) solicit(m *pvm.Machine) (pvm.ExitReason, bool) {
+	hash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	account := h.regular.account()
+	meta := service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(m.Registers[8])}
+	history, ok := account.PreimageMeta[meta]
+	switch {
+	case m.Registers[8] > math.MaxUint32:
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	case !ok:
+		if account.PreimageMeta == nil {
+			account.PreimageMeta = make(map[service.PreimageMeta]service.PreimageAvailabilityHistory)
+		}
+		account.PreimageMeta[meta] = service.PreimageAvailabilityHistory{}
+	case len(history) == 2:
+		account.PreimageMeta[meta] = append(history, h.timeSlot)
+	default:
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	// FULL if a_b < a_t, leaving the request untouched.
+	if account.Balance < account.ThresholdBalance() {
+		if ok {
+			account.PreimageMeta[meta] = history
+		} else {
+			delete(account.PreimageMeta, meta)
+		}
+		m.Registers[7] = hostcall.FULL
+		return hostcall.Continue()
+	}
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// forget drops the request of the preimage µ[ω7...+32] of length ω8, or marks it unavailable.
+func (h *hostCalls) forget(m *pvm.Machine) (pvm.ExitReason, bool) {
+	hash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	account := h.regular.account()
+	meta := service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(m.Registers[8])}
+	history, ok := account.PreimageMeta[meta]
+	switch {
+	case !ok || m.Registers[8] > math.MaxUint32:
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	case len(history) == 0, len(history) == 2 && isExpired(history[1], h.timeSlot):
+		delete(account.PreimageMeta, meta)
+		delete(account.Preimages, hash)
+	case len(history) == 1:
+		account.PreimageMeta[meta] = service.PreimageAvailabilityHistory{history[0], h.timeSlot}
+	case len(history) == 3 && isExpired(history[1], h.timeSlot):
+		account.PreimageMeta[meta] = service.PreimageAvailabilityHistory{history[2], h.timeSlot}
+	default:
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// yield sets the accumulation output to the hash µ[ω7...+32].
+func (h *hostCalls) yield(m *pvm.Machine) (pvm.ExitReason, bool) {
+	hash, ok := hostcall.ReadHash(m, m.Registers[7])
+	if !ok {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	h.regular.Yield = &hash
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// provide supplies the preimage µ[ω8...+ω9] requested by the service ω7, or the service itself.
+func (h *hostCalls) provide(m *pvm.Machine) (pvm.ExitReason, bool) {
+	x := h.regular
+	targetId := m.Registers[7]
+	if targetId == math.MaxUint64 {
+		targetId = uint64(x.ServiceId)
+	}
+
+	preimage, err := m.ReadMemory(m.Registers[8], m.Registers[9])
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	target, ok := x.State.Services.Get(service.ServiceId(targetId))
+	if targetId > math.MaxUint32 || !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+
+	meta := service.PreimageMeta{Hash: blake2b.Sum256(preimage), BlobLength: common.BlobLength(len(preimage))}
+	history, ok := target.PreimageMeta[meta]
+	if !ok || len(history) != 0 || x.isProvided(service.ServiceId(targetId), preimage) {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	x.Provided = append(x.Provided, ProvidedPreimage{ServiceId: service.ServiceId(targetId), Preimage: preimage})
+
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// A preimage unavailable since the timeslot may be expunged, y < t − D.
+func isExpired(unavailableSince jamtime.TimeSlot, timeSlot jamtime.TimeSlot) bool {
+	return unavailableSince+jamtime.PreimageExpungePeriod < timeSlot
+}
@@ -0,0 +1,63 @@
+package accumulate
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHostCalls() (*hostCalls, *pvm.Machine) {
+	x := newContext(newTestPartialState(nil), testServiceId, common.Hash{1}, 1)
+	h := &hostCalls{regular: x, exceptional: x.Clone(), timeSlot: 1}
+
+	m := pvmtest.Machine(1000)
+	hash := common.Hash{0xcc}
+	m.Memory.Set(pvmtest.Address, hash[:])
+	return h, m
+}
+
+func TestNewThresholdBalance(t *testing.T) {
+	// The new service holding the code of 800 octets requires B_S + 2·B_I + B_L·(81 + 800) = 1001, above the balance of 1000.
+	h, m := newTestHostCalls()
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 800
+	_, exited := h.handle(hostcall.New, m)
+	require.False(t, exited)
+	require.Equal(t, hostcall.CASH, m.Registers[7])
+	require.Equal(t, service.Balance(1000), h.regular.account().Balance)
+
+	// The new service of the code of 10 octets is endowed with 211, leaving the creator above its threshold of B_S.
+	nextServiceId := h.regular.NextServiceId
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 10
+	_, exited = h.handle(hostcall.New, m)
+	require.False(t, exited)
+	require.Equal(t, uint64(nextServiceId), m.Registers[7])
+	require.Equal(t, service.Balance(789), h.regular.account().Balance)
+
+	created, ok := h.regular.State.Services.Get(nextServiceId)
+	require.True(t, ok)
+	require.Equal(t, service.Balance(211), created.Balance)
+	require.Equal(t, created.ThresholdBalance(), created.Balance)
+}
+
+func TestSolicitThresholdBalance(t *testing.T) {
+	h, m := newTestHostCalls()
+	hash := common.Hash{0xcc}
+
+	// Requesting a preimage of 900 octets raises the threshold to B_S + 2·B_I + B_L·(81 + 900) = 1101, above the balance.
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 900
+	_, exited := h.handle(hostcall.Solicit, m)
+	require.False(t, exited)
+	require.Equal(t, hostcall.FULL, m.Registers[7])
+	require.NotContains(t, h.regular.account().PreimageMeta, service.PreimageMeta{Hash: hash, BlobLength: 900})
+
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 10
+	_, exited = h.handle(hostcall.Solicit, m)
+	require.False(t, exited)
+	require.Equal(t, hostcall.OK, m.Registers[7])
+	require.Equal(t, service.PreimageAvailabilityHistory{}, h.regular.account().PreimageMeta[service.PreimageMeta{Hash: hash, BlobLength: 10}])
+}
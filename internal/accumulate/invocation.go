@@ -0,0 +1,105 @@
+package accumulate
+
+import (
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// The entry point of the accumulate code.
+const accumulateEntryPoint = 5
+
+// Result is the outcome of the accumulation of a service, (u, t, y, g, p).
+type Result struct {
+	State     *PartialState      // u: The posterior partial state.
+	Transfers []DeferredTransfer // t: The transfers deferred by the service.
+	Yield     *common.Hash       // y: The accumulation output, if any.
+	GasUsed   service.Gas        // g
+	Provided  []ProvidedPreimage // p: The preimages provided by the service.
+}
+
+// Invoke runs the accumulate code of the service with the operands, Ψ_A defined in the Gray Paper (B.8).
+// Host calls operate on the regular context, which is collapsed into the exceptional context when the code panics or runs out of gas.
+func Invoke(
+	state *PartialState, // u
+	timeSlot jamtime.TimeSlot, // t
+	entropy common.Hash, // η′0
+	serviceId service.ServiceId, // s
+	gas service.Gas, // g
+	operands []*Operand, // o
+) (*Result, error) {
+	account, ok := state.Services.Get(serviceId)
+	if !ok {
+		return &Result{State: state}, nil
+	}
+	code := account.GetServiceCode()
+	if code == nil || len(code) > service.MaxServiceCodeSize {
+		return &Result{State: state}, nil
+	}
+
+	// E4(t) ⌢ E4(s) ⌢ E(↕o)
+	encodedOperands, err := codec.Encode(operands)
+	if err != nil {
+		return nil, err
+	}
+	arguments := codec.EncodeUint(uint64(timeSlot), 4)
+	arguments = append(arguments, codec.EncodeUint(uint64(serviceId), 4)...)
+	arguments = append(arguments, encodedOperands...)
+
+	regular := newContext(state.Clone(), serviceId, entropy, timeSlot)
+	h := &hostCalls{regular: regular, exceptional: regular.Clone(), timeSlot: timeSlot}
+
+	gasUsed, output, reason := pvm.Invoke(code, accumulateEntryPoint, pvm.Gas(gas), arguments, h.handle)
+
+	// (B.12) C(g, o, (x, y))
+	if reason != pvm.Halt {
+		y := h.exceptional
+		return &Result{State: y.State, Transfers: y.Transfers, Yield: y.Yield, GasUsed: service.Gas(gasUsed), Provided: y.Provided}, nil
+	}
+
+	x := h.regular
+	yield := x.Yield
+	if len(output) == common.HashLength {
+		hash := common.Hash(output)
+		yield = &hash
+	}
+	return &Result{State: x.State, Transfers: x.Transfers, Yield: yield, GasUsed: service.Gas(gasUsed), Provided: x.Provided}, nil
+}
+
+// accumulateService accumulates the work results of the service in the reports, Δ1 defined in the Gray Paper (12.20).
+// g = U(f_s, 0) + Σ_{w∈w, r∈w_r, r_s=s} r_g
+// p = [(h: w_s_h, e: w_s_e, a: w_a, o: w_o, y: r_y, d: r_d) ∣ w <− w, r <− w_r, r_s = s]
+func accumulateService(
+	state *PartialState, // o
+	reports []*workreport.WorkReport, // w
+	alwaysAccumulate map[service.ServiceId]service.Gas, // f
+	serviceId service.ServiceId, // s
+	timeSlot jamtime.TimeSlot,
+	entropy common.Hash,
+) (*Result, error) {
+	gas := alwaysAccumulate[serviceId]
+
+	var operands []*Operand
+	for _, report := range reports {
+		for _, workResult := range report.WorkResults {
+			if workResult.ServiceId != serviceId {
+				continue
+			}
+
+			gas += workResult.Gas
+			operands = append(operands, &Operand{
+				WorkPackageHash:  report.AvailabilitySpecification.WorkPackageHash,
+				SegmentRoot:      report.AvailabilitySpecification.SegmentRoot,
+				AuthorizerHash:   report.AuthorizerHash,
+				AuthorizerOutput: report.Output,
+				PayloadHash:      workResult.PayloadHash,
+				Result:           workResult.ExecResult,
+			})
+		}
+	}
+
+	return Invoke(state, timeSlot, entropy, serviceId, gas, operands)
+}
@@ -0,0 +1,157 @@
+package accumulate
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	testServiceId   service.ServiceId = 1000
+	testRecipientId service.ServiceId = 1001
+	readOnlyAddress                   = 1 << 16 // The read-only data zone of a standard program.
+)
+
+var (
+	loadKeyAddress   = []byte{51, 0x07, 0x00, 0x00, 0x01} // load_imm ω7 0x10000
+	loadKeyLength    = []byte{51, 0x08, 1}                // load_imm ω8 1
+	loadValueAddress = []byte{51, 0x09, 0x01, 0x00, 0x01} // load_imm ω9 0x10001
+	loadValueLength  = []byte{51, 0x0a, 1}                // load_imm ω10 1
+	loadHashAddress  = []byte{51, 0x07, 0x02, 0x00, 0x01} // load_imm ω7 0x10002
+)
+
+// standardProgram builds a standard program blob with the read-only data, of which accumulate entry point runs the instructions.
+func standardProgram(readOnlyData []byte, instructions ...[]byte) []byte {
+	return pvmtest.StandardProgram(accumulateEntryPoint, readOnlyData, 0, instructions...)
+}
+
+func newTestPartialState(code []byte) *PartialState {
+	codeHash := blake2b.Sum256(code)
+
+	state := &PartialState{}
+	state.Services.Save(testServiceId, &service.ServiceAccount{
+		CodeHash:  codeHash,
+		Preimages: map[common.Hash]common.Blob{codeHash: code},
+		Balance:   1000,
+	})
+	state.Services.Save(testRecipientId, &service.ServiceAccount{OnTransferGas: 10})
+	return state
+}
+
+func TestInvoke(t *testing.T) {
+	yield := common.Hash{0xff}
+	readOnlyData := append([]byte{'k', 'v'}, yield[:]...)
+	writeInstructions := [][]byte{loadKeyAddress, loadKeyLength, loadValueAddress, loadValueLength, {10, byte(hostcall.Write)}}
+	storageKey := hostcall.StorageKey(testServiceId, []byte{'k'})
+
+	t.Run("halt commits the regular context", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(writeInstructions, loadHashAddress, []byte{10, byte(hostcall.Yield)}, pvmtest.Halt)...)
+		state := newTestPartialState(code)
+
+		result, err := Invoke(state, 1, common.Hash{}, testServiceId, 1000, nil)
+		require.NoError(t, err)
+
+		account, _ := result.State.Services.Get(testServiceId)
+		require.Equal(t, common.Blob{'v'}, account.StorageItems[storageKey])
+		require.Equal(t, &yield, result.Yield)
+		require.Equal(t, service.Gas(8*1+2*hostcall.GasCost), result.GasUsed)
+
+		original, _ := state.Services.Get(testServiceId)
+		require.Empty(t, original.StorageItems, "the prior state is not mutated")
+	})
+
+	t.Run("panic rolls back to the checkpoint", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(writeInstructions,
+			[]byte{10, byte(hostcall.Checkpoint)},
+			loadHashAddress, []byte{10, byte(hostcall.Yield)},
+			pvmtest.Trap)...)
+		state := newTestPartialState(code)
+
+		result, err := Invoke(state, 1, common.Hash{}, testServiceId, 1000, nil)
+		require.NoError(t, err)
+
+		account, _ := result.State.Services.Get(testServiceId)
+		require.Equal(t, common.Blob{'v'}, account.StorageItems[storageKey])
+		require.Nil(t, result.Yield, "yield after the checkpoint is rolled back")
+	})
+
+	t.Run("out of gas rolls back to the initial context", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(writeInstructions, pvmtest.Halt)...)
+		state := newTestPartialState(code)
+
+		result, err := Invoke(state, 1, common.Hash{}, testServiceId, 6, nil)
+		require.NoError(t, err)
+
+		account, _ := result.State.Services.Get(testServiceId)
+		require.Empty(t, account.StorageItems)
+		require.Equal(t, service.Gas(6), result.GasUsed)
+	})
+
+	t.Run("transfer is deferred", func(t *testing.T) {
+		memo := make([]byte, service.TransferMemoSize)
+		memo[0] = 0xaa
+		code := standardProgram(memo,
+			[]byte{51, 0x07, 0xe9, 0x03},       // load_imm ω7 1001
+			[]byte{51, 0x08, 100},              // load_imm ω8 100
+			[]byte{51, 0x09, 10},               // load_imm ω9 10
+			[]byte{51, 0x0a, 0x00, 0x00, 0x01}, // load_imm ω10 0x10000
+			[]byte{10, byte(hostcall.Transfer)},
+			pvmtest.Halt)
+		state := newTestPartialState(code)
+
+		result, err := Invoke(state, 1, common.Hash{}, testServiceId, 1000, nil)
+		require.NoError(t, err)
+
+		expected := DeferredTransfer{Sender: testServiceId, Destination: testRecipientId, Amount: 100, Gas: 10}
+		expected.Memo[0] = 0xaa
+		require.Equal(t, []DeferredTransfer{expected}, result.Transfers)
+
+		account, _ := result.State.Services.Get(testServiceId)
+		require.Equal(t, service.Balance(900), account.Balance)
+	})
+
+	t.Run("transfer below the threshold balance is refused", func(t *testing.T) {
+		code := standardProgram(make([]byte, service.TransferMemoSize),
+			[]byte{51, 0x07, 0xe9, 0x03},       // load_imm ω7 1001
+			[]byte{51, 0x08, 0xb6, 0x03},       // load_imm ω8 950
+			[]byte{51, 0x09, 10},               // load_imm ω9 10
+			[]byte{51, 0x0a, 0x00, 0x00, 0x01}, // load_imm ω10 0x10000
+			[]byte{10, byte(hostcall.Transfer)},
+			pvmtest.Halt)
+		state := newTestPartialState(code)
+
+		result, err := Invoke(state, 1, common.Hash{}, testServiceId, 1000, nil)
+		require.NoError(t, err)
+		require.Empty(t, result.Transfers, "a balance of 50 would fall below the threshold balance B_S")
+
+		account, _ := result.State.Services.Get(testServiceId)
+		require.Equal(t, service.Balance(1000), account.Balance)
+	})
+
+	t.Run("service without code", func(t *testing.T) {
+		state := newTestPartialState(nil)
+
+		result, err := Invoke(state, 1, common.Hash{}, testRecipientId, 1000, nil)
+		require.NoError(t, err)
+		require.Equal(t, &Result{State: state}, result)
+	})
+}
+
+func TestNewServiceId(t *testing.T) {
+	state := &PartialState{}
+	state.Services.Save(minServiceId, &service.ServiceAccount{})
+
+	require.Equal(t, service.ServiceId(minServiceId+1), checkServiceId(state, minServiceId))
+	require.Equal(t, service.ServiceId(minServiceId+42), bumpServiceId(minServiceId))
+	require.Equal(t, service.ServiceId(minServiceId+41), bumpServiceId(minServiceId+serviceIdSpace-1))
+
+	x := newContext(state, testServiceId, common.Hash{1}, 1)
+	require.GreaterOrEqual(t, x.NextServiceId, service.ServiceId(minServiceId))
+	_, exists := state.Services.Get(x.NextServiceId)
+	require.False(t, exists)
+}
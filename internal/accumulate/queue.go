@@ -0,0 +1,97 @@
+package accumulate
+
+import (
+	"maps"
+
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// (12.4) W! ≡ [w ∣ w <− W, |(wx)p| = 0 ∧ wl = {}]
+// (12.5) WQ ≡ E([D(w) ∣ w <− W, |(wx)p| > 0 ∨ wl ≠ {}], ⋃x∈ξ x)
+func splitAvailableReports(reports []*workreport.WorkReport, history *AccumulationHistory) ([]*workreport.WorkReport, []*QueuedWorkReport) {
+	immediate := make([]*workreport.WorkReport, 0, len(reports))
+	queued := make([]*QueuedWorkReport, 0, len(reports))
+	for _, report := range reports {
+		if len(report.RefinementContext.PreRequisiteWorkPackageHashes) == 0 && len(report.SegmentRootLookup) == 0 {
+			immediate = append(immediate, report)
+			continue
+		}
+		queued = append(queued, withDependencies(report))
+	}
+
+	return immediate, editQueue(queued, history.workPackageHashes())
+}
+
+// (12.6) D(w) ≡ (w, {(wx)p} ∪ K(wl))
+func withDependencies(report *workreport.WorkReport) *QueuedWorkReport {
+	dependencies := make(map[common.Hash]struct{}, len(report.RefinementContext.PreRequisiteWorkPackageHashes)+len(report.SegmentRootLookup))
+	for _, workPackageHash := range report.RefinementContext.PreRequisiteWorkPackageHashes {
+		dependencies[workPackageHash] = struct{}{}
+	}
+	for workPackageHash := range report.SegmentRootLookup {
+		dependencies[workPackageHash] = struct{}{}
+	}
+
+	return &QueuedWorkReport{WorkReport: report, Dependencies: dependencies}
+}
+
+// (12.7) E(r, x) ≡ [(w, d ∖ x) ∣ (w, d) <− r, (ws)h ∉ x]
+// Queued reports of accumulated work packages are dropped, and accumulated work packages are removed from the dependencies.
+func editQueue(queue []*QueuedWorkReport, accumulated map[common.Hash]struct{}) []*QueuedWorkReport {
+	var edited []*QueuedWorkReport
+	for _, record := range queue {
+		if _, ok := accumulated[record.WorkReport.AvailabilitySpecification.WorkPackageHash]; ok {
+			continue
+		}
+
+		dependencies := maps.Clone(record.Dependencies)
+		maps.DeleteFunc(dependencies, func(workPackageHash common.Hash, _ struct{}) bool {
+			_, ok := accumulated[workPackageHash]
+			return ok
+		})
+		edited = append(edited, &QueuedWorkReport{WorkReport: record.WorkReport, Dependencies: dependencies})
+	}
+
+	return edited
+}
+
+// (12.8) Q(r) ≡ [] if g = [], g ⌢ Q(E(r, P(g))) otherwise, where g = [w ∣ (w, {}) <− r]
+// Reports of which dependencies are all resolved are accumulatable, and accumulating them may resolve others.
+func accumulationPriority(queue []*QueuedWorkReport) []*workreport.WorkReport {
+	var accumulatable []*workreport.WorkReport
+	for {
+		ready := make([]*workreport.WorkReport, 0, len(queue))
+		for _, record := range queue {
+			if len(record.Dependencies) == 0 {
+				ready = append(ready, record.WorkReport)
+			}
+		}
+		if len(ready) == 0 {
+			return accumulatable
+		}
+
+		accumulatable = append(accumulatable, ready...)
+		queue = editQueue(queue, workPackageHashes(ready))
+	}
+}
+
+// (12.9) P(w) ≡ {(ws)h ∣ w ∈ w}
+func workPackageHashes(reports []*workreport.WorkReport) map[common.Hash]struct{} {
+	hashes := make(map[common.Hash]struct{}, len(reports))
+	for _, report := range reports {
+		hashes[report.AvailabilitySpecification.WorkPackageHash] = struct{}{}
+	}
+
+	return hashes
+}
+
+// ⋃x∈ξ x
+func (h *AccumulationHistory) workPackageHashes() map[common.Hash]struct{} {
+	hashes := make(map[common.Hash]struct{})
+	for _, accumulated := range h {
+		maps.Copy(hashes, accumulated)
+	}
+
+	return hashes
+}
@@ -0,0 +1,304 @@
+package accumulate
+
+import (
+	"bytes"
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/merkle"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Outcome is the outcome of the accumulation of a block.
+type Outcome struct {
+	Accumulated []*workreport.WorkReport          // W*...n: The accumulated work reports.
+	State       *PartialState                     // o′: The posterior partial state.
+	Transfers   []DeferredTransfer                // t: The deferred transfers.
+	Yields      map[ServiceYield]struct{}         // b: The accumulation outputs.
+	GasUsed     map[service.ServiceId]service.Gas // u: The gas used by each service.
+}
+
+// A service and its accumulation output, (N_S, H).
+type ServiceYield struct {
+	ServiceId service.ServiceId
+	Hash      common.Hash
+}
+
+// ResultRoot returns the root of the accumulation outputs of the services, committed to by BEEFY, Gray Paper (7.3)
+// r = M_B([s ^^ E4(s) ⌢ E(h) ∣ (s, h) ∈ C], H_K), the pairs ordered by service and then hash.
+func (o *Outcome) ResultRoot() common.Hash {
+	yields := slices.SortedFunc(maps.Keys(o.Yields), func(a, b ServiceYield) int {
+		if a.ServiceId != b.ServiceId {
+			return cmp.Compare(a.ServiceId, b.ServiceId)
+		}
+		return bytes.Compare(a.Hash[:], b.Hash[:])
+	})
+
+	blobs := make([][]byte, len(yields))
+	for i, yield := range yields {
+		blobs[i] = append(codec.EncodeUint(uint64(yield.ServiceId), 4), yield.Hash[:]...)
+	}
+	return merkle.Binary(blobs, merkle.Keccak)
+}
+
+// Update accumulates the available work reports W which are accumulatable in this block, and rotates θ and ξ.
+// (12.21) (n, o′, t, b, u) ≡ Δ+(g, W*, (χ, δ, ι, φ), χg)
+func Update(
+	queue *AccumulationQueue, // θ
+	history *AccumulationHistory, // ξ
+	timeSlot jamtime.TimeSlot, // τ′
+	prevTimeSlot jamtime.TimeSlot, // τ
+	availableReports []*workreport.WorkReport, // W
+	state *PartialState, // (χ, δ, ι, φ)
+	entropy common.Hash, // η′0
+) (*Outcome, error) {
+	accumulatable, queued := queue.accumulatableReports(timeSlot, availableReports, history)
+
+	alwaysAccumulate := state.PrivilegedServices.AlwaysAccumulate
+	outcome, err := accumulateSequentially(GasLimit(alwaysAccumulate), accumulatable, state, alwaysAccumulate, timeSlot, entropy)
+	if err != nil {
+		return nil, err
+	}
+
+	history.update(outcome.Accumulated)
+	queue.update(timeSlot, prevTimeSlot, queued, history[len(history)-1])
+
+	return outcome, nil
+}
+
+// accumulateSequentially accumulates the prefix of the reports which fits in the gas limit in parallel,
+// and continues with the remaining reports with the remaining gas, Δ+ defined in the Gray Paper (12.16).
+func accumulateSequentially(
+	gasLimit service.Gas, // g
+	reports []*workreport.WorkReport, // w
+	state *PartialState, // o
+	alwaysAccumulate map[service.ServiceId]service.Gas, // f
+	timeSlot jamtime.TimeSlot,
+	entropy common.Hash,
+) (*Outcome, error) {
+	outcome := &Outcome{
+		State:   state,
+		Yields:  make(map[ServiceYield]struct{}),
+		GasUsed: make(map[service.ServiceId]service.Gas),
+	}
+
+	for {
+		// i = max(N|w|+1) ∶ Σw∈w...i, r∈wr (rg) ≤ g
+		remaining := reports[len(outcome.Accumulated):]
+		i := AccumulatablePrefix(gasLimit, remaining)
+		if i == 0 {
+			return outcome, nil
+		}
+
+		gasUsed, err := accumulateInParallel(outcome, remaining[:i], alwaysAccumulate, timeSlot, entropy)
+		if err != nil {
+			return nil, err
+		}
+
+		outcome.Accumulated = append(outcome.Accumulated, remaining[:i]...)
+		gasLimit -= min(gasLimit, gasUsed)
+		alwaysAccumulate = nil
+	}
+}
+
+// accumulateInParallel accumulates every service of the reports and the always-accumulate services on the same partial state,
+// and integrates their outputs into the outcome, Δ* defined in the Gray Paper (12.17).
+// It returns the total gas used.
+func accumulateInParallel(
+	outcome *Outcome,
+	reports []*workreport.WorkReport, // w
+	alwaysAccumulate map[service.ServiceId]service.Gas, // f
+	timeSlot jamtime.TimeSlot,
+	entropy common.Hash,
+) (service.Gas, error) {
+	// s = {rs ∣ w ∈ w, r ∈ wr} ∪ K(f)
+	serviceIds := make(map[service.ServiceId]struct{}, len(alwaysAccumulate))
+	for serviceId := range alwaysAccumulate {
+		serviceIds[serviceId] = struct{}{}
+	}
+	for _, report := range reports {
+		for _, workResult := range report.WorkResults {
+			serviceIds[workResult.ServiceId] = struct{}{}
+		}
+	}
+
+	state := outcome.State
+	results := make(map[service.ServiceId]*Result, len(serviceIds))
+	var gasUsed service.Gas
+	var provided []ProvidedPreimage
+	for _, serviceId := range slices.Sorted(maps.Keys(serviceIds)) {
+		result, err := accumulateService(state, reports, alwaysAccumulate, serviceId, timeSlot, entropy)
+		if err != nil {
+			return 0, err
+		}
+		results[serviceId] = result
+
+		gasUsed += result.GasUsed
+		outcome.GasUsed[serviceId] += result.GasUsed
+		outcome.Transfers = append(outcome.Transfers, result.Transfers...)
+		if result.Yield != nil {
+			outcome.Yields[ServiceYield{ServiceId: serviceId, Hash: *result.Yield}] = struct{}{}
+		}
+		provided = append(provided, result.Provided...)
+	}
+
+	outcome.State = integrate(state, results, provided, timeSlot)
+	return gasUsed, nil
+}
+
+// integrate builds the posterior partial state o′ = (d′, i′, q′, (m′, a′, v′, z′)) from the results of each service.
+// Privileged components are taken from the services privileged to alter them, and service accounts from the services themselves.
+func integrate(state *PartialState, results map[service.ServiceId]*Result, provided []ProvidedPreimage, timeSlot jamtime.TimeSlot) *PartialState {
+	privileged := state.PrivilegedServices
+	posterior := &PartialState{
+		StagingValidators:  state.StagingValidators,
+		AuthorizerQueues:   state.AuthorizerQueues,
+		PrivilegedServices: privileged.Clone(),
+	}
+
+	// (m′, z′) = (e*x)(m, z) where e* = Δ1(o, w, f, m)o
+	if result, ok := results[privileged.Manager]; ok {
+		posterior.PrivilegedServices.Manager = result.State.PrivilegedServices.Manager
+		posterior.PrivilegedServices.AlwaysAccumulate = result.State.PrivilegedServices.AlwaysAccumulate
+	}
+	// ∀c ∈ NC ∶ a′c = ((Δ1(o, w, f, ac)o)x)a c, q′c = ((Δ1(o, w, f, ac)o)q)c
+	for coreIndex, assigner := range privileged.Assigners {
+		if result, ok := results[assigner]; ok {
+			posterior.PrivilegedServices.Assigners[coreIndex] = result.State.PrivilegedServices.Assigners[coreIndex]
+			posterior.AuthorizerQueues[coreIndex] = result.State.AuthorizerQueues[coreIndex]
+		}
+	}
+	// v′ = ((Δ1(o, w, f, v)o)x)v, i′ = (Δ1(o, w, f, v)o)i
+	if result, ok := results[privileged.Designator]; ok {
+		posterior.PrivilegedServices.Designator = result.State.PrivilegedServices.Designator
+		posterior.StagingValidators = result.State.StagingValidators
+	}
+
+	// d′ = P((d ∪ n) ∖ m, ⋃ p)
+	// n = ⋃s∈s ((Δ1(o, w, f, s)o)d ∖ K(d ∖ {s})), m = ⋃s∈s (K(d) ∖ K((Δ1(o, w, f, s)o)d))
+	posterior.Services = state.Services.Clone()
+	for serviceId, result := range results {
+		for id, account := range result.State.Services.All() {
+			if _, existed := state.Services.Get(id); id == serviceId || !existed {
+				posterior.Services.Save(id, account)
+			}
+		}
+	}
+	for _, result := range results {
+		for id := range state.Services.All() {
+			if _, ok := result.State.Services.Get(id); !ok {
+				posterior.Services.Remove(id)
+			}
+		}
+	}
+
+	provide(&posterior.Services, provided, timeSlot)
+
+	return posterior
+}
+
+// provide integrates the preimages provided during accumulation which are still requested, P defined in the Gray Paper (12.18).
+func provide(services *service.Services, provided []ProvidedPreimage, timeSlot jamtime.TimeSlot) {
+	for _, p := range provided {
+		account, ok := services.Get(p.ServiceId)
+		if !ok {
+			continue
+		}
+
+		hash := common.Hash(blake2b.Sum256(p.Preimage))
+		meta := service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(len(p.Preimage))}
+		if history, ok := account.PreimageMeta[meta]; !ok || len(history) != 0 {
+			continue
+		}
+
+		if account.Preimages == nil {
+			account.Preimages = make(map[common.Hash]common.Blob)
+		}
+		account.Preimages[hash] = p.Preimage
+		account.PreimageMeta[meta] = service.PreimageAvailabilityHistory{timeSlot}
+	}
+}
+
+// W*, the available reports in the order of accumulation, and WQ, the available reports to be queued.
+// (12.10) m = Ht mod E
+// (12.11) W* ≡ W! ⌢ Q(q)
+// (12.12) q = E(θm... ⌢ θ...m ⌢ WQ, P(W!))
+func (q *AccumulationQueue) accumulatableReports(
+	timeSlot jamtime.TimeSlot,
+	availableReports []*workreport.WorkReport,
+	history *AccumulationHistory,
+) ([]*workreport.WorkReport, []*QueuedWorkReport) {
+	immediate, queued := splitAvailableReports(availableReports, history)
+
+	m := timeSlot.ToTimeSlotInEpoch()
+	candidates := slices.Concat(slices.Concat(q[m:]...), slices.Concat(q[:m]...), queued)
+
+	return append(immediate, accumulationPriority(editQueue(candidates, workPackageHashes(immediate)))...), queued
+}
+
+// GasLimit returns the gas available for accumulation in a block.
+// (12.13) g = max(GT, GA·C + Σx∈V(χg) x)
+func GasLimit(alwaysAccumulate map[service.ServiceId]service.Gas) service.Gas {
+	gas := service.Gas(service.WorkReportAccumulationGasLimit * common.NumOfCores)
+	for _, g := range alwaysAccumulate {
+		gas += g
+	}
+
+	return max(service.TotalAccumulationGasLimit, gas)
+}
+
+// AccumulatablePrefix returns the number of reports from the head of w, of which total gas fits within the gas limit.
+// (12.16) i = max(N|w|+1) ∶ Σw∈w...i, r∈wr (rg) ≤ g
+func AccumulatablePrefix(gasLimit service.Gas, reports []*workreport.WorkReport) int {
+	var gas service.Gas
+	for i, report := range reports {
+		for _, workResult := range report.WorkResults {
+			gas += workResult.Gas
+		}
+		if gas > gasLimit {
+			return i
+		}
+	}
+
+	return len(reports)
+}
+
+// (12.25) ξ′E−1 = P(W*...n), ∀i ∈ NE−1 ∶ ξ′i = ξi+1
+func (h *AccumulationHistory) update(accumulated []*workreport.WorkReport) {
+	copy(h[:], h[1:])
+	h[len(h)-1] = workPackageHashes(accumulated)
+}
+
+// (12.26) ∀i ∈ NE ∶ θ′↺m−i ≡ E(WQ, ξ′E−1) if i = 0,
+// [] if 1 ≤ i < τ′ − τ,
+// E(θ↺m−i, ξ′E−1) if i ≥ τ′ − τ
+func (q *AccumulationQueue) update(
+	timeSlot jamtime.TimeSlot,
+	prevTimeSlot jamtime.TimeSlot,
+	queued []*QueuedWorkReport,
+	accumulated map[common.Hash]struct{},
+) {
+	m := int(timeSlot.ToTimeSlotInEpoch())
+	elapsed := int(timeSlot - prevTimeSlot)
+
+	var updated AccumulationQueue
+	for i := range jamtime.TimeSlotsPerEpoch {
+		index := (m - i + jamtime.TimeSlotsPerEpoch) % jamtime.TimeSlotsPerEpoch
+		switch {
+		case i == 0:
+			updated[index] = editQueue(queued, accumulated)
+		case i < elapsed:
+			updated[index] = nil
+		default:
+			updated[index] = editQueue(q[index], accumulated)
+		}
+	}
+
+	*q = updated
+}
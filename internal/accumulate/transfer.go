@@ -0,0 +1,125 @@
+package accumulate
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// The entry point of the on-transfer code.
+const onTransferEntryPoint = 10
+
+// ApplyTransfers credits the deferred transfers to their destinations and runs the on-transfer code of each destination
+// with the transfers it received, Gray Paper (12.27) ~ (12.29).
+// δ‡ = {s ↦ Ψ_T(δ†, τ′, s, R(t, s)) ∣ (s ↦ a) ∈ δ†}
+// R(t, d) ≡ [t ∣ s <− N_S, t <− t, t_s = s, t_d = d]
+// It returns the gas used by the on-transfer invocation of each service which received transfers.
+func ApplyTransfers(
+	services *service.Services, // δ†
+	timeSlot jamtime.TimeSlot, // τ′
+	transfers []DeferredTransfer, // t
+) map[service.ServiceId]service.Gas {
+	received := make(map[service.ServiceId][]DeferredTransfer)
+	for _, transfer := range transfers {
+		received[transfer.Destination] = append(received[transfer.Destination], transfer)
+	}
+
+	// The on-transfer invocations all see δ†, so the posterior accounts are saved after all of them.
+	posterior := make(map[service.ServiceId]*service.ServiceAccount, len(received))
+	gasUsed := make(map[service.ServiceId]service.Gas, len(received))
+	for _, serviceId := range slices.Sorted(maps.Keys(received)) {
+		transfers := received[serviceId]
+		slices.SortStableFunc(transfers, func(a, b DeferredTransfer) int {
+			return cmp.Compare(a.Sender, b.Sender)
+		})
+
+		account, gas := InvokeOnTransfer(services, timeSlot, serviceId, transfers)
+		if account != nil {
+			posterior[serviceId] = account
+		}
+		gasUsed[serviceId] = gas
+	}
+
+	for serviceId, account := range posterior {
+		services.Save(serviceId, account)
+	}
+	return gasUsed
+}
+
+// InvokeOnTransfer credits the transfers to the service and runs its on-transfer code with them, Ψ_T defined in the Gray Paper (B.15).
+// It returns the posterior account of the service, or nil if the service does not exist, and the gas used.
+// Ψ_T(d, t, s, t) ≡ (a, 0) if a_c = ∅ ∨ t = [], Ψ_M(a_c, 10, Σ_{r∈t} r_g, E(t, s, ↕t), F, a) otherwise
+// where a = d[s] except a_b = d[s]_b + Σ_{r∈t} r_a
+func InvokeOnTransfer(
+	services *service.Services, // d
+	timeSlot jamtime.TimeSlot, // t
+	serviceId service.ServiceId, // s
+	transfers []DeferredTransfer, // t
+) (*service.ServiceAccount, service.Gas) {
+	prior, ok := services.Get(serviceId)
+	if !ok {
+		return nil, 0
+	}
+
+	account := prior.Clone()
+	var gas service.Gas
+	for _, transfer := range transfers {
+		account.Balance += transfer.Amount
+		gas += transfer.Gas
+	}
+
+	code := account.GetServiceCode()
+	if code == nil || len(code) > service.MaxServiceCodeSize || len(transfers) == 0 {
+		return account, 0
+	}
+
+	encodedTransfers, err := codec.Encode(transfers)
+	if err != nil {
+		return account, 0
+	}
+	arguments := codec.EncodeUint(uint64(timeSlot), 4)
+	arguments = append(arguments, codec.EncodeUint(uint64(serviceId), 4)...)
+	arguments = append(arguments, encodedTransfers...)
+
+	// The host calls see the other services as in d, and the service itself as a.
+	var view service.Services
+	for id, other := range services.All() {
+		view.Save(id, other)
+	}
+	view.Save(serviceId, account)
+
+	gasUsed, _, _ := pvm.Invoke(code, onTransferEntryPoint, pvm.Gas(gas), arguments, func(id uint64, m *pvm.Machine) (pvm.ExitReason, bool) {
+		return handleOnTransferHostCall(id, m, serviceId, &view)
+	})
+
+	// The changes made by the host calls are kept regardless of the exit reason.
+	return account, service.Gas(gasUsed)
+}
+
+// The host calls of Ψ_T, the general ones operating on the service s.
+func handleOnTransferHostCall(id uint64, m *pvm.Machine, serviceId service.ServiceId, services *service.Services) (pvm.ExitReason, bool) {
+	if !m.ConsumeGas(hostcall.GasCost) {
+		return hostcall.Exit(pvm.OutOfGas)
+	}
+
+	switch id {
+	case hostcall.Gas:
+		return hostcall.HandleGas(m)
+	case hostcall.Lookup:
+		return hostcall.HandleLookup(m, serviceId, services)
+	case hostcall.Read:
+		return hostcall.HandleRead(m, serviceId, services)
+	case hostcall.Write:
+		return hostcall.HandleWrite(m, serviceId, services)
+	case hostcall.Info:
+		return hostcall.HandleInfo(m, serviceId, services)
+	}
+
+	return hostcall.Unknown(m)
+}
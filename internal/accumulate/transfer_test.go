@@ -0,0 +1,56 @@
+package accumulate
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTransfers(t *testing.T) {
+	code := pvmtest.StandardProgram(onTransferEntryPoint, []byte{'k', 'v'}, 0, loadKeyAddress, loadKeyLength, loadValueAddress, loadValueLength, []byte{10, byte(hostcall.Write)}, pvmtest.Halt)
+	services := newTestPartialState(code).Services
+	prior, _ := services.Get(testServiceId)
+
+	transfers := []DeferredTransfer{
+		{Sender: testRecipientId, Destination: testServiceId, Amount: 5, Gas: 100},
+		{Sender: testServiceId, Destination: testRecipientId, Amount: 7, Gas: 10},
+		{Sender: testServiceId, Destination: 9999, Amount: 1, Gas: 10},
+	}
+	gasUsed := ApplyTransfers(&services, 1, transfers)
+
+	// The on-transfer code of the service writes to its storage.
+	account, _ := services.Get(testServiceId)
+	require.Equal(t, service.Balance(1005), account.Balance)
+	require.Equal(t, common.Blob{'v'}, account.StorageItems[hostcall.StorageKey(testServiceId, []byte{'k'})])
+	require.NotZero(t, gasUsed[testServiceId])
+	require.Less(t, gasUsed[testServiceId], service.Gas(100))
+	require.Empty(t, prior.StorageItems, "the prior account is not mutated")
+
+	// The recipient has no code, and is only credited.
+	recipient, _ := services.Get(testRecipientId)
+	require.Equal(t, service.Balance(7), recipient.Balance)
+	require.Equal(t, service.Gas(0), gasUsed[testRecipientId])
+
+	// Transfers to a service which no longer exists are dropped.
+	_, ok := services.Get(9999)
+	require.False(t, ok)
+}
+
+func TestInvokeOnTransfer(t *testing.T) {
+	code := pvmtest.StandardProgram(onTransferEntryPoint, nil, 0, pvmtest.Trap)
+	services := newTestPartialState(code).Services
+
+	// A panic keeps the credited balance, and uses the gas until the panic.
+	account, gasUsed := InvokeOnTransfer(&services, 1, testServiceId, []DeferredTransfer{{Sender: testRecipientId, Destination: testServiceId, Amount: 5, Gas: 100}})
+	require.Equal(t, service.Balance(1005), account.Balance)
+	require.Equal(t, service.Gas(1), gasUsed)
+
+	// Without transfers the code is not run.
+	account, gasUsed = InvokeOnTransfer(&services, 1, testServiceId, nil)
+	require.Equal(t, service.Balance(1000), account.Balance)
+	require.Zero(t, gasUsed)
+}
@@ -23,12 +23,12 @@ type AuthorizerPool []common.Hash
 // TODO: This function requires post state of Authorizers queue before processing.
 func (pools *AuthorizerPools) Update(
 	timeSlot jamtime.TimeSlot,
-	authorizerHashes map[uint8]common.Hash, // core to authorizer hash mapping
+	authorizerHashes map[uint32]common.Hash, // core to authorizer hash mapping
 	queues *authqueue.AuthorizerQueues,
 ) {
 	for coreIndex := range pools {
 		var coreAuthorizerHash *common.Hash
-		if hash, ok := authorizerHashes[uint8(coreIndex)]; ok {
+		if hash, ok := authorizerHashes[uint32(coreIndex)]; ok {
 			coreAuthorizerHash = &hash
 		}
 		pools[coreIndex].Update(timeSlot, coreAuthorizerHash, queues[coreIndex])
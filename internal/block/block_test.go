@@ -0,0 +1,285 @@
+package block
+
+import (
+	"crypto/ed25519"
+	"slices"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/dispute"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/internal/work"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func newTestBlock() *Block {
+	winningTickets := make(safrole.Tickets, jamtime.TimeSlotsPerEpoch)
+	for i := range winningTickets {
+		winningTickets[i] = &safrole.Ticket{TicketID: bandersnatch.VrfOutput{byte(i)}, EntryIndex: uint8(i % 2)}
+	}
+
+	judgements := &dispute.Judgements{}
+	for i := range judgements {
+		judgements[i] = &dispute.Judgement{Vote: i%2 == 0, ValidatorIndex: uint32(i), Signature: make([]byte, ed25519.SignatureSize)}
+	}
+
+	epochMarker := &EpochMarker{
+		Entropies: struct {
+			Next    common.Hash
+			Current common.Hash
+		}{Next: common.Hash{4}, Current: common.Hash{5}},
+	}
+	for i := range epochMarker.Validators {
+		epochMarker.Validators[i].Bandersnatch = bandersnatch.PublicKey{byte(i)}
+		epochMarker.Validators[i].Ed25519 = slices.Repeat([]byte{byte(i)}, ed25519.PublicKeySize)
+	}
+
+	return &Block{
+		Header: Header{
+			ParentHash:          common.Hash{1},
+			PriorStateRoot:      common.Hash{2},
+			ExtrinsicHash:       common.Hash{3},
+			TimeSlot:            42,
+			EpochMarker:         epochMarker,
+			WinningTicketMarker: &WinningTicketMarker{Tickets: winningTickets},
+			OffendersMarker: &OffendersMarker{
+				Offenders: []ed25519.PublicKey{make([]byte, ed25519.PublicKeySize)},
+			},
+			BlockAuthorIndex:   7,
+			VRFSignature:       bandersnatch.IetfSignature{6},
+			BlockSealSignature: bandersnatch.IetfSignature{7},
+		},
+		Extrinsic: Extrinsic{
+			TicketsExtrinsic: TicketsExtrinsic{
+				Tickets: []safrole.TicketProof{{EntryIndex: 1, TicketProof: bandersnatch.Signature{8}}},
+			},
+			PreimagesExtrinsic: PreimagesExtrinsic{
+				Preimages: []Preimage{{Requester: 18, Blob: []byte{0x04, 0x05}}},
+			},
+			GuaranteesExtrinsic: GuaranteesExtrinsic{
+				Guarantees: []*workreport.Guarantee{
+					{
+						WorkReport: &workreport.WorkReport{
+							AvailabilitySpecification: &workreport.AvailabilitySpecification{
+								WorkPackageHash:  common.Hash{9},
+								WorkBundleLength: 100,
+								ErasureRoot:      common.Hash{10},
+								SegmentRoot:      common.Hash{11},
+								SegmentCount:     2,
+							},
+							RefinementContext: &work.RefinementContext{
+								AnchorHeaderHash:              common.Hash{12},
+								LookupAnchorTimeSlot:          40,
+								PreRequisiteWorkPackageHashes: []common.Hash{{13}},
+							},
+							CoreIndex:         1,
+							AuthorizerHash:    common.Hash{14},
+							Output:            []byte{0x01, 0x02},
+							SegmentRootLookup: map[common.Hash]common.Hash{{15}: {16}},
+							WorkResults: []*workreport.WorkResult{
+								{ServiceId: 1, Gas: 1000, ExecResult: &workreport.ExecResult{Output: []byte{0x03}}},
+								{ServiceId: 2, Gas: 2000, ExecResult: &workreport.ExecResult{Error: workreport.Panic}},
+							},
+						},
+						Timeslot: 41,
+						Credentials: []*workreport.Credential{
+							{ValidatorIndex: 0, Signature: make([]byte, ed25519.SignatureSize)},
+							{ValidatorIndex: 3, Signature: make([]byte, ed25519.SignatureSize)},
+						},
+					},
+				},
+			},
+			AssuarancesExtrinsic: AssuarancesExtrinsic{
+				Assurances: []*workreport.Assurance{
+					{AnchorParentHash: common.Hash{1}, ValidatorIndex: 5, Signature: make([]byte, ed25519.SignatureSize)},
+				},
+			},
+			DisputesExtrinsic: DisputesExtrinsic{
+				Verdicts: []*dispute.Verdict{{WorkReportHash: common.Hash{17}, Epoch: 3, Judgements: judgements}},
+				Culprits: []*dispute.Culprit{
+					{WorkReportHash: common.Hash{17}, CulpritKey: make([]byte, ed25519.PublicKeySize), Signature: make([]byte, ed25519.SignatureSize)},
+				},
+				Faults: []*dispute.Fault{
+					{WorkReportHash: common.Hash{17}, Vote: true, FaultKey: make([]byte, ed25519.PublicKeySize), Signature: make([]byte, ed25519.SignatureSize)},
+				},
+			},
+		},
+	}
+}
+
+func TestBlockEncodeDecode(t *testing.T) {
+	block := newTestBlock()
+
+	encoded, err := codec.Encode(block)
+	require.NoError(t, err)
+
+	decoded := &Block{}
+	err = codec.Decode(encoded, decoded)
+	require.NoError(t, err)
+	require.Equal(t, block, decoded)
+}
+
+func TestHeaderEncodeOptionalMarkers(t *testing.T) {
+	header := newTestBlock().Header
+	header.EpochMarker = nil
+	header.WinningTicketMarker = nil
+	header.OffendersMarker = &OffendersMarker{Offenders: []ed25519.PublicKey{}}
+
+	encoded, err := codec.Encode(header)
+	require.NoError(t, err)
+
+	// Hp, Hr, Hx, E4(Ht), ¿He, ¿Hw, ↕Ho, E2(Hi), Hv, Hs
+	expectedLen := 3*common.HashLength + 4 + 1 + 1 + 1 + 2 + 2*bandersnatch.IetfSignatureSize
+	require.Len(t, encoded, expectedLen)
+	require.Equal(t, []byte{0, 0, 0}, encoded[3*common.HashLength+4:3*common.HashLength+7])
+
+	decoded := Header{}
+	err = codec.Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, header, decoded)
+}
+
+func TestEpochMarkerEncode(t *testing.T) {
+	epochMarker := newTestBlock().Header.EpochMarker
+
+	encoded, err := codec.Encode(epochMarker)
+	require.NoError(t, err)
+
+	// η0, η1, [(kb, ke)] without length prefix
+	require.Len(t, encoded, 2*common.HashLength+common.NumOfValidators*(bandersnatch.PublicKeySize+ed25519.PublicKeySize))
+
+	// ke of the second validator follows kb, after the first key pair
+	offset := 2*common.HashLength + bandersnatch.PublicKeySize + ed25519.PublicKeySize + bandersnatch.PublicKeySize
+	require.Equal(t, []byte(epochMarker.Validators[1].Ed25519), encoded[offset:offset+ed25519.PublicKeySize])
+}
+
+func TestHeaderEncodeUnsigned(t *testing.T) {
+	header := newTestBlock().Header
+
+	encoded, err := codec.Encode(header)
+	require.NoError(t, err)
+
+	unsigned, err := header.EncodeUnsigned()
+	require.NoError(t, err)
+	require.Equal(t, encoded[:len(encoded)-bandersnatch.IetfSignatureSize], unsigned)
+
+	header.BlockSealSignature = bandersnatch.IetfSignature{0xff}
+	resealed, err := header.EncodeUnsigned()
+	require.NoError(t, err)
+	require.Equal(t, unsigned, resealed, "seal must not affect the unsigned encoding")
+}
+
+func TestValidateEpochMarker(t *testing.T) {
+	header := newTestBlock().Header
+	expected := *header.EpochMarker
+
+	require.NoError(t, header.ValidateEpochMarker(&expected))
+	require.ErrorIs(t, header.ValidateEpochMarker(nil), ErrInvalidEpochMarker)
+
+	t.Run("Altered ed25519 key", func(t *testing.T) {
+		altered := expected
+		altered.Validators[3].Ed25519 = slices.Clone(altered.Validators[3].Ed25519)
+		altered.Validators[3].Ed25519[0] ^= 0xff
+		require.ErrorIs(t, header.ValidateEpochMarker(&altered), ErrInvalidEpochMarker)
+	})
+
+	t.Run("Altered bandersnatch key", func(t *testing.T) {
+		altered := expected
+		altered.Validators[3].Bandersnatch[0] ^= 0xff
+		require.ErrorIs(t, header.ValidateEpochMarker(&altered), ErrInvalidEpochMarker)
+	})
+
+	expected.Entropies.Next = common.Hash{0xff}
+	require.ErrorIs(t, header.ValidateEpochMarker(&expected), ErrInvalidEpochMarker)
+
+	header.EpochMarker = nil
+	require.NoError(t, header.ValidateEpochMarker(nil))
+	require.ErrorIs(t, header.ValidateEpochMarker(&expected), ErrInvalidEpochMarker)
+}
+
+func TestValidateWinningTicketMarker(t *testing.T) {
+	header := newTestBlock().Header
+	expected := &WinningTicketMarker{Tickets: make(safrole.Tickets, len(header.WinningTicketMarker.Tickets))}
+	for i, ticket := range header.WinningTicketMarker.Tickets {
+		copied := *ticket
+		expected.Tickets[i] = &copied
+	}
+
+	require.NoError(t, header.ValidateWinningTicketMarker(expected))
+	require.ErrorIs(t, header.ValidateWinningTicketMarker(nil), ErrInvalidWinningTicketMarker)
+
+	expected.Tickets[0].EntryIndex++
+	require.ErrorIs(t, header.ValidateWinningTicketMarker(expected), ErrInvalidWinningTicketMarker)
+
+	header.WinningTicketMarker = nil
+	require.NoError(t, header.ValidateWinningTicketMarker(nil))
+	require.ErrorIs(t, header.ValidateWinningTicketMarker(expected), ErrInvalidWinningTicketMarker)
+}
+
+func TestValidateOffendersMarker(t *testing.T) {
+	header := newTestBlock().Header
+	offender := make(ed25519.PublicKey, ed25519.PublicKeySize)
+
+	require.NoError(t, header.ValidateOffendersMarker([]ed25519.PublicKey{offender}))
+	require.ErrorIs(t, header.ValidateOffendersMarker(nil), ErrInvalidOffendersMarker)
+
+	other := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	other[0] = 1
+	require.ErrorIs(t, header.ValidateOffendersMarker([]ed25519.PublicKey{other}), ErrInvalidOffendersMarker)
+
+	header.OffendersMarker = nil
+	require.NoError(t, header.ValidateOffendersMarker(nil))
+	require.ErrorIs(t, header.ValidateOffendersMarker([]ed25519.PublicKey{offender}), ErrInvalidOffendersMarker)
+}
+
+func TestExtrinsicHash(t *testing.T) {
+	t.Run("Empty extrinsic", func(t *testing.T) {
+		hash, err := (&Extrinsic{}).Hash()
+		require.NoError(t, err)
+
+		// ET, EP, g and EA are empty sequences, ED is three empty sequences.
+		emptySequence := blake2b.Sum256([]byte{0})
+		emptyDisputes := blake2b.Sum256([]byte{0, 0, 0})
+		expected := blake2b.Sum256(slices.Concat(emptySequence[:], emptySequence[:], emptySequence[:], emptySequence[:], emptyDisputes[:]))
+		require.Equal(t, common.Hash(expected), hash)
+	})
+
+	t.Run("Guarantees are hashed with work report hash", func(t *testing.T) {
+		extrinsic := newTestBlock().Extrinsic
+		guarantee := extrinsic.Guarantees[0]
+
+		workReportHash, err := guarantee.WorkReport.Hash()
+		require.NoError(t, err)
+
+		// ↕[H(w) ⌢ E4(t) ⌢ ↕a]
+		g := slices.Concat([]byte{1}, workReportHash[:], codec.EncodeUint(uint64(guarantee.Timeslot), 4), []byte{2})
+		for _, credential := range guarantee.Credentials {
+			g = slices.Concat(g, codec.EncodeUint(uint64(credential.ValidatorIndex), 2), credential.Signature)
+		}
+
+		hashes := make([]byte, 0, 5*common.HashLength)
+		for _, component := range []any{extrinsic.TicketsExtrinsic, extrinsic.PreimagesExtrinsic, nil, extrinsic.AssuarancesExtrinsic, extrinsic.DisputesExtrinsic} {
+			encoded := g
+			if component != nil {
+				encoded, err = codec.Encode(component)
+				require.NoError(t, err)
+			}
+			hash := blake2b.Sum256(encoded)
+			hashes = append(hashes, hash[:]...)
+		}
+
+		hash, err := extrinsic.Hash()
+		require.NoError(t, err)
+		require.Equal(t, common.Hash(blake2b.Sum256(hashes)), hash)
+
+		guarantee.Timeslot++
+		changed, err := extrinsic.Hash()
+		require.NoError(t, err)
+		require.NotEqual(t, hash, changed)
+	})
+}
@@ -0,0 +1,12 @@
+package block
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidEpochMarker         = errors.New("invalid epoch marker")
+	ErrInvalidWinningTicketMarker = errors.New("invalid winning ticket marker")
+	ErrInvalidOffendersMarker     = errors.New("invalid offenders marker")
+	ErrInvalidExtrinsicHash       = errors.New("invalid extrinsic hash")
+	ErrInvalidParentHash          = errors.New("invalid parent hash")
+	ErrInvalidPriorStateRoot      = errors.New("invalid prior state root")
+)
@@ -2,8 +2,13 @@ package block
 
 import (
 	"github.com/shunsukew/gojam/internal/dispute"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
 	"github.com/shunsukew/gojam/internal/validator/safrole"
 	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
 )
 
 // Hasing Extrinsic
@@ -24,7 +29,15 @@ type TicketsExtrinsic struct {
 	Tickets []safrole.TicketProof
 }
 
-type PreimagesExtrinsic struct{}
+// EP ∈ ⟦(NS, Y)⟧
+type PreimagesExtrinsic struct {
+	Preimages []Preimage
+}
+
+type Preimage struct {
+	Requester service.ServiceId // s ∈ NS
+	Blob      []byte            // p ∈ Y
+}
 
 // EG ∈ ⟦(w ∈ W, t ∈ NT, a ∈ ⟦(NV, E)⟧₂:₃)⟧C
 type GuaranteesExtrinsic struct {
@@ -40,3 +53,63 @@ type DisputesExtrinsic struct {
 	Culprits []*dispute.Culprit // Should not include already-inside-punish-set offenders
 	Faults   []*dispute.Fault   // Should not include already-inside-punish-set offenders
 }
+
+// Hash returns the extrinsic hash Hx.
+func (e *Extrinsic) Hash() (common.Hash, error) {
+	guarantees, err := e.GuaranteesExtrinsic.encodeForHash()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	components := make([][]byte, 0, 5)
+	for _, component := range []any{e.TicketsExtrinsic, e.PreimagesExtrinsic} {
+		encoded, err := codec.Encode(component)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		components = append(components, encoded)
+	}
+	components = append(components, guarantees)
+	for _, component := range []any{e.AssuarancesExtrinsic, e.DisputesExtrinsic} {
+		encoded, err := codec.Encode(component)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		components = append(components, encoded)
+	}
+
+	// E(H#(a)): the component hashes are concatenated without a length prefix.
+	hashes := make([]byte, 0, len(components)*common.HashLength)
+	for _, component := range components {
+		hash := blake2b.Sum256(component)
+		hashes = append(hashes, hash[:]...)
+	}
+
+	return blake2b.Sum256(hashes), nil
+}
+
+// guaranteeForHash is a guarantee with its work report replaced by the work report hash.
+type guaranteeForHash struct {
+	WorkReportHash common.Hash              // H(w)
+	Timeslot       jamtime.TimeSlot         // E4(t)
+	Credentials    []*workreport.Credential // ↕a
+}
+
+// g = E(↕[E(H(w), E4(t), ↕a) ∣ (w, t, a) −< EG])
+func (g GuaranteesExtrinsic) encodeForHash() ([]byte, error) {
+	guarantees := make([]guaranteeForHash, 0, len(g.Guarantees))
+	for _, guarantee := range g.Guarantees {
+		workReportHash, err := guarantee.WorkReport.Hash()
+		if err != nil {
+			return nil, err
+		}
+
+		guarantees = append(guarantees, guaranteeForHash{
+			WorkReportHash: workReportHash,
+			Timeslot:       guarantee.Timeslot,
+			Credentials:    guarantee.Credentials,
+		})
+	}
+
+	return codec.Encode(guarantees)
+}
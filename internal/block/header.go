@@ -3,39 +3,105 @@ package block
 import (
 	"crypto/ed25519"
 
+	"github.com/pkg/errors"
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+	"golang.org/x/crypto/blake2b"
 )
 
 // Block's header
 // H ≡ (Hp,Hr,Hx,Ht,He,Hw,Ho,Hi,Hv,Hs)
 type Header struct {
-	ParentHash          common.Hash            // Hp
-	PriorStateRoot      common.Hash            // Hr
-	ExtrinsicHash       common.Hash            // Hx
-	TimeSlot            jamtime.TimeSlot       // Ht
-	EpochMarker         *EpochMarker           // He (optional, non-empty when e' > e)
-	WinningTicketMarker *WinningTicketMarker   // Hw (optional, non-empty when e' > e)
-	OffendersMarker     *OffendersMarker       // Ho (optional)
-	BlockAuthorIndex    uint16                 // Hi: Hi ∈ NumV. V = 1023: The total number of validators.
-	VRFSignature        bandersnatch.Signature // Hv
-	BlockSealSignature  bandersnatch.Signature // Hs
+	ParentHash          common.Hash                // Hp
+	PriorStateRoot      common.Hash                // Hr
+	ExtrinsicHash       common.Hash                // Hx
+	TimeSlot            jamtime.TimeSlot           // Ht
+	EpochMarker         *EpochMarker               `codec:"optional"` // He (optional, non-empty when e' > e)
+	WinningTicketMarker *WinningTicketMarker       `codec:"optional"` // Hw (optional, non-empty when e' > e)
+	OffendersMarker     *OffendersMarker           // Ho (optional)
+	BlockAuthorIndex    uint16                     // Hi: Hi ∈ NumV. V = 1023: The total number of validators.
+	VRFSignature        bandersnatch.IetfSignature // Hv
+	BlockSealSignature  bandersnatch.IetfSignature // Hs
 }
 
+// He ∈ (ℍ, ℍ, ⟦(ℍ_B, ℍ_E)⟧V)
 type EpochMarker struct {
 	Entropies struct {
 		Next    common.Hash // μ0
 		Current common.Hash // μ1
 	}
-	BandersnatchPubKeys [common.NumOfValidators]bandersnatch.PublicKey
+	Validators [common.NumOfValidators]struct {
+		Bandersnatch bandersnatch.PublicKey // kb
+		Ed25519      ed25519.PublicKey      // ke
+	}
 }
 
+// Hw ∈ ⟦C⟧E
 type WinningTicketMarker struct {
 	Tickets safrole.Tickets
 }
 
+// Winning tickets marker is a fixed length sequence of E tickets, so no length prefix is encoded.
+func (m WinningTicketMarker) MarshalJAM() ([]byte, error) {
+	if len(m.Tickets) != jamtime.TimeSlotsPerEpoch {
+		return nil, errors.Errorf("winning tickets marker must have %d tickets, got %d", jamtime.TimeSlotsPerEpoch, len(m.Tickets))
+	}
+
+	encoded := make([]byte, 0, jamtime.TimeSlotsPerEpoch*(bandersnatch.VrfOutputSize+1))
+	for _, ticket := range m.Tickets {
+		bytes, err := codec.Encode(ticket)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, bytes...)
+	}
+
+	return encoded, nil
+}
+
+func (m *WinningTicketMarker) UnmarshalJAM(d *codec.Decoder) error {
+	tickets := make(safrole.Tickets, jamtime.TimeSlotsPerEpoch)
+	for i := range tickets {
+		tickets[i] = &safrole.Ticket{}
+		if err := d.Decode(tickets[i]); err != nil {
+			return err
+		}
+	}
+
+	m.Tickets = tickets
+	return nil
+}
+
 type OffendersMarker struct {
 	Offenders []ed25519.PublicKey
 }
+
+// Hash returns the header hash H(H), the Blake2b hash of the encoded header.
+func (h *Header) Hash() (common.Hash, error) {
+	encoded, err := codec.Encode(h)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return blake2b.Sum256(encoded), nil
+}
+
+// EncodeUnsigned encodes the header without the seal, E_U(H) defined in the Gray Paper (C.23).
+func (h *Header) EncodeUnsigned() ([]byte, error) {
+	encoded, err := codec.Encode(h)
+	if err != nil {
+		return nil, err
+	}
+
+	// The seal is the last item of the header.
+	return encoded[:len(encoded)-bandersnatch.IetfSignatureSize], nil
+}
+
+// EntropySource returns Y(Hv), the VRF output of the block entropy source.
+// It is not verified, see ValidatorState.VerifyBlockSeal for the verification.
+func (h *Header) EntropySource() (bandersnatch.VrfOutput, error) {
+	return h.VRFSignature.Output()
+}
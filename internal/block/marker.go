@@ -0,0 +1,68 @@
+package block
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+)
+
+// ValidateEpochMarker checks He against the epoch marker derived by the safrole state transition, Gray Paper (6.27).
+func (h *Header) ValidateEpochMarker(expected *EpochMarker) error {
+	switch {
+	case expected == nil && h.EpochMarker != nil:
+		return errors.WithMessage(ErrInvalidEpochMarker, "epoch marker must be empty within an epoch")
+	case expected != nil && h.EpochMarker == nil:
+		return errors.WithMessage(ErrInvalidEpochMarker, "epoch marker is missing on the first block of an epoch")
+	case expected != nil && expected.Entropies != h.EpochMarker.Entropies:
+		return errors.WithMessage(ErrInvalidEpochMarker, "epoch marker does not match the next epoch entropies")
+	case expected != nil:
+		for i, validator := range expected.Validators {
+			marked := h.EpochMarker.Validators[i]
+			if validator.Bandersnatch != marked.Bandersnatch || !bytes.Equal(validator.Ed25519, marked.Ed25519) {
+				return errors.WithMessagef(ErrInvalidEpochMarker, "epoch marker does not match the next epoch validator at index %d", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateWinningTicketMarker checks Hw against the winning tickets marker derived by the safrole state transition, Gray Paper (6.28).
+func (h *Header) ValidateWinningTicketMarker(expected *WinningTicketMarker) error {
+	switch {
+	case expected == nil && h.WinningTicketMarker != nil:
+		return errors.WithMessage(ErrInvalidWinningTicketMarker, "winning tickets marker must be empty")
+	case expected != nil && h.WinningTicketMarker == nil:
+		return errors.WithMessage(ErrInvalidWinningTicketMarker, "winning tickets marker is missing on the first block after the ticket submission period")
+	case expected != nil && !slices.EqualFunc(expected.Tickets, h.WinningTicketMarker.Tickets, func(a, b *safrole.Ticket) bool {
+		return a != nil && b != nil && *a == *b
+	}):
+		return errors.WithMessage(ErrInvalidWinningTicketMarker, "winning tickets marker does not match the accumulated tickets")
+	}
+
+	return nil
+}
+
+// ValidateOffendersMarker checks Ho against the offenders of the disputes extrinsic, Gray Paper (10.20)
+// Ho ≡ [k ∣ (x, k, s) ∈ EC] ⌢ [k ∣ (x, v, k, s) ∈ EF].
+func (h *Header) ValidateOffendersMarker(offenders []ed25519.PublicKey) error {
+	var marked []ed25519.PublicKey
+	if h.OffendersMarker != nil {
+		marked = h.OffendersMarker.Offenders
+	}
+
+	if len(marked) != len(offenders) {
+		return errors.WithMessagef(ErrInvalidOffendersMarker, "expected %d offenders, got %d", len(offenders), len(marked))
+	}
+
+	for i, offender := range offenders {
+		if !bytes.Equal(offender, marked[i]) {
+			return errors.WithMessagef(ErrInvalidOffendersMarker, "offender at index %d does not match", i)
+		}
+	}
+
+	return nil
+}
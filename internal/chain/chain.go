@@ -1 +1,274 @@
 package chain
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/accumulate"
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	jamstate "github.com/shunsukew/gojam/internal/state"
+	"github.com/shunsukew/gojam/internal/validator"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// ImportBlock applies the block to the prior state and returns the posterior state, Gray Paper (4.1) σ′ ≡ Υ(σ, B).
+// Sub state transitions run in the dependency order of the Gray Paper (4.5) ~ (4.20).
+// The prior state is never modified, so that the block can be discarded on any error.
+func ImportBlock(state *jamstate.State, b *block.Block) (*jamstate.State, error) {
+	if state == nil || b == nil {
+		return nil, errors.New("state and block must not be nil")
+	}
+
+	if state.ValidatorState.ActiveValidators == nil ||
+		state.ValidatorState.ArchivedValidators == nil ||
+		state.ValidatorState.StagingValidators == nil ||
+		state.ValidatorState.SafroleState == nil {
+		return nil, errors.New("validator state is not initialized")
+	}
+
+	header := &b.Header
+
+	// (5.7) P(H)t < Ht
+	if !header.TimeSlot.After(state.TimeSlot) {
+		return nil, errors.WithMessagef(jamtime.ErrInvalidTimeSlot, "block timeslot %d must be after prior timeslot %d", header.TimeSlot, state.TimeSlot)
+	}
+
+	// (5.4) Hx ≡ H(E(H#(a)))
+	extrinsicHash, err := b.Extrinsic.Hash()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to hash extrinsic")
+	}
+	if extrinsicHash != header.ExtrinsicHash {
+		return nil, errors.WithMessagef(block.ErrInvalidExtrinsicHash, "expected %s, got %s", extrinsicHash.ToHex(), header.ExtrinsicHash.ToHex())
+	}
+
+	// (5.2) Hp ≡ H(E(P(H)))
+	if len(state.RecentHistory) > 0 {
+		parentHash := state.RecentHistory[len(state.RecentHistory)-1].HeaderHash
+		if header.ParentHash != parentHash {
+			return nil, errors.WithMessagef(block.ErrInvalidParentHash, "expected %s, got %s", parentHash.ToHex(), header.ParentHash.ToHex())
+		}
+	}
+
+	// (5.8) Hr ≡ M_σ(σ)
+	stateRoot, err := state.Root()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to compute prior state root")
+	}
+	if header.PriorStateRoot != stateRoot {
+		return nil, errors.WithMessagef(block.ErrInvalidPriorStateRoot, "expected %s, got %s", stateRoot.ToHex(), header.PriorStateRoot.ToHex())
+	}
+
+	headerHash, err := header.Hash()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to hash header")
+	}
+
+	posterior := state.Clone()
+
+	// β† ≡ β except β†[∣β∣ − 1]s = Hr, used for the contextual validity of work reports.
+	intermediateRecentHistory := posterior.RecentHistory
+	if len(intermediateRecentHistory) > 0 {
+		intermediateRecentHistory[len(intermediateRecentHistory)-1].StateRoot = header.PriorStateRoot
+	}
+
+	// ψ′ ≺ (ED, ψ, κ, λ, τ′)
+	offenders, err := posterior.DisputeState.Update(
+		b.DisputesExtrinsic.Verdicts,
+		b.DisputesExtrinsic.Culprits,
+		b.DisputesExtrinsic.Faults,
+		state.ValidatorState.ActiveValidators[:],
+		state.ValidatorState.ArchivedValidators[:],
+		header.TimeSlot,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to process disputes")
+	}
+
+	err = header.ValidateOffendersMarker(offenders)
+	if err != nil {
+		return nil, err
+	}
+
+	// ρ† ≺ (ED, ρ)
+	disputedReports := slices.Concat(
+		posterior.DisputeState.BadReports[len(state.DisputeState.BadReports):],
+		posterior.DisputeState.WonkeyReports[len(state.DisputeState.WonkeyReports):],
+	)
+	err = posterior.PendingWorkReports.RemoveDisputedReports(disputedReports)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to remove disputed work reports")
+	}
+
+	// Y(Hv) is verified together with the seal once κ′, γ′ and η′ are known.
+	entropySource, err := header.EntropySource()
+	if err != nil {
+		return nil, errors.WithMessagef(validator.ErrInvalidEntropySource, "failed to derive entropy source: %v", err)
+	}
+
+	// (γ′, κ′, λ′, ι′, η′) ≺ (H, τ, ET, γ, ι, η, κ, λ, ψ′)
+	entropyPool, epochMarker, winningTicketMarker, err := posterior.ValidatorState.Update(
+		header.TimeSlot,
+		state.TimeSlot,
+		entropySource,
+		posterior.EntropyPool,
+		b.TicketsExtrinsic.Tickets,
+		offenders,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to update validator state")
+	}
+	posterior.EntropyPool = entropyPool
+
+	err = header.ValidateEpochMarker(epochMarker)
+	if err != nil {
+		return nil, err
+	}
+
+	err = header.ValidateWinningTicketMarker(winningTicketMarker)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = posterior.ValidatorState.VerifyBlockSeal(header, posterior.EntropyPool)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to verify block seal")
+	}
+
+	// (ρ‡, W) ≺ (EA, ρ†, κ′, Hp)
+	availableReports, err := posterior.PendingWorkReports.AssureAvailabilities(
+		header.TimeSlot,
+		b.AssuarancesExtrinsic.Assurances,
+		header.ParentHash,
+		posterior.ValidatorState.ActiveValidators,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to process assurances")
+	}
+
+	// ρ′ ≺ (EG, ρ‡, κ′, λ′, τ′, η′, α, δ, β†, ξ)
+	reporters, err := posterior.PendingWorkReports.GuaranteeNewWorkReports(
+		b.GuaranteesExtrinsic.Guarantees,
+		header.TimeSlot,
+		&posterior.EntropyPool,
+		posterior.ValidatorState.ActiveValidators,
+		posterior.ValidatorState.ArchivedValidators,
+		&posterior.AuthorizerPools,
+		&posterior.Services,
+		&intermediateRecentHistory,
+		&posterior.AccumulationQueue,
+		&posterior.AccumulationHistory,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to process guarantees")
+	}
+
+	// (θ′, ξ′, δ†, χ′, ι′, φ′) ≺ (W, θ, ξ, τ, τ′, δ, χ, ι, φ, η′)
+	outcome, err := accumulate.Update(
+		&posterior.AccumulationQueue,
+		&posterior.AccumulationHistory,
+		header.TimeSlot,
+		state.TimeSlot,
+		availableReports,
+		&accumulate.PartialState{
+			Services:           posterior.Services,
+			StagingValidators:  posterior.ValidatorState.StagingValidators,
+			AuthorizerQueues:   posterior.AuthorizerQueues,
+			PrivilegedServices: posterior.PrivilegedServices,
+		},
+		posterior.EntropyPool[0],
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to accumulate work reports")
+	}
+	posterior.Services = outcome.State.Services
+	posterior.ValidatorState.StagingValidators = outcome.State.StagingValidators
+	posterior.AuthorizerQueues = outcome.State.AuthorizerQueues
+	posterior.PrivilegedServices = outcome.State.PrivilegedServices
+
+	// δ‡ ≺ (t, δ†, τ′)
+	onTransferGasUsed := accumulate.ApplyTransfers(&posterior.Services, header.TimeSlot, outcome.Transfers)
+
+	// δ′ ≺ (EP, δ, δ‡, τ′)
+	_, err = posterior.Services.Update(&state.Services, header.TimeSlot, preimageRequests(b.PreimagesExtrinsic.Preimages))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to integrate preimages")
+	}
+
+	// α′ ≺ (H, EG, φ′, α)
+	authorizerHashes := make(map[uint32]common.Hash, len(b.GuaranteesExtrinsic.Guarantees))
+	for _, guarantee := range b.GuaranteesExtrinsic.Guarantees {
+		authorizerHashes[guarantee.WorkReport.CoreIndex] = guarantee.WorkReport.AuthorizerHash
+	}
+	posterior.AuthorizerPools.Update(header.TimeSlot, authorizerHashes, &posterior.AuthorizerQueues)
+
+	// β′ ≺ (H, EG, β†, C)
+	err = posterior.RecentHistory.Update(
+		headerHash,
+		header.PriorStateRoot,
+		outcome.ResultRoot(),
+		reportedWorkPackages(b.GuaranteesExtrinsic.Guarantees),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to update recent history")
+	}
+
+	// π′ ≺ (EG, EP, EA, ET, τ, κ′, π, H, W, I)
+	err = posterior.ActivityStatistics.UpdateValidators(
+		header.TimeSlot,
+		state.TimeSlot,
+		header.BlockAuthorIndex,
+		&b.Extrinsic,
+		reporters,
+		posterior.ValidatorState.ActiveValidators,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to update validator statistics")
+	}
+
+	err = posterior.ActivityStatistics.UpdateCores(&b.Extrinsic, availableReports)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to update core statistics")
+	}
+
+	posterior.ActivityStatistics.UpdateServices(&b.Extrinsic, outcome.Accumulated, outcome.GasUsed, receivedTransfers(outcome.Transfers), onTransferGasUsed)
+
+	// τ′ ≡ Ht
+	posterior.TimeSlot = header.TimeSlot
+
+	return posterior, nil
+}
+
+// {((gw)s)h ↦ ((gw)s)e ∣ g ∈ EG}
+func reportedWorkPackages(guarantees workreport.Guarantees) map[common.Hash]common.Hash {
+	workPackages := make(map[common.Hash]common.Hash, len(guarantees))
+	for _, guarantee := range guarantees {
+		spec := guarantee.WorkReport.AvailabilitySpecification
+		workPackages[spec.WorkPackageHash] = spec.SegmentRoot
+	}
+	return workPackages
+}
+
+// {d ↦ |R(t, d)| ∣ R(t, d) ≠ []}
+func receivedTransfers(transfers []accumulate.DeferredTransfer) map[service.ServiceId]uint32 {
+	counts := make(map[service.ServiceId]uint32)
+	for _, transfer := range transfers {
+		counts[transfer.Destination]++
+	}
+	return counts
+}
+
+// [(s, p) ∣ (s, p) <− EP]
+func preimageRequests(preimages []block.Preimage) []*service.PreimageRequest {
+	requests := make([]*service.PreimageRequest, len(preimages))
+	for i, preimage := range preimages {
+		requests[i] = &service.PreimageRequest{
+			ServiceId: preimage.Requester,
+			Preimage:  preimage.Blob,
+		}
+	}
+	return requests
+}
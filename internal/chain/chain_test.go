@@ -0,0 +1,217 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	authqueue "github.com/shunsukew/gojam/internal/authorizer/queue"
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/dispute"
+	"github.com/shunsukew/gojam/internal/entropy"
+	"github.com/shunsukew/gojam/internal/history"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	jamstate "github.com/shunsukew/gojam/internal/state"
+	"github.com/shunsukew/gojam/internal/validator"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestState() *jamstate.State {
+	validators := func() *[common.NumOfValidators]*keys.ValidatorKey {
+		v := [common.NumOfValidators]*keys.ValidatorKey{}
+		for i := range v {
+			v[i] = &keys.ValidatorKey{Ed25519PublicKey: make([]byte, 32)}
+		}
+		return &v
+	}
+
+	state := &jamstate.State{
+		TimeSlot: 10,
+		RecentHistory: history.RecentHistory{
+			{HeaderHash: common.Hash{1}, WorkPackageHashes: map[common.Hash]common.Hash{}},
+		},
+	}
+	for i := range state.AuthorizerQueues {
+		state.AuthorizerQueues[i] = &authqueue.AuthorizerQueue{}
+	}
+	state.ValidatorState.StagingValidators = validators()
+	state.ValidatorState.ActiveValidators = validators()
+	state.ValidatorState.ArchivedValidators = validators()
+	state.ValidatorState.SafroleState = &safrole.SafroleState{PendingValidators: validators()}
+
+	return state
+}
+
+// Build a block on top of the state, with the parent hash and the prior state root of the state.
+func newTestBlock(t *testing.T, state *jamstate.State, timeSlot jamtime.TimeSlot, extrinsic block.Extrinsic) *block.Block {
+	extrinsicHash, err := extrinsic.Hash()
+	require.NoError(t, err)
+	stateRoot, err := state.Root()
+	require.NoError(t, err)
+
+	return &block.Block{
+		Header: block.Header{
+			ParentHash:     state.RecentHistory[len(state.RecentHistory)-1].HeaderHash,
+			PriorStateRoot: stateRoot,
+			TimeSlot:       timeSlot,
+			ExtrinsicHash:  extrinsicHash,
+		},
+		Extrinsic: extrinsic,
+	}
+}
+
+func TestImportBlockKeepsPriorStateOnError(t *testing.T) {
+	t.Run("Timeslot not after prior timeslot", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		b := newTestBlock(t, state, state.TimeSlot, block.Extrinsic{})
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, jamtime.ErrInvalidTimeSlot)
+		require.Equal(t, expected, state)
+	})
+
+	t.Run("Invalid disputes", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		verdict := func(workReportHash common.Hash) *dispute.Verdict {
+			judgements := &dispute.Judgements{}
+			for i := range judgements {
+				judgements[i] = &dispute.Judgement{ValidatorIndex: uint32(i), Signature: make([]byte, ed25519.SignatureSize)}
+			}
+			return &dispute.Verdict{WorkReportHash: workReportHash, Judgements: judgements}
+		}
+
+		b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{DisputesExtrinsic: block.DisputesExtrinsic{
+			Verdicts: []*dispute.Verdict{verdict(common.Hash{2}), verdict(common.Hash{1})},
+		}})
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, dispute.ErrInvalidVerdicts)
+		require.Equal(t, expected, state)
+	})
+
+	t.Run("Extrinsic hash mismatch", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+		b.TicketsExtrinsic.Tickets = []safrole.TicketProof{{EntryIndex: 1}}
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, block.ErrInvalidExtrinsicHash)
+		require.Equal(t, expected, state)
+	})
+
+	t.Run("Parent hash mismatch", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+		b.Header.ParentHash = common.Hash{0xff}
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, block.ErrInvalidParentHash)
+		require.Equal(t, expected, state)
+	})
+
+	t.Run("Prior state root mismatch", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+		b.Header.PriorStateRoot = common.Hash{0xff}
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, block.ErrInvalidPriorStateRoot)
+		require.Equal(t, expected, state)
+	})
+
+	t.Run("Offenders marker without offenders", func(t *testing.T) {
+		state := newTestState()
+		expected := state.Clone()
+
+		b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+		b.Header.OffendersMarker = &block.OffendersMarker{Offenders: []ed25519.PublicKey{make([]byte, ed25519.PublicKeySize)}}
+		_, err := ImportBlock(state, b)
+		require.ErrorIs(t, err, block.ErrInvalidOffendersMarker)
+		require.Equal(t, expected, state)
+	})
+}
+
+// Seal the header in fallback mode as the validator of the given index, Gray Paper (6.16) (6.17).
+func sealHeader(t *testing.T, state *jamstate.State, header *block.Header, authorIndex uint16) {
+	secret, err := bandersnatch.NewPrivateKeyFromSeed([]byte("block author"))
+	require.NoError(t, err)
+	publicKey, err := secret.PublicKey()
+	require.NoError(t, err)
+
+	state.ValidatorState.ActiveValidators[authorIndex].BandersnatchPublicKey = publicKey
+	fallbackKeys := safrole.FallbackKeys{}
+	for i := range fallbackKeys {
+		fallbackKeys[i] = publicKey
+	}
+	state.ValidatorState.SafroleState.SealingKeySeries = &fallbackKeys
+	header.BlockAuthorIndex = authorIndex
+
+	// The author key and the sealing keys are part of the prior state.
+	header.PriorStateRoot, err = state.Root()
+	require.NoError(t, err)
+
+	sealInput := append([]byte(crypto.JamFallbackSealStatement), state.EntropyPool[3][:]...)
+
+	// The seal output only depends on the input, so it can be derived before signing the unsigned header.
+	seal, err := bandersnatch.IetfSign(secret, sealInput, []byte{})
+	require.NoError(t, err)
+	sealOutput, err := seal.Output()
+	require.NoError(t, err)
+
+	header.VRFSignature, err = bandersnatch.IetfSign(secret, append([]byte(crypto.JamEntropyStatement), sealOutput[:]...), []byte{})
+	require.NoError(t, err)
+
+	unsignedHeader, err := header.EncodeUnsigned()
+	require.NoError(t, err)
+	header.BlockSealSignature, err = bandersnatch.IetfSign(secret, sealInput, unsignedHeader)
+	require.NoError(t, err)
+}
+
+func TestImportBlock(t *testing.T) {
+	state := newTestState()
+	state.EntropyPool = entropy.EntropyPool{{1}, {2}, {3}, {4}}
+
+	b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+	sealHeader(t, state, &b.Header, 1)
+	expected := state.Clone()
+
+	posterior, err := ImportBlock(state, b)
+	require.NoError(t, err)
+	require.Equal(t, expected, state, "prior state must not be modified")
+
+	headerHash, err := b.Header.Hash()
+	require.NoError(t, err)
+
+	require.Equal(t, b.Header.TimeSlot, posterior.TimeSlot)
+	require.Len(t, posterior.RecentHistory, 2)
+	require.Equal(t, b.Header.PriorStateRoot, posterior.RecentHistory[0].StateRoot, "prior state root must be set to the last recent block")
+	require.Equal(t, headerHash, posterior.RecentHistory[1].HeaderHash)
+
+	entropySource, err := b.Header.EntropySource()
+	require.NoError(t, err)
+	expectedEntropy := state.EntropyPool
+	expectedEntropy.AccumulateEntropy(entropySource)
+	require.Equal(t, expectedEntropy, posterior.EntropyPool)
+}
+
+func TestImportBlockRejectsInvalidSeal(t *testing.T) {
+	state := newTestState()
+
+	b := newTestBlock(t, state, state.TimeSlot+1, block.Extrinsic{})
+	b.Header.TimeSlot++
+	sealHeader(t, state, &b.Header, 1)
+
+	// Any change to the unsigned header invalidates the seal.
+	b.Header.TimeSlot--
+	_, err := ImportBlock(state, b)
+	require.ErrorIs(t, err, validator.ErrInvalidBlockSeal)
+}
@@ -165,8 +165,8 @@ type Judgements [common.NumOfSuperMajorityValidators]*Judgement
 
 type Judgement struct {
 	Vote           bool
-	ValidatorIndex uint32
-	Signature      []byte // 𝔼
+	ValidatorIndex uint32 `codec:"size=2"`
+	Signature      []byte `codec:"length=64"` // 𝔼
 }
 
 func (j *Judgements) isSortedNonDuplicates() bool {
@@ -184,7 +184,7 @@ type Culprits []*Culprit
 type Culprit struct {
 	WorkReportHash common.Hash       // r
 	CulpritKey     ed25519.PublicKey // k
-	Signature      []byte            // 𝔼
+	Signature      []byte            `codec:"length=64"` // 𝔼
 }
 
 func (culprits Culprits) isSortedNonDuplicates() bool {
@@ -202,7 +202,7 @@ type Fault struct {
 	WorkReportHash common.Hash       // r
 	Vote           bool              // v
 	FaultKey       ed25519.PublicKey // k
-	Signature      []byte            // 𝔼
+	Signature      []byte            `codec:"length=64"` // 𝔼
 }
 
 func (faults Faults) isSortedNonDuplicates() bool {
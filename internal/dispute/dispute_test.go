@@ -0,0 +1,46 @@
+package dispute
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisputesEncodeDecode(t *testing.T) {
+	judgements := &Judgements{}
+	for i := range judgements {
+		judgements[i] = &Judgement{Vote: true, ValidatorIndex: uint32(i), Signature: make([]byte, ed25519.SignatureSize)}
+	}
+
+	disputes := struct {
+		Verdicts Verdicts
+		Culprits Culprits
+		Faults   Faults
+	}{
+		Verdicts: Verdicts{{WorkReportHash: common.Hash{1}, Epoch: 2, Judgements: judgements}},
+		Culprits: Culprits{
+			{WorkReportHash: common.Hash{1}, CulpritKey: make([]byte, ed25519.PublicKeySize), Signature: make([]byte, ed25519.SignatureSize)},
+		},
+		Faults: Faults{
+			{WorkReportHash: common.Hash{1}, Vote: false, FaultKey: make([]byte, ed25519.PublicKeySize), Signature: make([]byte, ed25519.SignatureSize)},
+		},
+	}
+
+	encoded, err := codec.Encode(disputes)
+	require.NoError(t, err)
+
+	judgementSize := 1 + 2 + ed25519.SignatureSize
+	verdictSize := common.HashLength + 4 + common.NumOfSuperMajorityValidators*judgementSize
+	culpritSize := common.HashLength + ed25519.PublicKeySize + ed25519.SignatureSize
+	faultSize := common.HashLength + 1 + ed25519.PublicKeySize + ed25519.SignatureSize
+	require.Len(t, encoded, 1+verdictSize+1+culpritSize+1+faultSize)
+
+	decoded := disputes
+	decoded.Verdicts, decoded.Culprits, decoded.Faults = nil, nil, nil
+	err = codec.Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, disputes, decoded)
+}
@@ -15,9 +15,10 @@ const (
 // EntropyPool[1] ~ EntropyPool[3] are historical entropies in previous "epochs".
 type EntropyPool [EntropyPoolSize]common.Hash
 
+// Should be invoked when e' > e, instead of AccumulateEntropy
 func (ep *EntropyPool) RotateEntropies(vrfOutput bandersnatch.VrfOutput) {
 	ep.rotateHistoricalEntropies()
-	ep.updateEntropy(vrfOutput)
+	ep.AccumulateEntropy(vrfOutput)
 }
 
 func (ep *EntropyPool) rotateHistoricalEntropies() {
@@ -29,6 +30,6 @@ func (ep *EntropyPool) rotateHistoricalEntropies() {
 
 // Should be invoked at each timeslot
 // Gray Paper equation (6.22)
-func (ep *EntropyPool) updateEntropy(vrfOutput bandersnatch.VrfOutput) {
+func (ep *EntropyPool) AccumulateEntropy(vrfOutput bandersnatch.VrfOutput) {
 	ep[0] = blake2b.Sum256(append(ep[0][:], vrfOutput[:]...))
 }
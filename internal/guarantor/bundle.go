@@ -0,0 +1,48 @@
+package guarantor
+
+import (
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// Bundle is a work package with the data its work items refer to, as it is refined and distributed for auditing.
+type Bundle struct {
+	Package        *workpackage.Package   // p
+	Extrinsics     [][][]byte             // x̄: The extrinsic data of each work item, in the order of its references.
+	Imports        [][]workreport.Segment // i̅: The segments imported by each work item, in the order of its references.
+	Justifications [][][]common.Hash      // j̄: The justification J_0 of each imported segment against the root of the segments it is imported from.
+}
+
+// Encode returns the encoded work package bundle, Gray Paper (14.14)
+// E(p) ⌢ E(x̄) ⌢ E(i̅) ⌢ E(j̄), where the data and segments are concatenated without length prefixes since the work items give their lengths,
+// and each justification is prefixed with its length since it depends on the number of segments exported by the work package imported from.
+func (b *Bundle) Encode() ([]byte, error) {
+	encoded, err := codec.Encode(b.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, extrinsics := range b.Extrinsics {
+		for _, extrinsic := range extrinsics {
+			encoded = append(encoded, extrinsic...)
+		}
+	}
+	for _, segments := range b.Imports {
+		for _, segment := range segments {
+			encoded = append(encoded, segment[:]...)
+		}
+	}
+	for _, justifications := range b.Justifications {
+		for _, justification := range justifications {
+			encodedJustification, err := codec.Encode(justification)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, encodedJustification...)
+		}
+	}
+
+	return encoded, nil
+}
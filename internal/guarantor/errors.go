@@ -0,0 +1,10 @@
+package guarantor
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidCoreIndex   = errors.New("invalid core index")
+	ErrUnauthorized       = errors.New("work package is not authorized")
+	ErrUnknownSegmentRoot = errors.New("unknown segment root of imported work package")
+	ErrUnjustifiedImport  = errors.New("imported segment is not justified by its segment root")
+)
@@ -0,0 +1,182 @@
+// Package guarantor implements the in-core computation of a guarantor, turning work packages into guaranteed work reports.
+// Gray Paper section 14.
+package guarantor
+
+import (
+	"crypto/ed25519"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/history"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/refine"
+	jamstate "github.com/shunsukew/gojam/internal/state"
+	"github.com/shunsukew/gojam/internal/work"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/shunsukew/gojam/pkg/merkle"
+)
+
+// Guarantor computes and signs the work reports of the work packages submitted to the core it is assigned to.
+type Guarantor struct {
+	validatorIndex uint32             // The index of the validator in the current validator set.
+	privateKey     ed25519.PrivateKey // The Ed25519 secret of the validator.
+}
+
+func New(validatorIndex uint32, privateKey ed25519.PrivateKey) *Guarantor {
+	return &Guarantor{validatorIndex: validatorIndex, privateKey: privateKey}
+}
+
+// Guarantee computes the work report of the bundle on the core, and returns the guarantee with the credential of the guarantor,
+// together with the segments exported by the work package.
+// The work package must be authorized by an authorizer in the pool of the core, anchored in the recent blocks,
+// and import segments by work package hash only from the work packages reported in the recent blocks.
+// Every imported segment must be justified against the root of the segments it is imported from.
+// Gray Paper (14.12)
+// Ξ(p, c) ≡ (s: A(H(p), E(p, x̄, i̅, j̄), e), x: p_x, c, a: p_a, o, l, r, g)
+// where o = Ψ_I(p, c) ∈ Y, (r, e) = T[I(p, j) ∣ j <− N_|p_w|]
+func (g *Guarantor) Guarantee(
+	state *jamstate.State,
+	timeSlot jamtime.TimeSlot, // t: The time slot of the guarantee.
+	coreIndex uint32, // c
+	bundle *Bundle,
+) (*workreport.Guarantee, []workreport.Segment, error) {
+	p := bundle.Package
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if coreIndex >= common.NumOfCores {
+		return nil, nil, errors.WithMessagef(ErrInvalidCoreIndex, "core index %d must be less than %d", coreIndex, common.NumOfCores)
+	}
+
+	if p.RefinementContext == nil {
+		return nil, nil, errors.WithMessage(work.ErrInvalidRefinementContext, "work package has no refinement context")
+	}
+	if err := p.RefinementContext.ValidateAnchors(timeSlot, &state.RecentHistory); err != nil {
+		return nil, nil, err
+	}
+
+	authorizerHash := p.AuthorizerHash()
+	if !slices.Contains(state.AuthorizerPools[coreIndex], authorizerHash) {
+		return nil, nil, errors.WithMessagef(ErrUnauthorized, "authorizer hash %s is not in the pool of core %d", authorizerHash.ToHex(), coreIndex)
+	}
+
+	segmentRootLookup, err := lookupSegmentRoots(p, state.RecentHistory)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyImports(bundle, segmentRootLookup); err != nil {
+		return nil, nil, err
+	}
+
+	authorization, authGasUsed, err := refine.IsAuthorized(&state.Services, p, coreIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorization.Output == nil {
+		return nil, nil, errors.WithMessagef(ErrUnauthorized, "is-authorized failed with error %d", authorization.Error)
+	}
+
+	results, exports, err := refine.Refine(&state.Services, p, authorization.Output, bundle.Imports, bundle.Extrinsics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	packageHash, err := p.Hash()
+	if err != nil {
+		return nil, nil, err
+	}
+	encodedBundle, err := bundle.Encode()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &workreport.WorkReport{
+		AvailabilitySpecification: workreport.NewAvailabilitySpecification(packageHash, encodedBundle, exports),
+		RefinementContext:         p.RefinementContext,
+		CoreIndex:                 coreIndex,
+		AuthorizerHash:            authorizerHash,
+		Output:                    authorization.Output,
+		SegmentRootLookup:         segmentRootLookup,
+		WorkResults:               results,
+		AuthGasUsed:               authGasUsed,
+	}
+
+	credential, err := g.Sign(report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &workreport.Guarantee{
+		WorkReport:  report,
+		Timeslot:    timeSlot,
+		Credentials: []*workreport.Credential{credential},
+	}, exports, nil
+}
+
+// Sign returns the credential of the guarantor on the work report, so that co-guarantors can add theirs to the guarantee.
+// Gray Paper (11.26) s ∈ E_(k_v)e⟨X_G ⌢ H(E(w))⟩
+func (g *Guarantor) Sign(report *workreport.WorkReport) (*workreport.Credential, error) {
+	reportHash, err := report.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	message := append([]byte(crypto.JamGuaranteeStatement), reportHash[:]...)
+	return &workreport.Credential{
+		ValidatorIndex: g.validatorIndex,
+		Signature:      ed25519.Sign(g.privateKey, message),
+	}, nil
+}
+
+// lookupSegmentRoots returns the segment roots of the work packages from which the work items import segments by hash, l.
+func lookupSegmentRoots(p *workpackage.Package, recentBlocks history.RecentHistory) (map[common.Hash]common.Hash, error) {
+	lookup := make(map[common.Hash]common.Hash)
+	for _, item := range p.WorkItems {
+		for _, segment := range item.ImportSegments {
+			if !segment.IsWorkPackageHash {
+				continue
+			}
+
+			found := false
+			for _, block := range recentBlocks {
+				if root, ok := block.WorkPackageHashes[segment.TreeRoot]; ok {
+					lookup[segment.TreeRoot], found = root, true
+					break
+				}
+			}
+			if !found {
+				return nil, errors.WithMessagef(ErrUnknownSegmentRoot, "work package %s is not reported in the recent blocks", segment.TreeRoot.ToHex())
+			}
+		}
+	}
+	return lookup, nil
+}
+
+// verifyImports checks that the justification of each imported segment leads to the segment root it is imported from.
+func verifyImports(bundle *Bundle, segmentRootLookup map[common.Hash]common.Hash) error {
+	items := bundle.Package.WorkItems
+	if len(bundle.Imports) != len(items) || len(bundle.Justifications) != len(items) {
+		return errors.WithMessagef(ErrUnjustifiedImport, "%d import and %d justification lists for %d work items", len(bundle.Imports), len(bundle.Justifications), len(items))
+	}
+
+	for i, item := range items {
+		if len(bundle.Imports[i]) != len(item.ImportSegments) || len(bundle.Justifications[i]) != len(item.ImportSegments) {
+			return errors.WithMessagef(ErrUnjustifiedImport, "%d imports and %d justifications for work item %d, expected %d",
+				len(bundle.Imports[i]), len(bundle.Justifications[i]), i, len(item.ImportSegments))
+		}
+
+		for j, segment := range item.ImportSegments {
+			root := segment.TreeRoot
+			if segment.IsWorkPackageHash {
+				root = segmentRootLookup[segment.TreeRoot]
+			}
+			if merkle.JustifiedRoot(bundle.Imports[i][j][:], int(segment.Index), bundle.Justifications[i][j], merkle.Blake2b) != root {
+				return errors.WithMessagef(ErrUnjustifiedImport, "import %d of work item %d, segment %d of root %s", j, i, segment.Index, root.ToHex())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package guarantor
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/history"
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	jamstate "github.com/shunsukew/gojam/internal/state"
+	"github.com/shunsukew/gojam/internal/work"
+	workitem "github.com/shunsukew/gojam/internal/work/item"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	testServiceId   service.ServiceId = 1000
+	testTimeSlot    jamtime.TimeSlot  = 10
+	readOnlyAddress                   = 1 << 16 // The read-only data zone of a standard program.
+)
+
+// standardProgram builds a standard program blob which halts with the read-only data as its output, after exporting it if export is set.
+func standardProgram(readOnlyData []byte, export bool) []byte {
+	loadAddress := pvmtest.LoadImm(7, readOnlyAddress)
+	instructions := [][]byte{loadAddress, pvmtest.LoadImm(8, uint32(len(readOnlyData)))}
+	if export {
+		instructions = append(instructions, pvmtest.Ecalli(hostcall.Export), loadAddress)
+	}
+	return pvmtest.StandardProgram(0, readOnlyData, 0, append(instructions, pvmtest.Halt)...)
+}
+
+// The segments exported by the work package {3} reported in the recent blocks, of which the bundle imports the last one.
+var exportedSegments = []workreport.Segment{{5}, {6}, {7}}
+
+func newTestState(authCode, refineCode []byte) *jamstate.State {
+	state := &jamstate.State{
+		RecentHistory: history.RecentHistory{{
+			HeaderHash:        common.Hash{1},
+			StateRoot:         common.Hash{2},
+			WorkPackageHashes: map[common.Hash]common.Hash{{3}: workreport.SegmentRoot(exportedSegments)},
+		}},
+	}
+
+	account := &service.ServiceAccount{
+		Preimages:    map[common.Hash]common.Blob{},
+		PreimageMeta: map[service.PreimageMeta]service.PreimageAvailabilityHistory{},
+	}
+	for _, code := range [][]byte{authCode, refineCode} {
+		hash := common.Hash(blake2b.Sum256(code))
+		account.Preimages[hash] = code
+		account.PreimageMeta[service.PreimageMeta{Hash: hash, BlobLength: common.BlobLength(len(code))}] = service.PreimageAvailabilityHistory{0}
+	}
+	state.Services.Save(testServiceId, account)
+
+	return state
+}
+
+func newTestBundle(authCode, refineCode []byte) *Bundle {
+	extrinsic := []byte("extrinsic")
+
+	return &Bundle{
+		Package: &workpackage.Package{
+			AuthToken:    []byte("token"),
+			ServiceIndex: testServiceId,
+			AuthCodeHash: blake2b.Sum256(authCode),
+			AuthParam:    []byte("param"),
+			RefinementContext: &work.RefinementContext{
+				AnchorHeaderHash:     common.Hash{1},
+				AnchorStateRoot:      common.Hash{2},
+				LookupAnchorTimeSlot: testTimeSlot,
+			},
+			WorkItems: []*workitem.Item{{
+				ServiceId:          testServiceId,
+				CodeHash:           blake2b.Sum256(refineCode),
+				Payload:            []byte("payload"),
+				RefineGasLimit:     1000,
+				AccumulateGasLimit: 100,
+				ImportSegments:     []workitem.ImportSegment{{TreeRoot: common.Hash{3}, IsWorkPackageHash: true, Index: 2}},
+				Extrinsics:         []workitem.ExtrinsicRef{{Hash: blake2b.Sum256(extrinsic), Length: uint32(len(extrinsic))}},
+				ExportCount:        1,
+			}},
+		},
+		Extrinsics:     [][][]byte{{extrinsic}},
+		Imports:        [][]workreport.Segment{{exportedSegments[2]}},
+		Justifications: [][][]common.Hash{{workreport.Justification(workreport.PagedProofs(exportedSegments)[0], len(exportedSegments), 2)}},
+	}
+}
+
+func TestGuarantee(t *testing.T) {
+	authCode := standardProgram([]byte("authorized"), false)
+	refineCode := standardProgram([]byte("refined"), true)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	guarantor := New(3, privateKey)
+
+	t.Run("guarantee", func(t *testing.T) {
+		state := newTestState(authCode, refineCode)
+		bundle := newTestBundle(authCode, refineCode)
+		state.AuthorizerPools[1] = []common.Hash{bundle.Package.AuthorizerHash()}
+
+		guarantee, exports, err := guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.NoError(t, err)
+		require.Equal(t, testTimeSlot, guarantee.Timeslot)
+
+		var exported workreport.Segment
+		copy(exported[:], "refined")
+		require.Equal(t, []workreport.Segment{exported}, exports)
+
+		report := guarantee.WorkReport
+		packageHash, err := bundle.Package.Hash()
+		require.NoError(t, err)
+		encodedBundle, err := bundle.Encode()
+		require.NoError(t, err)
+
+		// The bundle ends with the imported segment and its justification of two hashes in a tree of four leaves.
+		justification := bundle.Justifications[0][0]
+		require.Len(t, justification, 2)
+		tail := append(exportedSegments[2][:], 2)
+		tail = append(append(tail, justification[0][:]...), justification[1][:]...)
+		require.Equal(t, tail, encodedBundle[len(encodedBundle)-len(tail):])
+
+		require.Equal(t, workreport.NewAvailabilitySpecification(packageHash, encodedBundle, exports), report.AvailabilitySpecification)
+		require.Equal(t, bundle.Package.RefinementContext, report.RefinementContext)
+		require.Equal(t, uint32(1), report.CoreIndex)
+		require.Equal(t, bundle.Package.AuthorizerHash(), report.AuthorizerHash)
+		require.Equal(t, []byte("authorized"), report.Output)
+		require.Equal(t, map[common.Hash]common.Hash{{3}: workreport.SegmentRoot(exportedSegments)}, report.SegmentRootLookup)
+		require.NotZero(t, report.AuthGasUsed)
+
+		require.Len(t, report.WorkResults, 1)
+		require.Equal(t, &workreport.ExecResult{Output: []byte("refined")}, report.WorkResults[0].ExecResult)
+		require.Equal(t, uint32(1), report.WorkResults[0].RefineLoad.Imports)
+
+		require.Len(t, guarantee.Credentials, 1)
+		require.Equal(t, uint32(3), guarantee.Credentials[0].ValidatorIndex)
+		reportHash, err := report.Hash()
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(publicKey, append([]byte(crypto.JamGuaranteeStatement), reportHash[:]...), guarantee.Credentials[0].Signature))
+	})
+
+	t.Run("authorizer not in the pool", func(t *testing.T) {
+		_, _, err := guarantor.Guarantee(newTestState(authCode, refineCode), testTimeSlot, 1, newTestBundle(authCode, refineCode))
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("is-authorized fails", func(t *testing.T) {
+		state := newTestState(authCode, refineCode)
+		bundle := newTestBundle(authCode, refineCode)
+		bundle.Package.AuthCodeHash = common.Hash{9}
+		state.AuthorizerPools[1] = []common.Hash{bundle.Package.AuthorizerHash()}
+
+		_, _, err := guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("unknown anchor", func(t *testing.T) {
+		state := newTestState(authCode, refineCode)
+		bundle := newTestBundle(authCode, refineCode)
+		bundle.Package.RefinementContext.AnchorHeaderHash = common.Hash{9}
+
+		_, _, err := guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.ErrorIs(t, err, work.ErrInvalidRefinementContext)
+	})
+
+	t.Run("unknown segment root", func(t *testing.T) {
+		state := newTestState(authCode, refineCode)
+		bundle := newTestBundle(authCode, refineCode)
+		bundle.Package.WorkItems[0].ImportSegments[0].TreeRoot = common.Hash{9}
+		state.AuthorizerPools[1] = []common.Hash{bundle.Package.AuthorizerHash()}
+
+		_, _, err := guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.ErrorIs(t, err, ErrUnknownSegmentRoot)
+	})
+
+	t.Run("unjustified import", func(t *testing.T) {
+		state := newTestState(authCode, refineCode)
+		bundle := newTestBundle(authCode, refineCode)
+		bundle.Imports[0][0] = exportedSegments[1]
+		state.AuthorizerPools[1] = []common.Hash{bundle.Package.AuthorizerHash()}
+
+		_, _, err := guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.ErrorIs(t, err, ErrUnjustifiedImport)
+
+		bundle = newTestBundle(authCode, refineCode)
+		bundle.Justifications = nil
+		_, _, err = guarantor.Guarantee(state, testTimeSlot, 1, bundle)
+		require.ErrorIs(t, err, ErrUnjustifiedImport)
+	})
+}
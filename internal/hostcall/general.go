@@ -0,0 +1,196 @@
+package hostcall
+
+import (
+	"math"
+
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"golang.org/x/crypto/blake2b"
+)
+
+// HandleGas is Ω_G, setting ω7 to the remaining gas ϱ′.
+func HandleGas(m *pvm.Machine) (pvm.ExitReason, bool) {
+	m.Registers[7] = uint64(m.Gas)
+	return Continue()
+}
+
+// HandleLookup is Ω_L, reading the preimage of the hash µ[ω8...+32] from the service ω7, or the service s itself.
+func HandleLookup(m *pvm.Machine, serviceId service.ServiceId, services *service.Services) (pvm.ExitReason, bool) {
+	account := lookupAccount(m.Registers[7], serviceId, services)
+
+	hash, err := m.ReadMemory(m.Registers[8], common.HashLength)
+	if err != nil {
+		return Exit(pvm.Panic)
+	}
+
+	var preimage []byte
+	found := false
+	if account != nil {
+		preimage, found = account.Preimages[common.Hash(hash)]
+	}
+
+	return WriteValue(m, preimage, found, m.Registers[9], m.Registers[10], m.Registers[11])
+}
+
+// HandleHistoricalLookup is Ω_H, reading the preimage of the hash µ[ω8...+32] from the service ω7, or the service s itself,
+// as it was available for lookup at the time slot t, Λ(a, t, h).
+func HandleHistoricalLookup(m *pvm.Machine, serviceId service.ServiceId, services *service.Services, timeSlot jamtime.TimeSlot) (pvm.ExitReason, bool) {
+	account := lookupAccount(m.Registers[7], serviceId, services)
+
+	hash, err := m.ReadMemory(m.Registers[8], common.HashLength)
+	if err != nil {
+		return Exit(pvm.Panic)
+	}
+
+	var preimage []byte
+	if account != nil {
+		preimage = account.LookupPreimage(common.Hash(hash), timeSlot)
+	}
+
+	return WriteValue(m, preimage, preimage != nil, m.Registers[9], m.Registers[10], m.Registers[11])
+}
+
+// HandleRead is Ω_R, reading the storage item of the key µ[ω8...+ω9] from the service ω7, or the service s itself.
+func HandleRead(m *pvm.Machine, serviceId service.ServiceId, services *service.Services) (pvm.ExitReason, bool) {
+	targetId := serviceId
+	if m.Registers[7] != math.MaxUint64 {
+		targetId = service.ServiceId(m.Registers[7])
+	}
+	account := lookupAccount(m.Registers[7], serviceId, services)
+
+	key, err := m.ReadMemory(m.Registers[8], m.Registers[9])
+	if err != nil {
+		return Exit(pvm.Panic)
+	}
+
+	var value []byte
+	found := false
+	if account != nil {
+		value, found = account.StorageItems[StorageKey(targetId, key)]
+	}
+
+	return WriteValue(m, value, found, m.Registers[10], m.Registers[11], m.Registers[12])
+}
+
+// HandleWrite is Ω_W, setting the storage item of the key µ[ω7...+ω8] of the service s to µ[ω9...+ω10], or removing it if ω10 = 0.
+// ω7 is set to the length of the previous value, or NONE.
+func HandleWrite(m *pvm.Machine, serviceId service.ServiceId, services *service.Services) (pvm.ExitReason, bool) {
+	key, err := m.ReadMemory(m.Registers[7], m.Registers[8])
+	if err != nil {
+		return Exit(pvm.Panic)
+	}
+	var value []byte
+	if m.Registers[10] != 0 {
+		value, err = m.ReadMemory(m.Registers[9], m.Registers[10])
+		if err != nil {
+			return Exit(pvm.Panic)
+		}
+	}
+
+	account, ok := services.Get(serviceId)
+	if !ok {
+		return Exit(pvm.Panic)
+	}
+
+	storageKey := StorageKey(serviceId, key)
+	previous, existed := account.StorageItems[storageKey]
+
+	// a = s except a_s[k] = v, or the key removed; FULL if a_t > a_b
+	updated := account.Clone()
+	if value == nil {
+		delete(updated.StorageItems, storageKey)
+	} else {
+		if updated.StorageItems == nil {
+			updated.StorageItems = make(map[common.Hash]common.Blob)
+		}
+		updated.StorageItems[storageKey] = value
+	}
+	if updated.ThresholdBalance() > updated.Balance {
+		m.Registers[7] = FULL
+		return Continue()
+	}
+	account.StorageItems = updated.StorageItems
+
+	if existed {
+		m.Registers[7] = uint64(len(previous))
+	} else {
+		m.Registers[7] = NONE
+	}
+	return Continue()
+}
+
+// HandleInfo is Ω_I, writing E(t_c, E8(t_b, t_t, t_g, t_m, t_o), E4(t_i)) of the service ω7, or the service s itself, at µ[ω8].
+func HandleInfo(m *pvm.Machine, serviceId service.ServiceId, services *service.Services) (pvm.ExitReason, bool) {
+	account := lookupAccount(m.Registers[7], serviceId, services)
+	if account == nil {
+		m.Registers[7] = NONE
+		return Continue()
+	}
+
+	footprint := account.Footprint()
+
+	info := append([]byte{}, account.CodeHash[:]...)
+	info = append(info, codec.EncodeUint(uint64(account.Balance), 8)...)
+	info = append(info, codec.EncodeUint(uint64(account.ThresholdBalance()), 8)...)
+	info = append(info, codec.EncodeUint(uint64(account.AccumulateGas), 8)...)
+	info = append(info, codec.EncodeUint(uint64(account.OnTransferGas), 8)...)
+	info = append(info, codec.EncodeUint(footprint.SizeOfStorageItems, 8)...)
+	info = append(info, codec.EncodeUint(uint64(footprint.NumOfStorageItems), 4)...)
+
+	if err := m.WriteMemory(m.Registers[8], info); err != nil {
+		return Exit(pvm.Panic)
+	}
+
+	m.Registers[7] = OK
+	return Continue()
+}
+
+// StorageKey is the key of the storage item k of the service s, H(E4(s) ⌢ k).
+func StorageKey(serviceId service.ServiceId, key []byte) common.Hash {
+	return blake2b.Sum256(append(codec.EncodeUint(uint64(serviceId), 4), key...))
+}
+
+// ReadHash reads a hash at the address given by a register.
+func ReadHash(m *pvm.Machine, address uint64) (common.Hash, bool) {
+	hash, err := m.ReadMemory(address, common.HashLength)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	return common.Hash(hash), true
+}
+
+// The service of the id given by a register, where 2⁶⁴ − 1 denotes the service s itself.
+func lookupAccount(id uint64, serviceId service.ServiceId, services *service.Services) *service.ServiceAccount {
+	if id == math.MaxUint64 {
+		id = uint64(serviceId)
+	}
+	if id > math.MaxUint32 {
+		return nil
+	}
+
+	account, ok := services.Get(service.ServiceId(id))
+	if !ok {
+		return nil
+	}
+	return account
+}
+
+// WriteValue writes v[f...+l] at µ[o], with f = min(ω_f, |v|) and l = min(ω_l, |v| − f), and sets ω7 to |v|, or NONE if not found.
+func WriteValue(m *pvm.Machine, value []byte, found bool, address, offset, length uint64) (pvm.ExitReason, bool) {
+	f := min(offset, uint64(len(value)))
+	l := min(length, uint64(len(value))-f)
+
+	if err := m.WriteMemory(address, value[f:f+l]); err != nil {
+		return Exit(pvm.Panic)
+	}
+
+	if !found {
+		m.Registers[7] = NONE
+	} else {
+		m.Registers[7] = uint64(len(value))
+	}
+	return Continue()
+}
@@ -0,0 +1,102 @@
+package hostcall
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	const serviceId service.ServiceId = 1
+
+	// The balance covers exactly one storage item of a 5 octets value, B_S + B_I + B_L·(32 + 5).
+	var services service.Services
+	services.Save(serviceId, &service.ServiceAccount{
+		Balance: service.BasicMinimumBalance + service.ItemMinimumBalance + service.OctetMinimumBalance*(32+5),
+	})
+
+	m := pvmtest.Machine(100)
+	m.Memory.Set(pvmtest.Address, []byte("keyvalue"))
+
+	// Writing a value exceeding the threshold balance responds FULL.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10] = pvmtest.Address, 3, pvmtest.Address+3, 6
+	_, exited := HandleWrite(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, FULL, m.Registers[7])
+
+	// Write "value" to "key".
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10] = pvmtest.Address, 3, pvmtest.Address+3, 5
+	_, exited = HandleWrite(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, NONE, m.Registers[7])
+
+	account, _ := services.Get(serviceId)
+	require.Equal(t, common.Blob("value"), account.StorageItems[StorageKey(serviceId, []byte("key"))])
+
+	// Read "alu" of "value" at pvmtest.Address + 16.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10], m.Registers[11], m.Registers[12] = math.MaxUint64, pvmtest.Address, 3, pvmtest.Address+16, 1, 3
+	_, exited = HandleRead(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, uint64(5), m.Registers[7])
+	require.Equal(t, []byte("alu"), m.Memory.Get(pvmtest.Address+16, 3))
+
+	// Reading an unknown key.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10], m.Registers[11], m.Registers[12] = math.MaxUint64, pvmtest.Address, 2, pvmtest.Address+16, 0, 3
+	_, exited = HandleRead(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, NONE, m.Registers[7])
+
+	// Writing the output to inaccessible memory panics.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10], m.Registers[11], m.Registers[12] = math.MaxUint64, pvmtest.Address, 3, 0x100, 0, 3
+	reason, exited := HandleRead(m, serviceId, &services)
+	require.True(t, exited)
+	require.Equal(t, pvm.Panic, reason)
+
+	// Removing the value responds with the previous length.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10] = pvmtest.Address, 3, 0, 0
+	_, exited = HandleWrite(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, uint64(5), m.Registers[7])
+	require.Empty(t, account.StorageItems)
+}
+
+func TestInfo(t *testing.T) {
+	const serviceId service.ServiceId = 1
+
+	codeHash := common.Hash{1}
+	var services service.Services
+	services.Save(serviceId, &service.ServiceAccount{
+		CodeHash:      codeHash,
+		StorageItems:  map[common.Hash]common.Blob{{2}: {1, 2, 3}},
+		Balance:       1000,
+		AccumulateGas: 10,
+		OnTransferGas: 20,
+	})
+
+	m := pvmtest.Machine(100)
+	m.Registers[7], m.Registers[8] = math.MaxUint64, pvmtest.Address
+	_, exited := HandleInfo(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, OK, m.Registers[7])
+
+	// E(t_c, E8(t_b, t_t, t_g, t_m, t_o), E4(t_i)), where t_t = B_S + B_I + B_L·(32 + 3) = 145.
+	expected := append(codeHash[:], codec.EncodeUint(1000, 8)...)
+	expected = append(expected, codec.EncodeUint(145, 8)...)
+	expected = append(expected, codec.EncodeUint(10, 8)...)
+	expected = append(expected, codec.EncodeUint(20, 8)...)
+	expected = append(expected, codec.EncodeUint(35, 8)...)
+	expected = append(expected, codec.EncodeUint(1, 4)...)
+	require.Equal(t, expected, m.Memory.Get(pvmtest.Address, uint32(len(expected))))
+
+	// An unknown service responds NONE.
+	m.Registers[7] = 2
+	_, exited = HandleInfo(m, serviceId, &services)
+	require.False(t, exited)
+	require.Equal(t, NONE, m.Registers[7])
+}
@@ -0,0 +1,82 @@
+// Package hostcall implements the host calls available to services running on the PVM, Gray Paper Appendix B.
+package hostcall
+
+import (
+	"math"
+
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// Host call identifiers, Gray Paper Appendix B
+const (
+	Gas              uint64 = 0
+	Lookup           uint64 = 1
+	Read             uint64 = 2
+	Write            uint64 = 3
+	Info             uint64 = 4
+	Bless            uint64 = 5
+	Assign           uint64 = 6
+	Designate        uint64 = 7
+	Checkpoint       uint64 = 8
+	New              uint64 = 9
+	Upgrade          uint64 = 10
+	Transfer         uint64 = 11
+	Eject            uint64 = 12
+	Query            uint64 = 13
+	Solicit          uint64 = 14
+	Forget           uint64 = 15
+	Yield            uint64 = 16
+	HistoricalLookup uint64 = 17
+	Fetch            uint64 = 18
+	Export           uint64 = 19
+	Machine          uint64 = 20
+	Peek             uint64 = 21
+	Poke             uint64 = 22
+	Zero             uint64 = 23
+	Void             uint64 = 24
+	Invoke           uint64 = 25
+	Expunge          uint64 = 26
+	Provide          uint64 = 27
+)
+
+// Host call result codes, Gray Paper (B.1)
+const (
+	OK   uint64 = 0                  // The return value indicating general success.
+	NONE uint64 = math.MaxUint64     // The item does not exist.
+	WHAT uint64 = math.MaxUint64 - 1 // Name unknown.
+	OOB  uint64 = math.MaxUint64 - 2 // The inner PVM memory index provided for reading/writing is not accessible.
+	WHO  uint64 = math.MaxUint64 - 3 // Index unknown.
+	FULL uint64 = math.MaxUint64 - 4 // Storage full.
+	CORE uint64 = math.MaxUint64 - 5 // Core index unknown.
+	CASH uint64 = math.MaxUint64 - 6 // Insufficient funds.
+	LOW  uint64 = math.MaxUint64 - 7 // Gas limit too low.
+	HUH  uint64 = math.MaxUint64 - 8 // The item is already solicited or cannot be forgotten.
+)
+
+// The results of running an integrated PVM with the invoke host call, Gray Paper (B.1)
+const (
+	HALT  uint64 = 0 // The inner PVM halted.
+	PANIC uint64 = 1 // The inner PVM panicked.
+	FAULT uint64 = 2 // The inner PVM page faulted, with the faulting address in ω8.
+	HOST  uint64 = 3 // The inner PVM made a host call, with the host call identifier in ω8.
+	OOG   uint64 = 4 // The inner PVM ran out of gas.
+)
+
+// GasCost is the gas charged for every host call.
+const GasCost pvm.Gas = 10
+
+// Continue lets the machine continue after the host call.
+func Continue() (pvm.ExitReason, bool) {
+	return 0, false
+}
+
+// Exit stops the machine with the exit reason, e.g. panics when the host call accesses inaccessible memory.
+func Exit(reason pvm.ExitReason) (pvm.ExitReason, bool) {
+	return reason, true
+}
+
+// Unknown handles host calls not available in the invocation, setting ω7 to WHAT.
+func Unknown(m *pvm.Machine) (pvm.ExitReason, bool) {
+	m.Registers[7] = WHAT
+	return Continue()
+}
@@ -7,5 +7,6 @@ const (
 	TicketSubmissionDeadline = 500 // Y
 	GuarantorRotationPeriod  = 10  // R
 
-	MaxLookupAnchorAge TimeSlot = 14400 // L: The maximum age in timeslots of the lookup anchor.
+	MaxLookupAnchorAge    TimeSlot = 14400 // L: The maximum age in timeslots of the lookup anchor.
+	PreimageExpungePeriod TimeSlot = 19200 // D: The period in timeslots after which an unreferenced preimage may be expunged.
 )
@@ -7,5 +7,6 @@ const (
 	TicketSubmissionDeadline = 10
 	GuarantorRotationPeriod  = 4 // R
 
-	MaxLookupAnchorAge TimeSlot = 14400 // L: The maximum age in timeslots of the lookup anchor.
+	MaxLookupAnchorAge    TimeSlot = 14400 // L: The maximum age in timeslots of the lookup anchor.
+	PreimageExpungePeriod TimeSlot = 32    // D: The period in timeslots after which an unreferenced preimage may be expunged.
 )
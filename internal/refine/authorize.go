@@ -0,0 +1,74 @@
+package refine
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/work"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// The entry point of the is-authorized code.
+const isAuthorizedEntryPoint = 0
+
+// IsAuthorized runs the authorizer code of the package for the core, Ψ_I defined in the Gray Paper (B.3).
+// It returns the authorizer output, or the reason the package is not authorized, with the gas used.
+// The authorizer code is looked up from the preimages of the service p_h at the lookup anchor time slot.
+// p_c = ∅ ⇒ BAD, |p_c| > W_A ⇒ BIG, otherwise (g, r, ∅) = Ψ_M(p_c, 0, G_I, E(p, E2(c)), F, ∅)
+// where p_c = Λ(δ[p_h], (p_x)_t, p_u)
+func IsAuthorized(
+	services *service.Services, // δ
+	p *workpackage.Package, // p
+	coreIndex uint32, // c
+) (*workreport.ExecResult, service.Gas, error) {
+	if coreIndex >= common.NumOfCores {
+		return nil, 0, errors.WithMessagef(ErrInvalidCoreIndex, "core index %d must be less than %d", coreIndex, common.NumOfCores)
+	}
+	if p.RefinementContext == nil {
+		return nil, 0, errors.WithMessage(work.ErrInvalidRefinementContext, "work package has no refinement context")
+	}
+
+	var code []byte
+	if account, ok := services.Get(p.ServiceIndex); ok {
+		code = account.LookupPreimage(p.AuthCodeHash, p.RefinementContext.LookupAnchorTimeSlot)
+	}
+	if code == nil {
+		return &workreport.ExecResult{Error: workreport.ServiceUnavailable}, 0, nil
+	}
+	if len(code) > service.MaxAuthorizerCodeSize {
+		return &workreport.ExecResult{Error: workreport.CodeTooBig}, 0, nil
+	}
+
+	arguments, err := codec.Encode(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	arguments = append(arguments, codec.EncodeUint(uint64(coreIndex), 2)...)
+
+	gasUsed, output, reason := pvm.Invoke(code, isAuthorizedEntryPoint, service.WorkPackageAuthorizeGasLimit, arguments, handleIsAuthorizedHostCall)
+
+	switch reason {
+	case pvm.Halt:
+		return &workreport.ExecResult{Output: output}, service.Gas(gasUsed), nil
+	case pvm.OutOfGas:
+		return &workreport.ExecResult{Error: workreport.OutOfGas}, service.Gas(gasUsed), nil
+	default:
+		return &workreport.ExecResult{Error: workreport.Panic}, service.Gas(gasUsed), nil
+	}
+}
+
+// The host calls of Ψ_I, where only gas is available.
+func handleIsAuthorizedHostCall(id uint64, m *pvm.Machine) (pvm.ExitReason, bool) {
+	if !m.ConsumeGas(hostcall.GasCost) {
+		return hostcall.Exit(pvm.OutOfGas)
+	}
+
+	if id == hostcall.Gas {
+		return hostcall.HandleGas(m)
+	}
+	return hostcall.Unknown(m)
+}
@@ -0,0 +1,72 @@
+package refine
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/service"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	output := []byte("authorized")
+
+	// Output the read-only data µ[2^16...+|output|].
+	code := standardProgram(output, pvmtest.LoadImm(7, readOnlyAddress), pvmtest.LoadImm(8, uint32(len(output))), pvmtest.Ecalli(hostcall.Gas), pvmtest.LoadImm(7, readOnlyAddress), pvmtest.Halt)
+
+	newPackage := func(code []byte) *workpackage.Package {
+		p := newTestPackage(code, 0)
+		p.ServiceIndex = testServiceId
+		p.AuthCodeHash = blake2b.Sum256(code)
+		p.AuthToken = []byte("token")
+		return p
+	}
+
+	t.Run("authorized", func(t *testing.T) {
+		p := newPackage(code)
+
+		result, gasUsed, err := IsAuthorized(newTestServices(code, testTimeSlot), p, 0)
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Output: output}, result)
+		require.NotZero(t, gasUsed)
+		require.Less(t, gasUsed, service.Gas(service.WorkPackageAuthorizeGasLimit))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		code := standardProgram(nil, pvmtest.Trap)
+		p := newPackage(code)
+
+		result, _, err := IsAuthorized(newTestServices(code, testTimeSlot), p, 0)
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.Panic}, result)
+	})
+
+	t.Run("authorizer code unavailable", func(t *testing.T) {
+		p := newPackage(code)
+		p.AuthCodeHash = common.Hash{1}
+
+		result, gasUsed, err := IsAuthorized(newTestServices(code, testTimeSlot), p, 0)
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.ServiceUnavailable}, result)
+		require.Zero(t, gasUsed)
+	})
+
+	t.Run("authorizer code too big", func(t *testing.T) {
+		code := make([]byte, service.MaxAuthorizerCodeSize+1)
+		p := newPackage(code)
+
+		result, _, err := IsAuthorized(newTestServices(code, testTimeSlot), p, 0)
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.CodeTooBig}, result)
+	})
+
+	t.Run("invalid core index", func(t *testing.T) {
+		_, _, err := IsAuthorized(newTestServices(code, testTimeSlot), newPackage(code), common.NumOfCores)
+		require.ErrorIs(t, err, ErrInvalidCoreIndex)
+	})
+}
@@ -0,0 +1,9 @@
+package refine
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidImportSegments = errors.New("import segments do not match the work items")
+	ErrInvalidExtrinsics     = errors.New("extrinsic data do not match the work items")
+	ErrInvalidCoreIndex      = errors.New("invalid core index")
+)
@@ -0,0 +1,302 @@
+package refine
+
+import (
+	"math"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// The size of the gas and registers read and written by invoke, E8(g) ⌢ E8(w).
+const invokeStateSize = 8 + 8*pvm.NumOfRegisters
+
+// hostCalls dispatches the host calls of Ψ_R over the refine context (m, e), Gray Paper (B.5).
+type hostCalls struct {
+	serviceId        service.ServiceId    // s
+	services         *service.Services    // δ
+	timeSlot         jamtime.TimeSlot     // (p_x)_t: The lookup anchor time slot.
+	itemIndex        int                  // i
+	pkg              *workpackage.Package // p
+	encodedPackage   []byte               // E(p)
+	authorizerOutput []byte               // o
+	imports          [][]workreport.Segment
+	extrinsics       [][][]byte
+	exportOffset     uint // ς
+
+	machines map[uint64]*integratedMachine // m
+	exports  []workreport.Segment          // e
+}
+
+func (h *hostCalls) handle(id uint64, m *pvm.Machine) (pvm.ExitReason, bool) {
+	if !m.ConsumeGas(hostcall.GasCost) {
+		return hostcall.Exit(pvm.OutOfGas)
+	}
+
+	switch id {
+	case hostcall.Gas:
+		return hostcall.HandleGas(m)
+	case hostcall.HistoricalLookup:
+		return hostcall.HandleHistoricalLookup(m, h.serviceId, h.services, h.timeSlot)
+	case hostcall.Fetch:
+		return h.fetch(m)
+	case hostcall.Export:
+		return h.export(m)
+	case hostcall.Machine:
+		return h.machine(m)
+	case hostcall.Peek:
+		return h.peek(m)
+	case hostcall.Poke:
+		return h.poke(m)
+	case hostcall.Zero:
+		return h.zero(m)
+	case hostcall.Void:
+		return h.void(m)
+	case hostcall.Invoke:
+		return h.invoke(m)
+	case hostcall.Expunge:
+		return h.expunge(m)
+	}
+
+	return hostcall.Unknown(m)
+}
+
+// fetch writes v[ω8...+ω9] at µ[ω7], where v is selected by ω10:
+// 0: E(p), 1: o, 2: the payload of the work item ω11, 3: x̄[ω11][ω12], 4: x̄[i][ω11], 5: i̅[ω11][ω12], 6: i̅[i][ω11].
+func (h *hostCalls) fetch(m *pvm.Machine) (pvm.ExitReason, bool) {
+	var value []byte
+	found := true
+
+	switch a, b := m.Registers[11], m.Registers[12]; m.Registers[10] {
+	case 0:
+		value = h.encodedPackage
+	case 1:
+		value = h.authorizerOutput
+	case 2:
+		if found = a < uint64(len(h.pkg.WorkItems)); found {
+			value = h.pkg.WorkItems[a].Payload
+		}
+	case 3:
+		if found = a < uint64(len(h.extrinsics)) && b < uint64(len(h.extrinsics[a])); found {
+			value = h.extrinsics[a][b]
+		}
+	case 4:
+		if found = a < uint64(len(h.extrinsics[h.itemIndex])); found {
+			value = h.extrinsics[h.itemIndex][a]
+		}
+	case 5:
+		if found = a < uint64(len(h.imports)) && b < uint64(len(h.imports[a])); found {
+			value = h.imports[a][b][:]
+		}
+	case 6:
+		if found = a < uint64(len(h.imports[h.itemIndex])); found {
+			value = h.imports[h.itemIndex][a][:]
+		}
+	default:
+		found = false
+	}
+
+	return hostcall.WriteValue(m, value, found, m.Registers[7], m.Registers[8], m.Registers[9])
+}
+
+// export appends the segment P_WG(µ[ω7...+min(ω8, WG)]) to the exports e, and sets ω7 to its index ς + |e|.
+func (h *hostCalls) export(m *pvm.Machine) (pvm.ExitReason, bool) {
+	data, err := m.ReadMemory(m.Registers[7], min(m.Registers[8], workreport.SegmentSize))
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	index := uint64(h.exportOffset) + uint64(len(h.exports))
+	if index >= workpackage.MaxExportSegments {
+		m.Registers[7] = hostcall.FULL
+		return hostcall.Continue()
+	}
+
+	var segment workreport.Segment
+	copy(segment[:], data)
+	h.exports = append(h.exports, segment)
+
+	m.Registers[7] = index
+	return hostcall.Continue()
+}
+
+// machine creates an integrated PVM running the program µ[ω7...+ω8] from the pc ω9, and sets ω7 to its id n = min(N \ K(m)).
+func (h *hostCalls) machine(m *pvm.Machine) (pvm.ExitReason, bool) {
+	blob, err := m.ReadMemory(m.Registers[7], m.Registers[8])
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+
+	program, err := pvm.ParseProgram(blob)
+	if err != nil {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	var n uint64
+	for h.machines[n] != nil {
+		n++
+	}
+	h.machines[n] = &integratedMachine{program: program, memory: pvm.NewMemory(), pc: m.Registers[9]}
+
+	m.Registers[7] = n
+	return hostcall.Continue()
+}
+
+// peek copies ω10 octets from the address ω9 of the integrated PVM ω7 to µ[ω8].
+func (h *hostCalls) peek(m *pvm.Machine) (pvm.ExitReason, bool) {
+	n, o, s, z := m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10]
+
+	if !accessible(m.Memory, o, z, pvm.Writable) {
+		return hostcall.Exit(pvm.Panic)
+	}
+	inner, ok := h.machines[n]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	if !accessible(inner.memory, s, z, pvm.ReadOnly) {
+		m.Registers[7] = hostcall.OOB
+		return hostcall.Continue()
+	}
+
+	m.Memory.Set(uint32(o), inner.memory.Get(uint32(s), uint32(z)))
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// poke copies ω10 octets from µ[ω8] to the address ω9 of the integrated PVM ω7.
+func (h *hostCalls) poke(m *pvm.Machine) (pvm.ExitReason, bool) {
+	n, s, o, z := m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10]
+
+	data, err := m.ReadMemory(s, z)
+	if err != nil {
+		return hostcall.Exit(pvm.Panic)
+	}
+	inner, ok := h.machines[n]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	if !accessible(inner.memory, o, z, pvm.Writable) {
+		m.Registers[7] = hostcall.OOB
+		return hostcall.Continue()
+	}
+
+	inner.memory.Set(uint32(o), data)
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// zero zeroes the ω9 pages from the page ω8 of the integrated PVM ω7, making them writable.
+func (h *hostCalls) zero(m *pvm.Machine) (pvm.ExitReason, bool) {
+	n, p, c := m.Registers[7], m.Registers[8], m.Registers[9]
+
+	inner, ok := h.machines[n]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	// The pages below 2^16 are reserved, p < 16 ∨ p + c ≥ 2^32/Z_P
+	if p < 16 || p+c >= 1<<32/pvm.PageSize || p+c < p {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	setPages(inner.memory, p, c, pvm.Writable)
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// void zeroes the ω9 pages from the page ω8 of the integrated PVM ω7, making them inaccessible.
+func (h *hostCalls) void(m *pvm.Machine) (pvm.ExitReason, bool) {
+	n, p, c := m.Registers[7], m.Registers[8], m.Registers[9]
+
+	inner, ok := h.machines[n]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	// p + c ≥ 2^32/Z_P, or any of the pages is already inaccessible.
+	if p+c >= 1<<32/pvm.PageSize || p+c < p || !accessible(inner.memory, p*pvm.PageSize, c*pvm.PageSize, pvm.ReadOnly) {
+		m.Registers[7] = hostcall.HUH
+		return hostcall.Continue()
+	}
+
+	setPages(inner.memory, p, c, pvm.Inaccessible)
+	m.Registers[7] = hostcall.OK
+	return hostcall.Continue()
+}
+
+// invoke runs the integrated PVM ω7 with the gas and registers E8(g) ⌢ E8(w) = µ[ω8...+112], until it exits.
+// The remaining gas and registers are written back, ω7 is set to the exit reason and ω8 to the host call id or faulting address.
+func (h *hostCalls) invoke(m *pvm.Machine) (pvm.ExitReason, bool) {
+	address := m.Registers[8]
+
+	encoded, err := m.ReadMemory(address, invokeStateSize)
+	if err != nil || !accessible(m.Memory, address, invokeStateSize, pvm.Writable) {
+		return hostcall.Exit(pvm.Panic)
+	}
+	inner, ok := h.machines[m.Registers[7]]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+
+	d := codec.NewDecoder(encoded)
+	gas, _ := d.DecodeUint(8)
+	var registers pvm.Registers
+	for i := range registers {
+		registers[i], _ = d.DecodeUint(8)
+	}
+	if inner.pc > math.MaxUint32 {
+		m.Registers[7] = hostcall.PANIC
+		return hostcall.Continue()
+	}
+
+	vm := pvm.NewMachine(inner.program, uint32(inner.pc), pvm.Gas(min(gas, math.MaxInt64)), registers, inner.memory)
+	reason, value := vm.Run()
+
+	result, detail := hostcall.HALT, m.Registers[8]
+	switch reason {
+	case pvm.Panic:
+		result = hostcall.PANIC
+	case pvm.OutOfGas:
+		result = hostcall.OOG
+	case pvm.PageFault:
+		result, detail = hostcall.FAULT, value
+	case pvm.HostCall:
+		// The host call is left to the outer PVM, which resumes the inner PVM after it.
+		vm.ResumeAfterHostCall()
+		result, detail = hostcall.HOST, value
+	}
+	inner.pc = uint64(vm.PC)
+
+	state := codec.EncodeUint(uint64(max(vm.Gas, 0)), 8)
+	for _, register := range vm.Registers {
+		state = append(state, codec.EncodeUint(register, 8)...)
+	}
+	m.Memory.Set(uint32(address), state)
+
+	m.Registers[7], m.Registers[8] = result, detail
+	return hostcall.Continue()
+}
+
+// expunge removes the integrated PVM ω7, and sets ω7 to its pc.
+func (h *hostCalls) expunge(m *pvm.Machine) (pvm.ExitReason, bool) {
+	n := m.Registers[7]
+
+	inner, ok := h.machines[n]
+	if !ok {
+		m.Registers[7] = hostcall.WHO
+		return hostcall.Continue()
+	}
+	delete(h.machines, n)
+
+	m.Registers[7] = inner.pc
+	return hostcall.Continue()
+}
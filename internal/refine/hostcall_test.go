@@ -0,0 +1,119 @@
+package refine
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHostCalls() *hostCalls {
+	return &hostCalls{
+		pkg:        &workpackage.Package{},
+		imports:    [][]workreport.Segment{nil},
+		extrinsics: [][][]byte{nil},
+		machines:   make(map[uint64]*integratedMachine),
+	}
+}
+
+func TestIntegratedMachine(t *testing.T) {
+	h := newTestHostCalls()
+	m := pvmtest.Machine(1000)
+
+	// The inner program makes the host call 5 with ω7 = 42, then traps.
+	inner := pvmtest.Program(pvmtest.LoadImm(7, 42), pvmtest.Ecalli(5), pvmtest.Trap)
+	m.Memory.Set(pvmtest.Address, inner)
+
+	m.Registers[7], m.Registers[8], m.Registers[9] = pvmtest.Address, uint64(len(inner)), 0
+	_, exited := h.handle(hostcall.Machine, m)
+	require.False(t, exited)
+	require.Equal(t, uint64(0), m.Registers[7])
+
+	// Poking inaccessible inner memory is out of bounds, until the page is zeroed.
+	poke := func() uint64 {
+		m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10] = 0, pvmtest.Address, 16*pvm.PageSize, 4
+		h.handle(hostcall.Poke, m)
+		return m.Registers[7]
+	}
+	require.Equal(t, hostcall.OOB, poke())
+
+	m.Registers[7], m.Registers[8], m.Registers[9] = 0, 15, 1
+	h.handle(hostcall.Zero, m)
+	require.Equal(t, hostcall.HUH, m.Registers[7])
+
+	m.Registers[7], m.Registers[8], m.Registers[9] = 0, 16, 1
+	h.handle(hostcall.Zero, m)
+	require.Equal(t, hostcall.OK, m.Registers[7])
+	require.Equal(t, hostcall.OK, poke())
+
+	// Peek the poked octets back at pvmtest.Address + 8.
+	m.Registers[7], m.Registers[8], m.Registers[9], m.Registers[10] = 0, pvmtest.Address+8, 16*pvm.PageSize, 4
+	h.handle(hostcall.Peek, m)
+	require.Equal(t, hostcall.OK, m.Registers[7])
+	require.Equal(t, inner[:4], m.Memory.Get(pvmtest.Address+8, 4))
+
+	// Invoke runs until the host call, and writes back the gas and registers.
+	const state = pvmtest.Address + pvm.PageSize/2
+	m.Memory.Set(state, codec.EncodeUint(100, 8))
+	invoke := func() uint64 {
+		m.Registers[7], m.Registers[8] = 0, state
+		h.handle(hostcall.Invoke, m)
+		return m.Registers[7]
+	}
+	require.Equal(t, hostcall.HOST, invoke())
+	require.Equal(t, uint64(5), m.Registers[8])
+	require.Equal(t, codec.EncodeUint(98, 8), m.Memory.Get(state, 8))
+	require.Equal(t, codec.EncodeUint(42, 8), m.Memory.Get(state+8+7*8, 8))
+
+	// The next invocation resumes after the host call.
+	require.Equal(t, hostcall.PANIC, invoke())
+
+	// Void makes the page inaccessible, and fails on inaccessible pages.
+	m.Registers[7], m.Registers[8], m.Registers[9] = 0, 16, 1
+	h.handle(hostcall.Void, m)
+	require.Equal(t, hostcall.OK, m.Registers[7])
+	require.Equal(t, hostcall.OOB, poke())
+
+	m.Registers[7], m.Registers[8], m.Registers[9] = 0, 16, 1
+	h.handle(hostcall.Void, m)
+	require.Equal(t, hostcall.HUH, m.Registers[7])
+
+	// Expunge responds with the pc, after which the machine is unknown.
+	m.Registers[7] = 0
+	h.handle(hostcall.Expunge, m)
+	require.Equal(t, uint64(len(pvmtest.LoadImm(7, 42))+len(pvmtest.Ecalli(5))), m.Registers[7])
+
+	m.Registers[7] = 0
+	h.handle(hostcall.Expunge, m)
+	require.Equal(t, hostcall.WHO, m.Registers[7])
+
+	// Invoking with the state in read-only memory panics.
+	m.Memory.SetAccess(pvmtest.Address, pvm.PageSize, pvm.ReadOnly)
+	m.Registers[7], m.Registers[8] = 0, state
+	reason, exited := h.handle(hostcall.Invoke, m)
+	require.True(t, exited)
+	require.Equal(t, pvm.Panic, reason)
+}
+
+func TestExport(t *testing.T) {
+	h := newTestHostCalls()
+	h.exportOffset = workpackage.MaxExportSegments - 1
+	m := pvmtest.Machine(1000)
+	m.Memory.Set(pvmtest.Address, []byte("segment"))
+
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 7
+	h.handle(hostcall.Export, m)
+	require.Equal(t, uint64(workpackage.MaxExportSegments-1), m.Registers[7])
+	require.Equal(t, []byte("segment"), h.exports[0][:7])
+
+	// No more segments can be exported by the package.
+	m.Registers[7], m.Registers[8] = pvmtest.Address, 7
+	h.handle(hostcall.Export, m)
+	require.Equal(t, hostcall.FULL, m.Registers[7])
+	require.Len(t, h.exports, 1)
+}
@@ -0,0 +1,146 @@
+// Package refine implements the in-core computation of work packages,
+// the Is-Authorized invocation Ψ_I and the Refine invocation Ψ_R defined in the Gray Paper (B.3), (B.5).
+package refine
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/work"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/pvm"
+	"golang.org/x/crypto/blake2b"
+)
+
+// The entry point of the refine code.
+const refineEntryPoint = 0
+
+// Refine runs the Refine code of every work item of the package, and returns their work results and the segments they export.
+// The import segments i̅ and the extrinsic data x̄ are given per work item, in the order of the item's references to them.
+// Gray Paper (14.11)
+// (r, e) = I(p, j) ≡ (r, e) if |e| = w_e, (r, [G0, G0, ...]...w_e) if r ∉ Y, (⊚, [G0, G0, ...]...w_e) otherwise
+// where (r, e, u) = Ψ_R(j, p, o, i̅, ς), ς = Σ_{k<j} p_w[k]_e
+func Refine(
+	services *service.Services, // δ
+	p *workpackage.Package, // p
+	authorizerOutput []byte, // o
+	imports [][]workreport.Segment, // i̅
+	extrinsics [][][]byte, // x̄
+) ([]*workreport.WorkResult, []workreport.Segment, error) {
+	if p.RefinementContext == nil {
+		return nil, nil, errors.WithMessage(work.ErrInvalidRefinementContext, "work package has no refinement context")
+	}
+	if len(imports) != len(p.WorkItems) {
+		return nil, nil, errors.WithMessagef(ErrInvalidImportSegments, "%d import segment lists for %d work items", len(imports), len(p.WorkItems))
+	}
+	if len(extrinsics) != len(p.WorkItems) {
+		return nil, nil, errors.WithMessagef(ErrInvalidExtrinsics, "%d extrinsic lists for %d work items", len(extrinsics), len(p.WorkItems))
+	}
+	for i, item := range p.WorkItems {
+		if len(imports[i]) != len(item.ImportSegments) {
+			return nil, nil, errors.WithMessagef(ErrInvalidImportSegments, "%d import segments for work item %d, expected %d", len(imports[i]), i, len(item.ImportSegments))
+		}
+		if len(extrinsics[i]) != len(item.Extrinsics) {
+			return nil, nil, errors.WithMessagef(ErrInvalidExtrinsics, "%d extrinsics for work item %d, expected %d", len(extrinsics[i]), i, len(item.Extrinsics))
+		}
+		for j, extrinsic := range item.Extrinsics {
+			if uint64(len(extrinsics[i][j])) != uint64(extrinsic.Length) || blake2b.Sum256(extrinsics[i][j]) != extrinsic.Hash {
+				return nil, nil, errors.WithMessagef(ErrInvalidExtrinsics, "extrinsic %d of work item %d does not match hash %s and length %d", j, i, extrinsic.Hash.ToHex(), extrinsic.Length)
+			}
+		}
+	}
+
+	encodedPackage, err := codec.Encode(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]*workreport.WorkResult, len(p.WorkItems))
+	var exports []workreport.Segment
+	for j, item := range p.WorkItems {
+		h := &hostCalls{
+			serviceId:        item.ServiceId,
+			services:         services,
+			timeSlot:         p.RefinementContext.LookupAnchorTimeSlot,
+			itemIndex:        j,
+			pkg:              p,
+			encodedPackage:   encodedPackage,
+			authorizerOutput: authorizerOutput,
+			imports:          imports,
+			extrinsics:       extrinsics,
+			exportOffset:     uint(len(exports)),
+			machines:         make(map[uint64]*integratedMachine),
+		}
+		result, itemExports, gasUsed := invoke(h, item.RefineGasLimit)
+
+		if len(itemExports) != int(item.ExportCount) {
+			itemExports = make([]workreport.Segment, item.ExportCount)
+			if result.Output != nil {
+				result = &workreport.ExecResult{Error: workreport.ReportInvalid}
+			}
+		}
+		exports = append(exports, itemExports...)
+
+		// (14.8) C(w, r) ≡ (s: w_s, c: w_c, l: H(w_y), g: w_a, o: r)
+		results[j] = &workreport.WorkResult{
+			ServiceId:       item.ServiceId,
+			ServiceCodeHash: item.CodeHash,
+			PayloadHash:     blake2b.Sum256(item.Payload),
+			Gas:             item.AccumulateGasLimit,
+			ExecResult:      result,
+			RefineLoad: workreport.RefineLoad{
+				GasUsed:        gasUsed,
+				Imports:        uint32(len(item.ImportSegments)),
+				ExtrinsicCount: uint32(len(item.Extrinsics)),
+				ExtrinsicSize:  uint32(item.TotalExtrinsicSize()),
+				Exports:        uint32(item.ExportCount),
+			},
+		}
+	}
+
+	return results, exports, nil
+}
+
+// invoke runs the refine code of the work item, Ψ_R defined in the Gray Paper (B.5).
+// Λ(δ[w_s], (p_x)_t, w_c) = ∅ ⇒ (BAD, [], 0), |Λ(δ[w_s], (p_x)_t, w_c)| > W_C ⇒ (BIG, [], 0)
+// otherwise (g, r, (m, e)) = Ψ_M(Λ(δ[w_s], (p_x)_t, w_c), 0, w_g, a, F, (∅, []))
+// where a = E4(w_s) ⌢ E(↕w_y) ⌢ H(p) ⌢ E(p_x) ⌢ p_u
+func invoke(h *hostCalls, gas service.Gas) (*workreport.ExecResult, []workreport.Segment, service.Gas) {
+	item := h.pkg.WorkItems[h.itemIndex]
+
+	var code []byte
+	if account, ok := h.services.Get(item.ServiceId); ok {
+		code = account.LookupPreimage(item.CodeHash, h.timeSlot)
+	}
+	if code == nil {
+		return &workreport.ExecResult{Error: workreport.ServiceUnavailable}, nil, 0
+	}
+	if len(code) > service.MaxServiceCodeSize {
+		return &workreport.ExecResult{Error: workreport.CodeTooBig}, nil, 0
+	}
+
+	encodedContext, err := codec.Encode(h.pkg.RefinementContext)
+	if err != nil {
+		return &workreport.ExecResult{Error: workreport.Panic}, nil, 0
+	}
+	packageHash := blake2b.Sum256(h.encodedPackage)
+
+	arguments := codec.EncodeUint(uint64(item.ServiceId), 4)
+	arguments = append(arguments, codec.EncodeNatural(uint64(len(item.Payload)))...)
+	arguments = append(arguments, item.Payload...)
+	arguments = append(arguments, packageHash[:]...)
+	arguments = append(arguments, encodedContext...)
+	arguments = append(arguments, h.pkg.AuthCodeHash[:]...)
+
+	gasUsed, output, reason := pvm.Invoke(code, refineEntryPoint, pvm.Gas(gas), arguments, h.handle)
+
+	switch reason {
+	case pvm.Halt:
+		return &workreport.ExecResult{Output: output}, h.exports, service.Gas(gasUsed)
+	case pvm.OutOfGas:
+		return &workreport.ExecResult{Error: workreport.OutOfGas}, nil, service.Gas(gasUsed)
+	default:
+		return &workreport.ExecResult{Error: workreport.Panic}, nil, service.Gas(gasUsed)
+	}
+}
@@ -0,0 +1,159 @@
+package refine
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/hostcall"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/work"
+	workitem "github.com/shunsukew/gojam/internal/work/item"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/pvm/pvmtest"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	testServiceId     service.ServiceId = 1000
+	testTimeSlot      jamtime.TimeSlot  = 10
+	readOnlyAddress                     = 1 << 16 // The read-only data zone of a standard program.
+	readWriteAddress                    = 3 << 16 // The read-write data zone of a standard program with read-only data.
+	readWriteDataSize                   = 1 << 8
+)
+
+// standardProgram builds a standard program blob with the read-only data and a zeroed read-write data zone,
+// of which refine entry point runs the instructions.
+func standardProgram(readOnlyData []byte, instructions ...[]byte) []byte {
+	return pvmtest.StandardProgram(refineEntryPoint, readOnlyData, readWriteDataSize, instructions...)
+}
+
+func newTestServices(code []byte, availableFrom jamtime.TimeSlot) *service.Services {
+	codeHash := blake2b.Sum256(code)
+
+	services := &service.Services{}
+	services.Save(testServiceId, &service.ServiceAccount{
+		CodeHash:  codeHash,
+		Preimages: map[common.Hash]common.Blob{codeHash: code},
+		PreimageMeta: map[service.PreimageMeta]service.PreimageAvailabilityHistory{
+			{Hash: codeHash, BlobLength: common.BlobLength(len(code))}: {availableFrom},
+		},
+	})
+	return services
+}
+
+func newTestPackage(code []byte, exportCount uint16, extrinsics ...[]byte) *workpackage.Package {
+	item := &workitem.Item{
+		ServiceId:          testServiceId,
+		CodeHash:           blake2b.Sum256(code),
+		Payload:            []byte("payload"),
+		RefineGasLimit:     1000,
+		AccumulateGasLimit: 100,
+		ExportCount:        exportCount,
+	}
+	for _, extrinsic := range extrinsics {
+		item.Extrinsics = append(item.Extrinsics, workitem.ExtrinsicRef{Hash: blake2b.Sum256(extrinsic), Length: uint32(len(extrinsic))})
+	}
+
+	return &workpackage.Package{
+		RefinementContext: &work.RefinementContext{LookupAnchorTimeSlot: testTimeSlot},
+		WorkItems:         []*workitem.Item{item},
+	}
+}
+
+func TestRefine(t *testing.T) {
+	readOnlyData := []byte("exported")
+	exportInstructions := [][]byte{pvmtest.LoadImm(7, readOnlyAddress), pvmtest.LoadImm(8, uint32(len(readOnlyData))), pvmtest.Ecalli(hostcall.Export)}
+
+	var exported workreport.Segment
+	copy(exported[:], readOnlyData)
+
+	t.Run("halt exports the segments", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(exportInstructions, pvmtest.Halt)...)
+		p := newTestPackage(code, 1)
+
+		results, exports, err := Refine(newTestServices(code, testTimeSlot), p, nil, [][]workreport.Segment{nil}, [][][]byte{nil})
+		require.NoError(t, err)
+		require.Equal(t, []workreport.Segment{exported}, exports)
+		require.Len(t, results, 1)
+
+		result := results[0]
+		require.Equal(t, &workreport.ExecResult{Output: []byte{}}, result.ExecResult)
+		require.Equal(t, testServiceId, result.ServiceId)
+		require.Equal(t, p.WorkItems[0].CodeHash, result.ServiceCodeHash)
+		require.Equal(t, common.Hash(blake2b.Sum256([]byte("payload"))), result.PayloadHash)
+		require.Equal(t, service.Gas(100), result.Gas)
+		require.Equal(t, uint32(1), result.RefineLoad.Exports)
+		require.NotZero(t, result.RefineLoad.GasUsed)
+	})
+
+	t.Run("export count mismatch is reported invalid", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(exportInstructions, pvmtest.Halt)...)
+		p := newTestPackage(code, 2)
+
+		results, exports, err := Refine(newTestServices(code, testTimeSlot), p, nil, [][]workreport.Segment{nil}, [][][]byte{nil})
+		require.NoError(t, err)
+		require.Equal(t, make([]workreport.Segment, 2), exports)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.ReportInvalid}, results[0].ExecResult)
+	})
+
+	t.Run("panic discards the exports", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(exportInstructions, pvmtest.Trap)...)
+		p := newTestPackage(code, 1)
+
+		results, exports, err := Refine(newTestServices(code, testTimeSlot), p, nil, [][]workreport.Segment{nil}, [][][]byte{nil})
+		require.NoError(t, err)
+		require.Equal(t, make([]workreport.Segment, 1), exports)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.Panic}, results[0].ExecResult)
+	})
+
+	t.Run("out of gas", func(t *testing.T) {
+		code := standardProgram(readOnlyData, append(exportInstructions, pvmtest.Halt)...)
+		p := newTestPackage(code, 0)
+		p.WorkItems[0].RefineGasLimit = 2
+
+		results, _, err := Refine(newTestServices(code, testTimeSlot), p, nil, [][]workreport.Segment{nil}, [][][]byte{nil})
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.OutOfGas}, results[0].ExecResult)
+	})
+
+	t.Run("code unavailable at the lookup anchor", func(t *testing.T) {
+		code := standardProgram(readOnlyData, pvmtest.Halt)
+		p := newTestPackage(code, 0)
+
+		results, _, err := Refine(newTestServices(code, testTimeSlot+1), p, nil, [][]workreport.Segment{nil}, [][][]byte{nil})
+		require.NoError(t, err)
+		require.Equal(t, &workreport.ExecResult{Error: workreport.ServiceUnavailable}, results[0].ExecResult)
+		require.Zero(t, results[0].RefineLoad.GasUsed)
+	})
+
+	t.Run("fetched extrinsic is exported", func(t *testing.T) {
+		extrinsic := []byte("extrinsic")
+		code := standardProgram(readOnlyData,
+			// fetch x̄[i][0] to the read-write data
+			pvmtest.LoadImm(7, readWriteAddress), pvmtest.LoadImm(8, 0), pvmtest.LoadImm(9, readWriteDataSize), pvmtest.LoadImm(10, 4), pvmtest.LoadImm(11, 0), pvmtest.Ecalli(hostcall.Fetch),
+			pvmtest.LoadImm(8, uint32(len(extrinsic))), pvmtest.LoadImm(7, readWriteAddress), pvmtest.Ecalli(hostcall.Export),
+			pvmtest.Halt,
+		)
+		p := newTestPackage(code, 1, extrinsic)
+
+		results, exports, err := Refine(newTestServices(code, testTimeSlot), p, nil, [][]workreport.Segment{nil}, [][][]byte{{extrinsic}})
+		require.NoError(t, err)
+		require.Equal(t, []byte(extrinsic), exports[0][:len(extrinsic)])
+		require.Equal(t, uint32(len(extrinsic)), results[0].RefineLoad.ExtrinsicSize)
+	})
+
+	t.Run("mismatching inputs", func(t *testing.T) {
+		code := standardProgram(readOnlyData, pvmtest.Halt)
+		p := newTestPackage(code, 0, []byte("extrinsic"))
+		services := newTestServices(code, testTimeSlot)
+
+		_, _, err := Refine(services, p, nil, nil, [][][]byte{{[]byte("extrinsic")}})
+		require.ErrorIs(t, err, ErrInvalidImportSegments)
+
+		_, _, err = Refine(services, p, nil, [][]workreport.Segment{nil}, [][][]byte{{[]byte("other")}})
+		require.ErrorIs(t, err, ErrInvalidExtrinsics)
+	})
+}
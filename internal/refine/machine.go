@@ -0,0 +1,35 @@
+package refine
+
+import (
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// (B.4) M ≡ (p ∈ Y, u ∈ M, i ∈ N_R)
+// An integrated PVM, created by the machine host call and run by invoke.
+type integratedMachine struct {
+	program *pvm.Program // p: The program, deblobbed once when the machine is created.
+	memory  *pvm.Memory  // u: The RAM, initially inaccessible.
+	pc      uint64       // i: The instruction counter the next invocation starts from.
+}
+
+// accessible reports whether all pages of memory[address...+length] permit the access.
+func accessible(memory *pvm.Memory, address, length uint64, access pvm.Access) bool {
+	if address+length > 1<<32 || address+length < address {
+		return false
+	}
+	for page := address / pvm.PageSize; page*pvm.PageSize < address+length; page++ {
+		if memory.Access(uint32(page*pvm.PageSize)) < access {
+			return false
+		}
+	}
+	return true
+}
+
+// setPages zeroes the c pages from the page p, and sets their access.
+func setPages(memory *pvm.Memory, p, c uint64, access pvm.Access) {
+	zeros := make([]byte, pvm.PageSize)
+	for page := p; page < p+c; page++ {
+		memory.Set(uint32(page*pvm.PageSize), zeros)
+	}
+	memory.SetAccess(uint32(p*pvm.PageSize), uint32(c*pvm.PageSize), access)
+}
@@ -1,7 +1,10 @@
+//go:build !tiny
+
 package service
 
 const (
 	WorkReportAccumulationGasLimit = 10000000   // G_A: The gas allocated to invoke a work-report’s Accumulation logic.
 	WorkPackageAuthorizeGasLimit   = 50000000   // G_I: The gas allocated to invoke a work-package’s Is-Authorized logic.
 	WorkPackageRefineGasLimit      = 5000000000 // G_R: The gas allocated to invoke a work-package’s Refine logic.
+	TotalAccumulationGasLimit      = 3500000000 // G_T: The total gas allocated across for all Accumulation.
 )
@@ -0,0 +1,10 @@
+//go:build tiny
+
+package service
+
+const (
+	WorkReportAccumulationGasLimit = 10000000
+	WorkPackageAuthorizeGasLimit   = 50000000
+	WorkPackageRefineGasLimit      = 1000000000
+	TotalAccumulationGasLimit      = 20000000
+)
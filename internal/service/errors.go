@@ -0,0 +1,8 @@
+package service
+
+import "github.com/pkg/errors"
+
+var (
+	ErrPreimagesNotSortedUnique = errors.New("preimages not sorted unique")
+	ErrPreimageUnneeded         = errors.New("preimage unneeded")
+)
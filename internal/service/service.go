@@ -1,12 +1,24 @@
 package service
 
 import (
+	"iter"
+	"maps"
+	"slices"
+
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/pkg/common"
 )
 
 const (
 	MaxPreimageAvailabilityHistorySize = 3
+
+	MaxServiceCodeSize    = 4000000 // W_C: The maximum size of service code in octets.
+	MaxAuthorizerCodeSize = 64000   // W_A: The maximum size of is-authorized code in octets.
+	TransferMemoSize      = 128     // W_T: The size of a transfer memo in octets.
+
+	BasicMinimumBalance = 100 // B_S: The basic minimum balance which all services require.
+	ItemMinimumBalance  = 10  // B_I: The additional minimum balance required per item of elective service state.
+	OctetMinimumBalance = 1   // B_L: The additional minimum balance required per octet of elective service state.
 )
 
 type ServiceId uint32 // ℕ_S
@@ -32,6 +44,42 @@ func (s *Services) Save(serviceId ServiceId, account *ServiceAccount) {
 	s.services[serviceId] = account
 }
 
+func (s *Services) Remove(serviceId ServiceId) {
+	delete(s.services, serviceId)
+}
+
+// All iterates over every service account, in no particular order.
+func (s *Services) All() iter.Seq2[ServiceId, *ServiceAccount] {
+	return maps.All(s.services)
+}
+
+// Clone returns a deep copy of the service accounts, so that state transitions can be applied without touching the original.
+func (s *Services) Clone() Services {
+	if s.services == nil {
+		return Services{}
+	}
+
+	cloned := Services{services: make(map[ServiceId]*ServiceAccount, len(s.services))}
+	for serviceId, account := range s.services {
+		cloned.services[serviceId] = account.Clone()
+	}
+	return cloned
+}
+
+// χ ≡ (m ∈ N_S, a ∈ ⟦N_S⟧C, v ∈ N_S, g ∈ D⟨N_S → N_G⟩)
+type PrivilegedServices struct {
+	Manager          ServiceId                    // m: The service able to alter χ.
+	Assigners        [common.NumOfCores]ServiceId // a: The services able to alter φ of each core.
+	Designator       ServiceId                    // v: The service able to alter ι.
+	AlwaysAccumulate map[ServiceId]Gas            // g: The services which always accumulate, with their gas.
+}
+
+func (p *PrivilegedServices) Clone() PrivilegedServices {
+	cloned := *p
+	cloned.AlwaysAccumulate = maps.Clone(p.AlwaysAccumulate)
+	return cloned
+}
+
 type PreimageAvailabilityHistory []jamtime.TimeSlot
 
 // A ≡ (
@@ -55,11 +103,66 @@ type ServiceAccount struct {
 	OnTransferGas Gas                                          // m
 }
 
+// The storage footprint (a_i, a_o) of a service account, Gray Paper (9.8).
+type Footprint struct {
+	NumOfStorageItems  uint32 // a_i: The number of items in storage.
+	SizeOfStorageItems uint64 // a_o: The total number of octets used in storage.
+}
+
 type PreimageMeta struct {
 	Hash       common.Hash
 	BlobLength common.BlobLength
 }
 
+func (s *ServiceAccount) Clone() *ServiceAccount {
+	cloned := *s
+	cloned.StorageItems = maps.Clone(s.StorageItems)
+	cloned.Preimages = maps.Clone(s.Preimages)
+	if s.PreimageMeta != nil {
+		cloned.PreimageMeta = make(map[PreimageMeta]PreimageAvailabilityHistory, len(s.PreimageMeta))
+		for meta, history := range s.PreimageMeta {
+			cloned.PreimageMeta[meta] = slices.Clone(history)
+		}
+	}
+	return &cloned
+}
+
+// Footprint returns the number of items a_i and the number of octets a_o the account requires in state, Gray Paper (9.8)
+// a_i ≡ 2·|a_l| + |a_s|
+// a_o ≡ Σ_{(h, z) ∈ K(a_l)} 81 + z + Σ_{x ∈ V(a_s)} 32 + |x|
+func (s *ServiceAccount) Footprint() Footprint {
+	footprint := Footprint{
+		NumOfStorageItems: uint32(2*len(s.PreimageMeta) + len(s.StorageItems)),
+	}
+
+	for meta := range s.PreimageMeta {
+		footprint.SizeOfStorageItems += 81 + uint64(meta.BlobLength)
+	}
+	for _, value := range s.StorageItems {
+		footprint.SizeOfStorageItems += 32 + uint64(len(value))
+	}
+
+	return footprint
+}
+
+// ThresholdBalance returns the minimum balance a_t the account requires for its footprint, Gray Paper (9.8)
+// a_t ≡ B_S + B_I·a_i + B_L·a_o
+func (s *ServiceAccount) ThresholdBalance() Balance {
+	footprint := s.Footprint()
+	return BasicMinimumBalance +
+		ItemMinimumBalance*Balance(footprint.NumOfStorageItems) +
+		OctetMinimumBalance*Balance(footprint.SizeOfStorageItems)
+}
+
+// Headroom returns how much the balance exceeds the threshold balance, a_b − a_t, or 0 if the account is below its threshold.
+func (s *ServiceAccount) Headroom() Balance {
+	threshold := s.ThresholdBalance()
+	if s.Balance < threshold {
+		return 0
+	}
+	return s.Balance - threshold
+}
+
 // Gray paper (9.4)
 // The code c of a service account is represented by a hash which, if the service is to be functional, must be present within its preimage lookup
 //
@@ -1,23 +1,92 @@
 package service
 
 import (
+	"bytes"
+	"cmp"
+
+	"github.com/pkg/errors"
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
 )
 
+// EP ∈ ⟦(NS, Y)⟧
 type PreimageRequest struct {
 	ServiceId ServiceId
 	Preimage  common.Blob
 }
 
-type Footprint struct {
-	NumOfStorageItems  uint32
-	SizeOfStorageItems uint64
-}
-
+// Update integrates the preimages of EP into the service accounts δ‡, Gray Paper (12.35) - (12.38).
+// Every preimage must have been solicited by its requester in the prior state δ and not been provided yet.
+// Preimages which are no longer solicited in δ‡, since accumulation changed the requester, are skipped.
+// The footprint of the requester after integration is returned for each preimage in the order of EP, nil for the skipped ones.
 func (s *Services) Update(
-	timeSlot jamtime.TimeSlot,
+	prior *Services, // δ
+	timeSlot jamtime.TimeSlot, // τ′
 	preimages []*PreimageRequest,
 ) ([]*Footprint, error) {
-	return nil, nil
+	// (12.35) EP = [i ∈ EP ⍟ i]
+	for i := 1; i < len(preimages); i++ {
+		if comparePreimageRequests(preimages[i-1], preimages[i]) >= 0 {
+			return nil, errors.WithMessagef(ErrPreimagesNotSortedUnique, "preimage %d", i)
+		}
+	}
+
+	// (12.36) ∀(s, p) ∈ EP ∶ Y(δ, s, H(p), |p|)
+	// Every preimage is checked against the prior state before any of them is integrated.
+	metas := make([]PreimageMeta, len(preimages))
+	for i, preimage := range preimages {
+		metas[i] = PreimageMeta{
+			Hash:       blake2b.Sum256(preimage.Preimage),
+			BlobLength: common.BlobLength(len(preimage.Preimage)),
+		}
+
+		if !prior.isPreimageSolicited(preimage.ServiceId, metas[i]) {
+			return nil, errors.WithMessagef(ErrPreimageUnneeded, "service %d, preimage %x", preimage.ServiceId, metas[i].Hash)
+		}
+	}
+
+	// (12.37) P = {(s, p) ∣ (s, p) ∈ EP, Y(δ‡, s, H(p), |p|)}
+	// (12.38) δ′[s]p[H(p)] = p, δ′[s]l[H(p), |p|] = [τ′] for (s, p) ∈ P
+	footprints := make([]*Footprint, len(preimages))
+	for i, preimage := range preimages {
+		if !s.isPreimageSolicited(preimage.ServiceId, metas[i]) {
+			continue
+		}
+
+		account, _ := s.Get(preimage.ServiceId)
+		if account.Preimages == nil {
+			account.Preimages = make(map[common.Hash]common.Blob)
+		}
+		account.Preimages[metas[i].Hash] = preimage.Preimage
+		account.PreimageMeta[metas[i]] = PreimageAvailabilityHistory{timeSlot}
+
+		footprint := account.Footprint()
+		footprints[i] = &footprint
+	}
+
+	return footprints, nil
+}
+
+// (12.36) Y(d, s, h, l) ⇔ h ∉ d[s]p ∧ d[s]l[(h, l)] = []
+func (s *Services) isPreimageSolicited(serviceId ServiceId, meta PreimageMeta) bool {
+	account, ok := s.Get(serviceId)
+	if !ok {
+		return false
+	}
+
+	if _, ok := account.Preimages[meta.Hash]; ok {
+		return false
+	}
+
+	history, ok := account.PreimageMeta[meta]
+	return ok && len(history) == 0
+}
+
+// Preimages are ordered by the requesting service, then by the preimage octets.
+func comparePreimageRequests(a, b *PreimageRequest) int {
+	if c := cmp.Compare(a.ServiceId, b.ServiceId); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.Preimage, b.Preimage)
 }
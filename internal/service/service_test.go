@@ -4,7 +4,10 @@ import (
 	"testing"
 
 	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/pkg/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestPreimageAvailability(t *testing.T) {
@@ -119,3 +122,99 @@ func TestPreimageAvailability(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdatePreimages(t *testing.T) {
+	solicited := common.Blob{1, 2, 3}
+	solicitedMeta := PreimageMeta{Hash: blake2b.Sum256(solicited), BlobLength: common.BlobLength(len(solicited))}
+	provided := common.Blob{4, 5}
+	providedMeta := PreimageMeta{Hash: blake2b.Sum256(provided), BlobLength: common.BlobLength(len(provided))}
+
+	newServices := func() *Services {
+		services := &Services{}
+		services.Save(1, &ServiceAccount{
+			Preimages: map[common.Hash]common.Blob{providedMeta.Hash: provided},
+			PreimageMeta: map[PreimageMeta]PreimageAvailabilityHistory{
+				solicitedMeta: {},
+				providedMeta:  {3},
+			},
+		})
+		return services
+	}
+
+	tests := []struct {
+		name        string
+		preimages   []*PreimageRequest
+		expectedErr error
+	}{
+		{
+			name:      "Solicited preimage",
+			preimages: []*PreimageRequest{{ServiceId: 1, Preimage: solicited}},
+		},
+		{
+			name:        "Already provided preimage",
+			preimages:   []*PreimageRequest{{ServiceId: 1, Preimage: provided}},
+			expectedErr: ErrPreimageUnneeded,
+		},
+		{
+			name:        "Unknown service",
+			preimages:   []*PreimageRequest{{ServiceId: 2, Preimage: solicited}},
+			expectedErr: ErrPreimageUnneeded,
+		},
+		{
+			name:        "Duplicated preimages",
+			preimages:   []*PreimageRequest{{ServiceId: 1, Preimage: solicited}, {ServiceId: 1, Preimage: solicited}},
+			expectedErr: ErrPreimagesNotSortedUnique,
+		},
+		{
+			name:        "Unsorted preimages",
+			preimages:   []*PreimageRequest{{ServiceId: 2, Preimage: solicited}, {ServiceId: 1, Preimage: solicited}},
+			expectedErr: ErrPreimagesNotSortedUnique,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services := newServices()
+			footprints, err := services.Update(newServices(), 10, tt.preimages)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.Equal(t, newServices(), services, "services must not change on error")
+				return
+			}
+			require.NoError(t, err)
+
+			account, _ := services.Get(1)
+			require.Equal(t, solicited, account.Preimages[solicitedMeta.Hash])
+			require.Equal(t, PreimageAvailabilityHistory{10}, account.PreimageMeta[solicitedMeta])
+			require.Equal(t, []*Footprint{{NumOfStorageItems: 4, SizeOfStorageItems: 81*2 + 3 + 2}}, footprints)
+		})
+	}
+
+	t.Run("Preimage no longer solicited after accumulation", func(t *testing.T) {
+		prior := newServices()
+		services := newServices()
+		account, _ := services.Get(1)
+		delete(account.PreimageMeta, solicitedMeta)
+
+		footprints, err := services.Update(prior, 10, []*PreimageRequest{{ServiceId: 1, Preimage: solicited}})
+		require.NoError(t, err)
+		require.Equal(t, []*Footprint{nil}, footprints)
+		require.NotContains(t, account.Preimages, solicitedMeta.Hash)
+		require.NotContains(t, account.PreimageMeta, solicitedMeta)
+	})
+}
+
+func TestThresholdBalance(t *testing.T) {
+	account := &ServiceAccount{
+		StorageItems: map[common.Hash]common.Blob{{1}: {1, 2, 3}},
+		PreimageMeta: map[PreimageMeta]PreimageAvailabilityHistory{{Hash: common.Hash{2}, BlobLength: 10}: {}},
+		Balance:      500,
+	}
+
+	require.Equal(t, Footprint{NumOfStorageItems: 3, SizeOfStorageItems: 81 + 10 + 32 + 3}, account.Footprint())
+	require.Equal(t, Balance(BasicMinimumBalance+3*ItemMinimumBalance+126*OctetMinimumBalance), account.ThresholdBalance())
+	require.Equal(t, Balance(500-256), account.Headroom())
+
+	account.Balance = 200
+	require.Equal(t, Balance(0), account.Headroom())
+}
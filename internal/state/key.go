@@ -0,0 +1,55 @@
+package jamstate
+
+import (
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// State component indices used to construct state keys, Gray Paper (D.2).
+const (
+	authorizerPoolsIndex     uint8 = 1   // α
+	authorizerQueuesIndex    uint8 = 2   // φ
+	recentHistoryIndex       uint8 = 3   // β
+	safroleStateIndex        uint8 = 4   // γ
+	disputeStateIndex        uint8 = 5   // ψ
+	entropyPoolIndex         uint8 = 6   // η
+	stagingValidatorsIndex   uint8 = 7   // ι
+	activeValidatorsIndex    uint8 = 8   // κ
+	archivedValidatorsIndex  uint8 = 9   // λ
+	pendingWorkReportsIndex  uint8 = 10  // ρ
+	timeSlotIndex            uint8 = 11  // τ
+	privilegedServicesIndex  uint8 = 12  // χ
+	activityStatisticsIndex  uint8 = 13  // π
+	accumulationQueueIndex   uint8 = 14  // θ
+	accumulationHistoryIndex uint8 = 15  // ξ
+	serviceAccountIndex      uint8 = 255 // δ
+)
+
+// Gray Paper (D.1)
+// C(i) ≡ [i, 0, 0, ...]
+func stateKey(i uint8) common.Hash {
+	return common.Hash{i}
+}
+
+// Gray Paper (D.1)
+// C(i, s) ≡ [i, n0, 0, n1, 0, n2, 0, n3, 0, 0, ...] where n = E₄(s)
+func serviceStateKey(i uint8, serviceId service.ServiceId) common.Hash {
+	n := codec.EncodeUint(uint64(serviceId), 4)
+	return common.Hash{i, n[0], 0, n[1], 0, n[2], 0, n[3]}
+}
+
+// Gray Paper (D.1)
+// C(s, h) ≡ [n0, h0, n1, h1, n2, h2, n3, h3, h4, h5, ..., h27] where n = E₄(s)
+func serviceDataStateKey(serviceId service.ServiceId, h []byte) common.Hash {
+	n := codec.EncodeUint(uint64(serviceId), 4)
+
+	var key common.Hash
+	for i := range 4 {
+		key[2*i] = n[i]
+		key[2*i+1] = h[i]
+	}
+	copy(key[8:], h[4:28])
+
+	return key
+}
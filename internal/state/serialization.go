@@ -0,0 +1,275 @@
+package jamstate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/mmr"
+	"github.com/shunsukew/gojam/pkg/trie"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Root computes the state root, the merklization of the serialized state.
+// Gray Paper (D.5) M_σ(σ) ≡ M({(bits(k) ↦ v) ∣ (k ↦ v) ∈ T(σ)})
+func (s *State) Root() (common.Hash, error) {
+	serialized, err := s.Serialize()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return trie.Merklize(serialized), nil
+}
+
+// Serialize maps each state component into its key-value pairs, the state serialization T(σ) of the Gray Paper (D.2).
+func (s *State) Serialize() (map[common.Hash][]byte, error) {
+	serialized := make(map[common.Hash][]byte)
+
+	components := []struct {
+		index     uint8
+		serialize func() ([]byte, error)
+	}{
+		{authorizerPoolsIndex, encoder(s.AuthorizerPools)},                      // C(1) ↦ E([↕x ∣ x <− α])
+		{authorizerQueuesIndex, encoder(s.AuthorizerQueues)},                    // C(2) ↦ E(φ)
+		{recentHistoryIndex, s.serializeRecentHistory},                          // C(3)
+		{safroleStateIndex, s.serializeSafroleState},                            // C(4)
+		{disputeStateIndex, s.serializeDisputeState},                            // C(5)
+		{entropyPoolIndex, encoder(s.EntropyPool)},                              // C(6) ↦ E(η)
+		{stagingValidatorsIndex, encoder(s.ValidatorState.StagingValidators)},   // C(7) ↦ E(ι)
+		{activeValidatorsIndex, encoder(s.ValidatorState.ActiveValidators)},     // C(8) ↦ E(κ)
+		{archivedValidatorsIndex, encoder(s.ValidatorState.ArchivedValidators)}, // C(9) ↦ E(λ)
+		{pendingWorkReportsIndex, s.serializePendingWorkReports},                // C(10)
+		{timeSlotIndex, encoder(uint32(s.TimeSlot))},                            // C(11) ↦ E₄(τ)
+		{privilegedServicesIndex, s.serializePrivilegedServices},                // C(12)
+		{activityStatisticsIndex, encoder(s.ActivityStatistics)},                // C(13) ↦ E(E₄(πV), E₄(πL), πC, πS)
+		{accumulationQueueIndex, s.serializeAccumulationQueue},                  // C(14)
+		{accumulationHistoryIndex, s.serializeAccumulationHistory},              // C(15)
+	}
+	for _, component := range components {
+		encoded, err := component.serialize()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to serialize state component %d", component.index)
+		}
+		serialized[stateKey(component.index)] = encoded
+	}
+
+	for serviceId, account := range s.Services.All() {
+		if err := serializeServiceAccount(serialized, serviceId, account); err != nil {
+			return nil, errors.WithMessagef(err, "failed to serialize service account %d", serviceId)
+		}
+	}
+
+	return serialized, nil
+}
+
+func encoder(data interface{}) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return codec.Encode(data)
+	}
+}
+
+// C(3) ↦ E(↕[(h, E_M(b), s, ↕p) ∣ (h, b, s, p) <− β])
+func (s *State) serializeRecentHistory() ([]byte, error) {
+	type recentBlock struct {
+		HeaderHash            common.Hash
+		AccumulationResultMMR mmr.MMR
+		StateRoot             common.Hash
+		WorkPackageHashes     map[common.Hash]common.Hash
+	}
+
+	blocks := make([]recentBlock, len(s.RecentHistory))
+	for i, block := range s.RecentHistory {
+		blocks[i] = recentBlock{
+			HeaderHash:            block.HeaderHash,
+			AccumulationResultMMR: block.AccumulationResultMMR,
+			StateRoot:             block.StateRoot,
+			WorkPackageHashes:     block.WorkPackageHashes,
+		}
+	}
+
+	return codec.Encode(blocks)
+}
+
+// C(4) ↦ E(γk, γz, E₁(0) ⌢ γs if γs ∈ ⟦C⟧E, E₁(1) ⌢ γs if γs ∈ ⟦H_B⟧E, ↕γa)
+func (s *State) serializeSafroleState() ([]byte, error) {
+	safroleState := s.ValidatorState.SafroleState
+	if safroleState == nil {
+		safroleState = &safrole.SafroleState{}
+	}
+
+	encoded, err := codec.Encode(safroleState.PendingValidators)
+	if err != nil {
+		return nil, err
+	}
+
+	epochRoot, err := codec.Encode(safroleState.EpochRoot)
+	if err != nil {
+		return nil, err
+	}
+	encoded = append(encoded, epochRoot...)
+
+	switch series := safroleState.SealingKeySeries.(type) {
+	case safrole.Tickets:
+		if len(series) != jamtime.TimeSlotsPerEpoch {
+			return nil, errors.Errorf("sealing key series must have %d tickets, got %d", jamtime.TimeSlotsPerEpoch, len(series))
+		}
+		encoded = append(encoded, 0)
+		for _, ticket := range series {
+			ticketEncoded, err := codec.Encode(ticket)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, ticketEncoded...)
+		}
+	case *safrole.FallbackKeys:
+		keysEncoded, err := codec.Encode(series)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, 1)
+		encoded = append(encoded, keysEncoded...)
+	case nil:
+		// No sealing key series has been set yet, serialized as fallback mode with null keys.
+		keysEncoded, err := codec.Encode(safrole.FallbackKeys{})
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, 1)
+		encoded = append(encoded, keysEncoded...)
+	default:
+		return nil, errors.Errorf("unknown sealing key series kind %T", series)
+	}
+
+	ticketsAccumulator, err := codec.Encode(safroleState.TicketsAccumulator)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(encoded, ticketsAccumulator...), nil
+}
+
+// C(5) ↦ E(↕[x^ ∣ x ∈ ψg], ↕[x^ ∣ x ∈ ψb], ↕[x^ ∣ x ∈ ψw], ↕[x^ ∣ x ∈ ψo]), each set is ordered.
+func (s *State) serializeDisputeState() ([]byte, error) {
+	compareHashes := func(a, b common.Hash) int {
+		return bytes.Compare(a[:], b[:])
+	}
+
+	offenders := slices.Clone(s.DisputeState.Offenders)
+	slices.SortFunc(offenders, func(a, b ed25519.PublicKey) int {
+		return bytes.Compare(a, b)
+	})
+
+	return codec.Encode(struct {
+		GoodReports   []common.Hash
+		BadReports    []common.Hash
+		WonkeyReports []common.Hash
+		Offenders     []ed25519.PublicKey
+	}{
+		GoodReports:   slices.SortedFunc(slices.Values(s.DisputeState.GoodReports), compareHashes),
+		BadReports:    slices.SortedFunc(slices.Values(s.DisputeState.BadReports), compareHashes),
+		WonkeyReports: slices.SortedFunc(slices.Values(s.DisputeState.WonkeyReports), compareHashes),
+		Offenders:     offenders,
+	})
+}
+
+// C(10) ↦ E([¿(w, E₄(t)) ∣ (w, t) <− ρ])
+func (s *State) serializePendingWorkReports() ([]byte, error) {
+	var encoded []byte
+	for _, pending := range s.PendingWorkReports {
+		if pending == nil || pending.WorkReport == nil {
+			encoded = append(encoded, 0)
+			continue
+		}
+
+		report, err := codec.Encode(pending.WorkReport)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, 1)
+		encoded = append(encoded, report...)
+		encoded = append(encoded, codec.EncodeUint(uint64(pending.ReportedAt), 4)...)
+	}
+	return encoded, nil
+}
+
+// C(12) ↦ E₄(χm) ⌢ E₄(χa) ⌢ E₄(χv) ⌢ E(↕[E₄(s) ⌢ E₈(g) ∣ (s ↦ g) ∈ χg])
+func (s *State) serializePrivilegedServices() ([]byte, error) {
+	privileged := s.PrivilegedServices
+
+	encoded := codec.EncodeUint(uint64(privileged.Manager), 4)
+	for _, assigner := range privileged.Assigners {
+		encoded = append(encoded, codec.EncodeUint(uint64(assigner), 4)...)
+	}
+	encoded = append(encoded, codec.EncodeUint(uint64(privileged.Designator), 4)...)
+
+	serviceIds := slices.Sorted(maps.Keys(privileged.AlwaysAccumulate))
+	encoded = append(encoded, codec.EncodeNatural(uint64(len(serviceIds)))...)
+	for _, serviceId := range serviceIds {
+		encoded = append(encoded, codec.EncodeUint(uint64(serviceId), 4)...)
+		encoded = append(encoded, codec.EncodeUint(uint64(privileged.AlwaysAccumulate[serviceId]), 8)...)
+	}
+	return encoded, nil
+}
+
+// C(14) ↦ E([↕[(w, ↕d) ∣ (w, d) <− i] ∣ i <− θ])
+func (s *State) serializeAccumulationQueue() ([]byte, error) {
+	return codec.Encode(s.AccumulationQueue)
+}
+
+// C(15) ↦ E([↕[x^ ∣ x ∈ i] ∣ i <− ξ])
+func (s *State) serializeAccumulationHistory() ([]byte, error) {
+	return codec.Encode(s.AccumulationHistory)
+}
+
+// Service account serialization, Gray Paper (D.2)
+//
+//	C(255, s) ↦ a_c ⌢ E₈(a_b, a_g, a_m, a_o) ⌢ E₄(a_i)
+//	C(s, E₄(2³²−1) ⌢ k₀...₂₈) ↦ v for each (k ↦ v) ∈ a_s
+//	C(s, E₄(2³²−2) ⌢ h₁...₂₉) ↦ p for each (h ↦ p) ∈ a_p
+//	C(s, E₄(l) ⌢ H(h)₂...₃₀) ↦ E(↕[E₄(x) ∣ x <− t]) for each ((h, l) ↦ t) ∈ a_l
+func serializeServiceAccount(serialized map[common.Hash][]byte, serviceId service.ServiceId, account *service.ServiceAccount) error {
+	footprint := account.Footprint()
+
+	encoded := append([]byte{}, account.CodeHash[:]...)
+	encoded = append(encoded, codec.EncodeUint(uint64(account.Balance), 8)...)
+	encoded = append(encoded, codec.EncodeUint(uint64(account.AccumulateGas), 8)...)
+	encoded = append(encoded, codec.EncodeUint(uint64(account.OnTransferGas), 8)...)
+	encoded = append(encoded, codec.EncodeUint(footprint.SizeOfStorageItems, 8)...)
+	encoded = append(encoded, codec.EncodeUint(uint64(footprint.NumOfStorageItems), 4)...)
+	serialized[serviceStateKey(serviceAccountIndex, serviceId)] = encoded
+
+	for key, value := range account.StorageItems {
+		h := append(codec.EncodeUint(math.MaxUint32, 4), key[:28]...)
+		serialized[serviceDataStateKey(serviceId, h)] = value
+	}
+
+	for hash, preimage := range account.Preimages {
+		h := append(codec.EncodeUint(math.MaxUint32-1, 4), hash[1:29]...)
+		serialized[serviceDataStateKey(serviceId, h)] = preimage
+	}
+
+	for meta, availabilityHistory := range account.PreimageMeta {
+		hashOfHash := blake2b.Sum256(meta.Hash[:])
+		h := append(codec.EncodeUint(uint64(meta.BlobLength), 4), hashOfHash[2:30]...)
+
+		timeSlots := make([]uint32, len(availabilityHistory))
+		for i, timeSlot := range availabilityHistory {
+			timeSlots[i] = uint32(timeSlot)
+		}
+		value, err := codec.Encode(timeSlots)
+		if err != nil {
+			return err
+		}
+		serialized[serviceDataStateKey(serviceId, h)] = value
+	}
+
+	return nil
+}
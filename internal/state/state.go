@@ -1,11 +1,15 @@
 package jamstate
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/shunsukew/gojam/internal/accumulate"
 	authpool "github.com/shunsukew/gojam/internal/authorizer/pool"
 	authqueue "github.com/shunsukew/gojam/internal/authorizer/queue"
 	"github.com/shunsukew/gojam/internal/dispute"
 	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/statistics"
 	workreport "github.com/shunsukew/gojam/internal/work/report"
 
 	"github.com/shunsukew/gojam/internal/entropy"
@@ -16,21 +20,66 @@ import (
 
 // σ ≡ (α,β,γ,δ,η,ι,κ,λ,ρ,τ,φ,χ,ψ,π,θ,ξ)
 type State struct {
-	AuthorizerPools             authpool.AuthorizerPools       // α: The core αuthorizations pool. Equation 8.1 in Gray Paper.
-	RecentHistory               history.RecentHistory          // β: Information on the most recent βlocks.
-	Services                    service.Services               // δ: The (prior) state of the service accounts.
-	EntropyPool                 entropy.EntropyPool            // η: The eηtropy accumulator and epochal raηdomness.
-	ValidatorState              validator.ValidatorState       // (ι, κ, λ): The state of the validators related. & γ: State concerning Safrole. Equation 6.3 in Gray Paper.
-	PendingWorkReports          workreport.PendingWorkReports  // ρ: The ρending reports, per core, which are being made available prior to accumulation.
-	TimeSlot                    jamtime.TimeSlot               // τ: The most recent block’s τimeslot.
-	AuthorizerQueues            authqueue.AuthorizerQueues     // φ: The authorization queue.
-	PrivilegedServices                                         // χ: The privileged service indices.
-	DisputeState                dispute.DisputeState           // ψ: Past judgments/verdicts on work-reports and validators.
-	ValidatorActivityStatistics                                // π: The activity statistics for the validators.
-	AccumulationQueue           accumulate.AccumulationQueue   // θ: The accumulation queue.
-	AccumulationHistory         accumulate.AccumulationHistory // ξ: The accumulation history.
+	AuthorizerPools     authpool.AuthorizerPools       // α: The core αuthorizations pool. Equation 8.1 in Gray Paper.
+	RecentHistory       history.RecentHistory          // β: Information on the most recent βlocks.
+	Services            service.Services               // δ: The (prior) state of the service accounts.
+	EntropyPool         entropy.EntropyPool            // η: The eηtropy accumulator and epochal raηdomness.
+	ValidatorState      validator.ValidatorState       // (ι, κ, λ): The state of the validators related. & γ: State concerning Safrole. Equation 6.3 in Gray Paper.
+	PendingWorkReports  workreport.PendingWorkReports  // ρ: The ρending reports, per core, which are being made available prior to accumulation.
+	TimeSlot            jamtime.TimeSlot               // τ: The most recent block’s τimeslot.
+	AuthorizerQueues    authqueue.AuthorizerQueues     // φ: The authorization queue.
+	PrivilegedServices  service.PrivilegedServices     // χ: The privileged service indices.
+	DisputeState        dispute.DisputeState           // ψ: Past judgments/verdicts on work-reports and validators.
+	ActivityStatistics  statistics.ActivityStatistics  // π: The activity statistics for the validators, cores and services.
+	AccumulationQueue   accumulate.AccumulationQueue   // θ: The accumulation queue.
+	AccumulationHistory accumulate.AccumulationHistory // ξ: The accumulation history.
 }
 
-type PrivilegedServices struct{}
+// Clone returns a deep copy of the state components mutated by state transitions,
+// so that a block can be imported on the copy while the prior state stays untouched.
+func (s *State) Clone() *State {
+	cloned := *s
+
+	for coreIndex, pool := range s.AuthorizerPools {
+		cloned.AuthorizerPools[coreIndex] = slices.Clone(pool)
+	}
+
+	cloned.RecentHistory = make(history.RecentHistory, len(s.RecentHistory))
+	for i, block := range s.RecentHistory {
+		clonedBlock := *block
+		clonedBlock.AccumulationResultMMR = slices.Clone(block.AccumulationResultMMR)
+		clonedBlock.WorkPackageHashes = maps.Clone(block.WorkPackageHashes)
+		cloned.RecentHistory[i] = &clonedBlock
+	}
+
+	cloned.Services = s.Services.Clone()
+
+	if s.ValidatorState.SafroleState != nil {
+		safroleState := *s.ValidatorState.SafroleState
+		safroleState.TicketsAccumulator = slices.Clone(safroleState.TicketsAccumulator)
+		cloned.ValidatorState.SafroleState = &safroleState
+	}
+
+	for coreIndex, queue := range s.AuthorizerQueues {
+		if queue != nil {
+			clonedQueue := *queue
+			cloned.AuthorizerQueues[coreIndex] = &clonedQueue
+		}
+	}
 
-type ValidatorActivityStatistics struct{}
+	cloned.PrivilegedServices = s.PrivilegedServices.Clone()
+
+	cloned.DisputeState = dispute.DisputeState{
+		GoodReports:   slices.Clone(s.DisputeState.GoodReports),
+		BadReports:    slices.Clone(s.DisputeState.BadReports),
+		WonkeyReports: slices.Clone(s.DisputeState.WonkeyReports),
+		Offenders:     slices.Clone(s.DisputeState.Offenders),
+	}
+
+	cloned.ActivityStatistics = s.ActivityStatistics.Clone()
+
+	cloned.AccumulationQueue = s.AccumulationQueue.Clone()
+	cloned.AccumulationHistory = s.AccumulationHistory.Clone()
+
+	return &cloned
+}
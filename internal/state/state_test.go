@@ -0,0 +1,83 @@
+package jamstate
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateKeys(t *testing.T) {
+	require.Equal(t, common.Hash{11}, stateKey(timeSlotIndex))
+
+	require.Equal(
+		t,
+		common.Hash{255, 0x04, 0, 0x03, 0, 0x02, 0, 0x01},
+		serviceStateKey(serviceAccountIndex, service.ServiceId(0x01020304)),
+	)
+
+	h := make([]byte, 32)
+	for i := range h {
+		h[i] = byte(0xa0 + i)
+	}
+	expected := common.Hash{0x04, 0xa0, 0x03, 0xa1, 0x02, 0xa2, 0x01, 0xa3}
+	copy(expected[8:], h[4:28])
+	require.Equal(t, expected, serviceDataStateKey(service.ServiceId(0x01020304), h))
+}
+
+func TestStateRoot(t *testing.T) {
+	state := &State{}
+
+	serialized, err := state.Serialize()
+	require.NoError(t, err)
+	require.Len(t, serialized, 15, "expected one key-value pair for each state component")
+	require.Equal(t, []byte{0, 0, 0, 0}, serialized[stateKey(timeSlotIndex)])
+
+	root, err := state.Root()
+	require.NoError(t, err)
+	require.Equal(t, trie.Merklize(serialized), root)
+
+	state.TimeSlot = 1
+	state.Services.Save(1, &service.ServiceAccount{
+		StorageItems: map[common.Hash]common.Blob{{1}: {1, 2, 3}},
+		Preimages:    map[common.Hash]common.Blob{{2}: {4, 5}},
+		PreimageMeta: map[service.PreimageMeta]service.PreimageAvailabilityHistory{
+			{Hash: common.Hash{2}, BlobLength: 2}: {1},
+		},
+	})
+
+	serialized, err = state.Serialize()
+	require.NoError(t, err)
+	require.Len(t, serialized, 15+4, "expected account, storage, preimage and lookup entries of the service")
+	require.Equal(t, []byte{1, 0, 0, 0}, serialized[stateKey(timeSlotIndex)])
+
+	account := serialized[serviceStateKey(serviceAccountIndex, 1)]
+	require.Len(t, account, 32+8*4+4)
+	require.Equal(t, []byte{81 + 2 + 32 + 3, 0, 0, 0, 0, 0, 0, 0}, account[32+8*3:32+8*4], "a_o")
+	require.Equal(t, []byte{3, 0, 0, 0}, account[32+8*4:], "a_i")
+
+	newRoot, err := state.Root()
+	require.NoError(t, err)
+	require.NotEqual(t, root, newRoot)
+}
+
+func TestSerializePrivilegedServices(t *testing.T) {
+	state := &State{}
+	state.PrivilegedServices.Manager = 1
+	state.PrivilegedServices.Assigners[0] = 2
+	state.PrivilegedServices.Designator = 3
+	state.PrivilegedServices.AlwaysAccumulate = map[service.ServiceId]service.Gas{5: 10, 4: 20}
+
+	serialized, err := state.Serialize()
+	require.NoError(t, err)
+
+	expected := []byte{1, 0, 0, 0, 2, 0, 0, 0}
+	expected = append(expected, make([]byte, 4*(common.NumOfCores-1))...)
+	expected = append(expected, 3, 0, 0, 0)
+	expected = append(expected, 2)
+	expected = append(expected, 4, 0, 0, 0, 20, 0, 0, 0, 0, 0, 0, 0)
+	expected = append(expected, 5, 0, 0, 0, 10, 0, 0, 0, 0, 0, 0, 0)
+	require.Equal(t, expected, serialized[stateKey(privilegedServicesIndex)])
+}
@@ -0,0 +1,8 @@
+package statistics
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidValidatorIndex = errors.New("invalid validator index")
+	ErrInvalidCoreIndex      = errors.New("invalid core index")
+)
@@ -0,0 +1,172 @@
+package statistics
+
+import (
+	"crypto/ed25519"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// UpdateValidators accumulates the activity of the block author, the guarantors and the assurers into πV′.
+// The statistics of the current epoch are rotated into πL′ when the block starts a new epoch.
+func (s *ActivityStatistics) UpdateValidators(
+	timeSlot jamtime.TimeSlot, // τ′
+	prevTimeSlot jamtime.TimeSlot, // τ
+	authorIndex uint16, // Hi
+	extrinsic *block.Extrinsic,
+	reporters []ed25519.PublicKey, // R: The guarantors of the incoming work reports.
+	currentValidators *[common.NumOfValidators]*keys.ValidatorKey, // κ′
+) error {
+	if int(authorIndex) >= common.NumOfValidators {
+		return errors.WithMessagef(ErrInvalidValidatorIndex, "block author index %d out of range", authorIndex)
+	}
+
+	// (13.5) (a, πL′) ≡ (πV, πL) if e′ = e, ([(0, 0, 0, 0, 0, 0), ...], πV) otherwise
+	if timeSlot.ToEpoch() != prevTimeSlot.ToEpoch() {
+		s.Last = s.Current
+		s.Current = ValidatorStatistics{}
+	}
+
+	// (13.6) b′, t′, p′ and d′ are increased for the block author v = Hi
+	author := &s.Current[authorIndex]
+	author.Blocks++
+	author.Tickets += uint32(len(extrinsic.Tickets))
+	author.Preimages += uint32(len(extrinsic.Preimages))
+	for _, preimage := range extrinsic.Preimages {
+		author.PreimagesSize += uint32(len(preimage.Blob))
+	}
+
+	// g′ ≡ a[v]g + (κ′v ∈ R)
+	reporterSet := make(map[string]struct{}, len(reporters))
+	for _, reporter := range reporters {
+		reporterSet[string(reporter)] = struct{}{}
+	}
+	for validatorIndex, validatorKey := range currentValidators {
+		if validatorKey == nil {
+			continue
+		}
+		if _, ok := reporterSet[string(validatorKey.Ed25519PublicKey)]; ok {
+			s.Current[validatorIndex].Guarantees++
+		}
+	}
+
+	// a′ ≡ a[v]a + (∃a ∈ EA ∶ av = v)
+	for _, assurance := range extrinsic.Assurances {
+		if assurance.ValidatorIndex >= common.NumOfValidators {
+			return errors.WithMessagef(ErrInvalidValidatorIndex, "assurer index %d out of range", assurance.ValidatorIndex)
+		}
+		s.Current[assurance.ValidatorIndex].Assurances++
+	}
+
+	return nil
+}
+
+// UpdateCores replaces πC′ with the load of the incoming, assured and newly available work reports of the block.
+func (s *ActivityStatistics) UpdateCores(
+	extrinsic *block.Extrinsic,
+	availableReports []*workreport.WorkReport, // W: The newly available work reports.
+) error {
+	s.Cores = CoreStatistics{}
+
+	// (13.8) i, x, z, e, u and b are summed over the incoming work reports of each core.
+	for _, guarantee := range extrinsic.Guarantees {
+		report := guarantee.WorkReport
+		if report.CoreIndex >= common.NumOfCores {
+			return errors.WithMessagef(ErrInvalidCoreIndex, "core index %d out of range", report.CoreIndex)
+		}
+
+		record := &s.Cores[report.CoreIndex]
+		for _, result := range report.WorkResults {
+			record.Imports += result.RefineLoad.Imports
+			record.ExtrinsicCount += result.RefineLoad.ExtrinsicCount
+			record.ExtrinsicSize += result.RefineLoad.ExtrinsicSize
+			record.Exports += result.RefineLoad.Exports
+			record.GasUsed += result.RefineLoad.GasUsed
+		}
+		record.BundleSize += report.AvailabilitySpecification.WorkBundleLength
+	}
+
+	// (13.9) d ≡ Σ (ws)l + WG⌈(ws)n·65/64⌉ over the newly available work reports of each core.
+	for _, report := range availableReports {
+		if report.CoreIndex >= common.NumOfCores {
+			return errors.WithMessagef(ErrInvalidCoreIndex, "core index %d out of range", report.CoreIndex)
+		}
+
+		spec := report.AvailabilitySpecification
+		segments := (uint32(spec.SegmentCount)*65 + 63) / 64
+		s.Cores[report.CoreIndex].DALoad += spec.WorkBundleLength + workreport.SegmentSize*segments
+	}
+
+	// (13.10) p ≡ Σ af[c] over the assurances.
+	for _, assurance := range extrinsic.Assurances {
+		for coreIndex, available := range assurance.WorkReportAvailabilities {
+			if available {
+				s.Cores[coreIndex].Popularity++
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateServices replaces πS′ with the activity of each service provided with preimages,
+// refined by the incoming work reports, accumulated or receiving deferred transfers in the block.
+func (s *ActivityStatistics) UpdateServices(
+	extrinsic *block.Extrinsic,
+	accumulated []*workreport.WorkReport, // W*...n: The accumulated work reports.
+	accumulationGasUsed map[service.ServiceId]service.Gas, // u: The gas used by each accumulated service.
+	receivedTransfers map[service.ServiceId]uint32, // |R(t, d)|: The number of deferred transfers received by each service.
+	onTransferGasUsed map[service.ServiceId]service.Gas, // The gas used by the on-transfer invocation of each service.
+) {
+	s.Services = make(ServiceStatistics)
+
+	// (13.12) p ≡ Σ (1, |d|) over the preimages provided to the service.
+	for _, preimage := range extrinsic.Preimages {
+		record := s.Services.record(preimage.Requester)
+		record.ProvidedCount++
+		record.ProvidedSize += uint32(len(preimage.Blob))
+	}
+
+	// (13.13) r, i, x, z and e are summed over the work results of the incoming work reports.
+	for _, guarantee := range extrinsic.Guarantees {
+		for _, result := range guarantee.WorkReport.WorkResults {
+			record := s.Services.record(result.ServiceId)
+			record.RefinementCount++
+			record.RefinementGasUsed += result.RefineLoad.GasUsed
+			record.Imports += result.RefineLoad.Imports
+			record.ExtrinsicCount += result.RefineLoad.ExtrinsicCount
+			record.ExtrinsicSize += result.RefineLoad.ExtrinsicSize
+			record.Exports += result.RefineLoad.Exports
+		}
+	}
+
+	// (13.14) I ≡ {(s ↦ (N(s), G(s))) ∣ N(s) + G(s) ≠ 0}
+	accumulatedItems := make(map[service.ServiceId]uint32)
+	for _, report := range accumulated {
+		for _, result := range report.WorkResults {
+			accumulatedItems[result.ServiceId]++
+		}
+	}
+	for serviceId, count := range accumulatedItems {
+		s.Services.record(serviceId).AccumulateCount = count
+	}
+	for serviceId, gasUsed := range accumulationGasUsed {
+		if gasUsed == 0 {
+			continue
+		}
+		s.Services.record(serviceId).AccumulateGasUsed = gasUsed
+	}
+
+	// (13.15) X ≡ {(d ↦ (|R(t, d)|, u)) ∣ R(t, d) ≠ []}
+	for serviceId, count := range receivedTransfers {
+		s.Services.record(serviceId).OnTransferCount = count
+	}
+	for serviceId, gasUsed := range onTransferGasUsed {
+		s.Services.record(serviceId).OnTransferGasUsed = gasUsed
+	}
+}
@@ -0,0 +1,81 @@
+package statistics
+
+import (
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// (13.1) π ≡ (πV, πL, πC, πS)
+type ActivityStatistics struct {
+	Current  ValidatorStatistics // πV: The validator statistics accumulated over the current epoch.
+	Last     ValidatorStatistics // πL: The validator statistics of the previous epoch.
+	Cores    CoreStatistics      // πC: The core statistics of the most recent block.
+	Services ServiceStatistics   // πS: The service statistics of the most recent block.
+}
+
+type ValidatorStatistics [common.NumOfValidators]ValidatorRecord
+
+// (13.2) ⟦(b ∈ N, t ∈ N, p ∈ N, d ∈ N, g ∈ N, a ∈ N)⟧V
+type ValidatorRecord struct {
+	Blocks        uint32 // b: The number of blocks produced by the validator.
+	Tickets       uint32 // t: The number of tickets introduced by the validator.
+	Preimages     uint32 // p: The number of preimages introduced by the validator.
+	PreimagesSize uint32 // d: The total number of octets across all preimages introduced by the validator.
+	Guarantees    uint32 // g: The number of reports guaranteed by the validator.
+	Assurances    uint32 // a: The number of availability assurances made by the validator.
+}
+
+type CoreStatistics [common.NumOfCores]CoreRecord
+
+// (13.3) ⟦(d ∈ N, p ∈ N, i ∈ N, e ∈ N, z ∈ N, x ∈ N, b ∈ N, u ∈ NG)⟧C
+type CoreRecord struct {
+	DALoad         uint32      `codec:"compact"` // d: The amount of data made available in the Erasure Coded data store.
+	Popularity     uint32      `codec:"compact"` // p: The number of validators which assured availability for the core.
+	Imports        uint32      `codec:"compact"` // i: The number of segments imported by the incoming reports.
+	Exports        uint32      `codec:"compact"` // e: The number of segments exported by the incoming reports.
+	ExtrinsicSize  uint32      `codec:"compact"` // z: The total size of the extrinsics of the incoming reports.
+	ExtrinsicCount uint32      `codec:"compact"` // x: The number of extrinsics of the incoming reports.
+	BundleSize     uint32      `codec:"compact"` // b: The total size of the work bundles of the incoming reports.
+	GasUsed        service.Gas `codec:"compact"` // u: The gas used for refinement of the incoming reports.
+}
+
+type ServiceStatistics map[service.ServiceId]*ServiceRecord
+
+// (13.4) D⟨NS → (p ∈ (N, N), r ∈ (N, NG), i ∈ N, e ∈ N, z ∈ N, x ∈ N, a ∈ (N, NG), t ∈ (N, NG))⟩
+type ServiceRecord struct {
+	ProvidedCount     uint32      `codec:"compact"` // p: The number of preimages provided to the service.
+	ProvidedSize      uint32      `codec:"compact"` // p: The total size of the preimages provided to the service.
+	RefinementCount   uint32      `codec:"compact"` // r: The number of work items refined for the service.
+	RefinementGasUsed service.Gas `codec:"compact"` // r: The gas used for refinement of the work items.
+	Imports           uint32      `codec:"compact"` // i: The number of segments imported by the work items.
+	Exports           uint32      `codec:"compact"` // e: The number of segments exported by the work items.
+	ExtrinsicSize     uint32      `codec:"compact"` // z: The total size of the extrinsics of the work items.
+	ExtrinsicCount    uint32      `codec:"compact"` // x: The number of extrinsics of the work items.
+	AccumulateCount   uint32      `codec:"compact"` // a: The number of work items accumulated by the service.
+	AccumulateGasUsed service.Gas `codec:"compact"` // a: The gas used for accumulation of the work items.
+	OnTransferCount   uint32      `codec:"compact"` // t: The number of deferred transfers received by the service.
+	OnTransferGasUsed service.Gas `codec:"compact"` // t: The gas used for processing the deferred transfers.
+}
+
+// Clone returns a deep copy of the statistics.
+func (s *ActivityStatistics) Clone() ActivityStatistics {
+	cloned := *s
+	if s.Services != nil {
+		cloned.Services = make(ServiceStatistics, len(s.Services))
+		for serviceId, record := range s.Services {
+			clonedRecord := *record
+			cloned.Services[serviceId] = &clonedRecord
+		}
+	}
+	return cloned
+}
+
+// record returns the statistics of the service, inserting an empty record if it does not exist yet.
+func (s ServiceStatistics) record(serviceId service.ServiceId) *ServiceRecord {
+	record, ok := s[serviceId]
+	if !ok {
+		record = &ServiceRecord{}
+		s[serviceId] = record
+	}
+	return record
+}
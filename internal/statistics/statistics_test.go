@@ -0,0 +1,128 @@
+package statistics
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateValidators(t *testing.T) {
+	validators := &[common.NumOfValidators]*keys.ValidatorKey{}
+	for i := range validators {
+		validators[i] = &keys.ValidatorKey{Ed25519PublicKey: ed25519.PublicKey{byte(i + 1)}}
+	}
+
+	extrinsic := &block.Extrinsic{
+		PreimagesExtrinsic: block.PreimagesExtrinsic{
+			Preimages: []block.Preimage{{Requester: 1, Blob: []byte{1, 2, 3}}, {Requester: 2, Blob: []byte{4}}},
+		},
+		AssuarancesExtrinsic: block.AssuarancesExtrinsic{
+			Assurances: []*workreport.Assurance{{ValidatorIndex: 1}, {ValidatorIndex: 2}},
+		},
+	}
+	reporters := []ed25519.PublicKey{validators[2].Ed25519PublicKey, validators[3].Ed25519PublicKey}
+
+	statistics := ActivityStatistics{}
+	statistics.Current[0].Blocks = 7
+
+	err := statistics.UpdateValidators(1, 0, 0, extrinsic, reporters, validators)
+	require.NoError(t, err)
+	require.Equal(t, ValidatorRecord{Blocks: 8, Preimages: 2, PreimagesSize: 4}, statistics.Current[0])
+	require.Equal(t, ValidatorRecord{Assurances: 1}, statistics.Current[1])
+	require.Equal(t, ValidatorRecord{Guarantees: 1, Assurances: 1}, statistics.Current[2])
+	require.Equal(t, ValidatorRecord{Guarantees: 1}, statistics.Current[3])
+	require.Equal(t, ValidatorStatistics{}, statistics.Last)
+
+	// The first block of the next epoch rotates the statistics of the current epoch into πL′.
+	current := statistics.Current
+	err = statistics.UpdateValidators(jamtime.TimeSlotsPerEpoch, 1, 1, &block.Extrinsic{}, nil, validators)
+	require.NoError(t, err)
+	require.Equal(t, current, statistics.Last)
+	require.Equal(t, ValidatorRecord{Blocks: 1}, statistics.Current[1])
+
+	err = statistics.UpdateValidators(jamtime.TimeSlotsPerEpoch+1, jamtime.TimeSlotsPerEpoch, common.NumOfValidators, &block.Extrinsic{}, nil, validators)
+	require.ErrorIs(t, err, ErrInvalidValidatorIndex)
+}
+
+func TestUpdateCoresAndServices(t *testing.T) {
+	load := workreport.RefineLoad{GasUsed: 10, Imports: 1, ExtrinsicCount: 2, ExtrinsicSize: 3, Exports: 4}
+	report := &workreport.WorkReport{
+		AvailabilitySpecification: &workreport.AvailabilitySpecification{WorkBundleLength: 100, SegmentCount: 64},
+		CoreIndex:                 1,
+		WorkResults:               []*workreport.WorkResult{{ServiceId: 5, RefineLoad: load}, {ServiceId: 6, RefineLoad: load}},
+	}
+
+	var availabilities [common.NumOfCores]bool
+	availabilities[1] = true
+	extrinsic := &block.Extrinsic{
+		PreimagesExtrinsic: block.PreimagesExtrinsic{
+			Preimages: []block.Preimage{{Requester: 5, Blob: []byte{1, 2}}},
+		},
+		GuaranteesExtrinsic: block.GuaranteesExtrinsic{
+			Guarantees: []*workreport.Guarantee{{WorkReport: report}},
+		},
+		AssuarancesExtrinsic: block.AssuarancesExtrinsic{
+			Assurances: []*workreport.Assurance{{WorkReportAvailabilities: availabilities}, {WorkReportAvailabilities: availabilities}},
+		},
+	}
+
+	statistics := ActivityStatistics{}
+	err := statistics.UpdateCores(extrinsic, []*workreport.WorkReport{report})
+	require.NoError(t, err)
+	require.Equal(t, CoreRecord{
+		DALoad:         100 + workreport.SegmentSize*65,
+		Popularity:     2,
+		Imports:        2,
+		Exports:        8,
+		ExtrinsicSize:  6,
+		ExtrinsicCount: 4,
+		BundleSize:     100,
+		GasUsed:        20,
+	}, statistics.Cores[1])
+	require.Equal(t, CoreRecord{}, statistics.Cores[0])
+
+	statistics.UpdateServices(extrinsic, []*workreport.WorkReport{report}, map[service.ServiceId]service.Gas{5: 30, 6: 0}, map[service.ServiceId]uint32{7: 2}, map[service.ServiceId]service.Gas{7: 40})
+	require.Len(t, statistics.Services, 3)
+	require.Equal(t, &ServiceRecord{
+		ProvidedCount:     1,
+		ProvidedSize:      2,
+		RefinementCount:   1,
+		RefinementGasUsed: 10,
+		Imports:           1,
+		Exports:           4,
+		ExtrinsicSize:     3,
+		ExtrinsicCount:    2,
+		AccumulateCount:   1,
+		AccumulateGasUsed: 30,
+	}, statistics.Services[5])
+	require.Equal(t, service.Gas(0), statistics.Services[6].AccumulateGasUsed)
+	require.Equal(t, &ServiceRecord{OnTransferCount: 2, OnTransferGasUsed: 40}, statistics.Services[7])
+}
+
+func TestActivityStatisticsEncodeDecode(t *testing.T) {
+	statistics := ActivityStatistics{Services: ServiceStatistics{3: {ProvidedCount: 1, AccumulateGasUsed: 1 << 20}}}
+	statistics.Current[0].Blocks = 1
+	statistics.Last[1].Assurances = 2
+	statistics.Cores[0].DALoad = 1 << 16
+
+	encoded, err := codec.Encode(statistics)
+	require.NoError(t, err)
+
+	var decoded ActivityStatistics
+	err = codec.Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, statistics, decoded)
+
+	// Cloned service records are not shared with the original statistics.
+	cloned := statistics.Clone()
+	cloned.Services[3].ProvidedCount++
+	require.Equal(t, uint32(1), statistics.Services[3].ProvidedCount)
+}
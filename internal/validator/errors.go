@@ -1 +1,9 @@
 package validator
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidBlockAuthor   = errors.New("invalid block author")
+	ErrInvalidBlockSeal     = errors.New("invalid block seal")
+	ErrInvalidEntropySource = errors.New("invalid block entropy source")
+)
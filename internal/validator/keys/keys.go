@@ -3,12 +3,14 @@ package keys
 import (
 	"crypto/ed25519"
 
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
 	"github.com/shunsukew/gojam/pkg/crypto/bls"
 )
 
 const (
 	ValidatorKeyMetadataSize = 128
+	ValidatorKeySize         = bandersnatch.PublicKeySize + ed25519.PublicKeySize + bls.BlsKeySize + ValidatorKeyMetadataSize
 )
 
 // Validator keys tuple. Defined as K;blackboard in the Gray Paper
@@ -18,3 +20,37 @@ type ValidatorKey struct {
 	BLSKey                bls.BLSKey                     // kbls
 	Metadata              [ValidatorKeyMetadataSize]byte // km
 }
+
+// Gray Paper (6.8)
+// K ≡ B₃₃₆, kb ⌢ ke ⌢ kbls ⌢ km. A missing Ed25519 key is the null key and encoded as zeros.
+func (k ValidatorKey) MarshalJAM() ([]byte, error) {
+	encoded := make([]byte, 0, ValidatorKeySize)
+	encoded = append(encoded, k.BandersnatchPublicKey[:]...)
+	if len(k.Ed25519PublicKey) == 0 {
+		encoded = append(encoded, make([]byte, ed25519.PublicKeySize)...)
+	} else {
+		encoded = append(encoded, k.Ed25519PublicKey...)
+	}
+	encoded = append(encoded, k.BLSKey[:]...)
+	encoded = append(encoded, k.Metadata[:]...)
+	if len(encoded) != ValidatorKeySize {
+		return nil, codec.ErrInvalidLength
+	}
+	return encoded, nil
+}
+
+func (k *ValidatorKey) UnmarshalJAM(d *codec.Decoder) error {
+	encoded, err := d.ReadBytes(ValidatorKeySize)
+	if err != nil {
+		return err
+	}
+
+	copy(k.BandersnatchPublicKey[:], encoded)
+	encoded = encoded[bandersnatch.PublicKeySize:]
+	k.Ed25519PublicKey = ed25519.PublicKey(append([]byte{}, encoded[:ed25519.PublicKeySize]...))
+	encoded = encoded[ed25519.PublicKeySize:]
+	copy(k.BLSKey[:], encoded)
+	copy(k.Metadata[:], encoded[bls.BlsKeySize:])
+
+	return nil
+}
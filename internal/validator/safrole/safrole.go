@@ -7,10 +7,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 	"github.com/shunsukew/gojam/pkg/crypto"
 	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
-	"github.com/shunsukew/scale-codec-go/codec"
 	"golang.org/x/crypto/blake2b"
 )
 
@@ -25,8 +25,8 @@ type SealingKeySeriesKind interface {
 // Defined as C;blackboard in the Gray Paper
 // C;blackboard ≡ [ y ∈ H, r ∈ NumN ]
 type Ticket struct {
-	EntryIndex uint8                  // r: r ∈ NumN.
 	TicketID   bandersnatch.VrfOutput // y: y ∈ H
+	EntryIndex uint8                  // r: r ∈ NumN.
 }
 
 type Tickets []*Ticket
@@ -89,7 +89,7 @@ func (s *SafroleState) AccumulateTickets(ticketProofs []TicketProof, priorEpochR
 		}
 
 		vrfOutput, err := ticketProof.TicketProof.Verify(
-			buildTicketSealInput(entropy, ticketProof.EntryIndex),
+			BuildTicketSealInput(entropy, ticketProof.EntryIndex),
 			[]byte{},
 			priorEpochRoot,
 		)
@@ -139,7 +139,8 @@ func (s *SafroleState) AccumulateTickets(ticketProofs []TicketProof, priorEpochR
 	return nil
 }
 
-func buildTicketSealInput(entropy common.Hash, entryIndex uint8) []byte {
+// VRF input of ticket seals, XT ⌢ η ++ r
+func BuildTicketSealInput(entropy common.Hash, entryIndex uint8) []byte {
 	data := []byte(crypto.JamTicketSealStatement)
 	data = append(data, entropy[:]...)
 	data = append(data, byte(entryIndex))
@@ -189,29 +190,11 @@ func FallbackKeysSequence(entropy common.Hash, validatorKeys []*keys.ValidatorKe
 	numOfValidatorKeys := uint32(len(validatorKeys))
 	fallbackKeys := &FallbackKeys{}
 	for i := range len(fallbackKeys) {
-		// TODO: Replace to own JAM codec implementation
-		fallbackKeyIndexBytes := make([]byte, 4)
-		var err error
-		if i != 0 {
-			offsetBytes, err := codec.IntToBytes(uint32(i))
-			if err != nil {
-				return &FallbackKeys{}, errors.WithStack(err)
-			}
-			fallbackKeyIndexBytes = offsetBytes.GetAll()
-		}
-
-		hash := blake2b.Sum256(append(entropy[:], fallbackKeyIndexBytes...))
+		// H(r ⌢ E4(i))
+		hash := blake2b.Sum256(append(entropy[:], codec.EncodeUint(uint64(i), 4)...))
 
-		var num uint32
-		bytes, err := codec.NewBytes(hash[:])
-		if err != nil {
-			return &FallbackKeys{}, errors.WithStack(err)
-		}
-		decodedU32Num, err := bytes.ToUint32()
-		if err != nil {
-			return &FallbackKeys{}, errors.WithStack(err)
-		}
-		num = uint32(decodedU32Num)
+		// E4^-1(H(r ⌢ E4(i))_...4)
+		num := uint32(codec.DecodeUint(hash[:4]))
 
 		fallbackKeys[i] = validatorKeys[num%numOfValidatorKeys].BandersnatchPublicKey
 	}
@@ -15,17 +15,17 @@ func TestSortedAndNonDuplicates(t *testing.T) {
 	}{
 		{
 			name:     "Sorted and non-duplicate tickets",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{2}}, {0, bandersnatch.VrfOutput{3}}, {1, bandersnatch.VrfOutput{4}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{3}, 0}, {bandersnatch.VrfOutput{4}, 1}},
 			expected: true,
 		},
 		{
 			name:     "Not sorted tickets",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{2}}, {1, bandersnatch.VrfOutput{1}}, {0, bandersnatch.VrfOutput{3}}, {1, bandersnatch.VrfOutput{4}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{2}, 0}, {bandersnatch.VrfOutput{1}, 1}, {bandersnatch.VrfOutput{3}, 0}, {bandersnatch.VrfOutput{4}, 1}},
 			expected: false,
 		},
 		{
 			name:     "Duplicate tickets",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{2}}, {1, bandersnatch.VrfOutput{1}}, {0, bandersnatch.VrfOutput{4}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{1}, 1}, {bandersnatch.VrfOutput{4}, 0}},
 			expected: false,
 		},
 		{
@@ -35,7 +35,7 @@ func TestSortedAndNonDuplicates(t *testing.T) {
 		},
 		{
 			name:     "Single ticket",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{1}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{1}, 0}},
 			expected: true,
 		},
 	}
@@ -58,13 +58,13 @@ func TestSortTickets(t *testing.T) {
 	}{
 		{
 			name:     "Unsorted tickets",
-			input:    []*Ticket{{1, bandersnatch.VrfOutput{2}}, {0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{4}}, {0, bandersnatch.VrfOutput{3}}},
-			expected: []*Ticket{{0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{2}}, {0, bandersnatch.VrfOutput{3}}, {1, bandersnatch.VrfOutput{4}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{4}, 1}, {bandersnatch.VrfOutput{3}, 0}},
+			expected: []*Ticket{{bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{3}, 0}, {bandersnatch.VrfOutput{4}, 1}},
 		},
 		{
 			name:     "Already sorted tickets",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{2}}, {0, bandersnatch.VrfOutput{3}}, {1, bandersnatch.VrfOutput{4}}},
-			expected: []*Ticket{{0, bandersnatch.VrfOutput{1}}, {1, bandersnatch.VrfOutput{2}}, {0, bandersnatch.VrfOutput{3}}, {1, bandersnatch.VrfOutput{4}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{3}, 0}, {bandersnatch.VrfOutput{4}, 1}},
+			expected: []*Ticket{{bandersnatch.VrfOutput{1}, 0}, {bandersnatch.VrfOutput{2}, 1}, {bandersnatch.VrfOutput{3}, 0}, {bandersnatch.VrfOutput{4}, 1}},
 		},
 		{
 			name:     "Empty input",
@@ -73,8 +73,8 @@ func TestSortTickets(t *testing.T) {
 		},
 		{
 			name:     "Single ticket",
-			input:    []*Ticket{{0, bandersnatch.VrfOutput{1}}},
-			expected: []*Ticket{{0, bandersnatch.VrfOutput{1}}},
+			input:    []*Ticket{{bandersnatch.VrfOutput{1}, 0}},
+			expected: []*Ticket{{bandersnatch.VrfOutput{1}, 0}},
 		},
 	}
 
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/entropy"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+)
+
+// VerifyBlockSeal verifies the block seal Hs and the entropy source Hv, and returns Y(Hv).
+// It must be called on the posterior validator state (γ′, κ′) with the posterior entropy pool η′.
+func (vs *ValidatorState) VerifyBlockSeal(header *block.Header, entropyPool entropy.EntropyPool) (bandersnatch.VrfOutput, error) {
+	if int(header.BlockAuthorIndex) >= common.NumOfValidators || vs.ActiveValidators[header.BlockAuthorIndex] == nil {
+		return bandersnatch.VrfOutput{}, errors.WithMessagef(ErrInvalidBlockAuthor, "block author index %d is out of range", header.BlockAuthorIndex)
+	}
+
+	// Ha = κ′[Hi]b
+	authorKey := vs.ActiveValidators[header.BlockAuthorIndex].BandersnatchPublicKey
+
+	unsignedHeader, err := header.EncodeUnsigned()
+	if err != nil {
+		return bandersnatch.VrfOutput{}, errors.WithStack(err)
+	}
+
+	// i = γ′s[Ht]↺
+	slot := header.TimeSlot.ToTimeSlotInEpoch()
+
+	var sealOutput bandersnatch.VrfOutput
+	switch series := vs.SafroleState.SealingKeySeries.(type) {
+	case safrole.Tickets:
+		ticket := series[int(slot)%len(series)]
+
+		// Equation (6.15)
+		// Hs ∈ V;tilde_Ha^{XT ⌢ η′3 ++ ir}⟨EU(H)⟩, iy = Y(Hs)
+		sealOutput, err = header.BlockSealSignature.Verify(safrole.BuildTicketSealInput(entropyPool[3], ticket.EntryIndex), unsignedHeader, authorKey)
+		if err != nil {
+			return bandersnatch.VrfOutput{}, errors.WithMessagef(ErrInvalidBlockSeal, "ticket seal verification failed: %v", err)
+		}
+		if sealOutput != ticket.TicketID {
+			return bandersnatch.VrfOutput{}, errors.WithMessage(ErrInvalidBlockSeal, "seal output does not match ticket identifier")
+		}
+	case *safrole.FallbackKeys:
+		// Equation (6.16)
+		// i = Ha, Hs ∈ V;tilde_Ha^{XF ⌢ η′3}⟨EU(H)⟩
+		if series[slot] != authorKey {
+			return bandersnatch.VrfOutput{}, errors.WithMessagef(ErrInvalidBlockAuthor, "block author %d is not the fallback key of slot %d", header.BlockAuthorIndex, slot)
+		}
+
+		input := append([]byte(crypto.JamFallbackSealStatement), entropyPool[3][:]...)
+		sealOutput, err = header.BlockSealSignature.Verify(input, unsignedHeader, authorKey)
+		if err != nil {
+			return bandersnatch.VrfOutput{}, errors.WithMessagef(ErrInvalidBlockSeal, "fallback seal verification failed: %v", err)
+		}
+	default:
+		return bandersnatch.VrfOutput{}, errors.WithMessage(ErrInvalidBlockSeal, "sealing key series is not set")
+	}
+
+	// Equation (6.17)
+	// Hv ∈ V;tilde_Ha^{XE ⌢ Y(Hs)}⟨[]⟩
+	input := append([]byte(crypto.JamEntropyStatement), sealOutput[:]...)
+	entropySource, err := header.VRFSignature.Verify(input, []byte{}, authorKey)
+	if err != nil {
+		return bandersnatch.VrfOutput{}, errors.WithMessagef(ErrInvalidEntropySource, "entropy source verification failed: %v", err)
+	}
+
+	return entropySource, nil
+}
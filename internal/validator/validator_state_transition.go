@@ -55,7 +55,7 @@ func (s *ValidatorState) Update(
 		s.RotateValidators(offenders)
 
 		// (6.27)
-		// He ≡ (η0, η1, [kb ∣k <− γk']) if e' > e
+		// He ≡ (η0, η1, [(kb, ke) ∣ k <− γk']) if e' > e
 		epockMarker = newEpochMarker(&prevEntropyPool, s.SafroleState.PendingValidators)
 
 		// Determine sealing key series
@@ -78,6 +78,9 @@ func (s *ValidatorState) Update(
 
 		// As defined in equation (6.34), reset prior accumulator γa when e' > e
 		s.SafroleState.ResetTicketsAccumulator()
+	} else {
+		// Equation (6.22) η′0 ≡ H(η0 ⌢ Y(Hv)) for every block
+		entropyPool.AccumulateEntropy(vrfOutput)
 	}
 
 	// Equation (6.28)
@@ -109,11 +112,11 @@ func newEpochMarker(entropyPool *entropy.EntropyPool, validatorKeys *[common.Num
 			Next:    entropyPool[0],
 			Current: entropyPool[1],
 		},
-		BandersnatchPubKeys: [common.NumOfValidators]bandersnatch.PublicKey{},
 	}
 
 	for i, validatorKey := range validatorKeys {
-		epochMarker.BandersnatchPubKeys[i] = validatorKey.BandersnatchPublicKey
+		epochMarker.Validators[i].Bandersnatch = validatorKey.BandersnatchPublicKey
+		epochMarker.Validators[i].Ed25519 = validatorKey.Ed25519PublicKey
 	}
 
 	return epochMarker
@@ -6,9 +6,11 @@ import (
 	mathrand "math/rand"
 	"testing"
 
+	"github.com/shunsukew/gojam/internal/entropy"
 	"github.com/shunsukew/gojam/internal/validator/keys"
 	"github.com/shunsukew/gojam/internal/validator/safrole"
 	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,3 +63,23 @@ func TestNullifyOffenders(t *testing.T) {
 		}
 	}
 }
+
+func TestNewEpochMarker(t *testing.T) {
+	entropyPool := &entropy.EntropyPool{{1}, {2}, {3}, {4}}
+	pendingValidators := &[common.NumOfValidators]*keys.ValidatorKey{}
+	for i := range pendingValidators {
+		pendingValidators[i] = &keys.ValidatorKey{
+			BandersnatchPublicKey: bandersnatch.PublicKey{byte(i)},
+			Ed25519PublicKey:      generateDummyKey(),
+		}
+	}
+
+	epochMarker := newEpochMarker(entropyPool, pendingValidators)
+
+	require.Equal(t, entropyPool[0], epochMarker.Entropies.Next)
+	require.Equal(t, entropyPool[1], epochMarker.Entropies.Current)
+	for i, validator := range epochMarker.Validators {
+		require.Equal(t, pendingValidators[i].BandersnatchPublicKey, validator.Bandersnatch)
+		require.Equal(t, pendingValidators[i].Ed25519PublicKey, validator.Ed25519)
+	}
+}
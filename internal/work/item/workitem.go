@@ -1,3 +1,71 @@
 package workitem
 
-type Item struct{}
+import (
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+// workPackageHashFlag marks an imported segment identified by the hash of its exporting work package, 2^15.
+const workPackageHashFlag = 1 << 15
+
+// (14.3) I ≡ (s ∈ NS, h ∈ H, y ∈ Y, g ∈ NG, a ∈ NG, e ∈ N, i ∈ ⟦(H ∪ (H⊞), N)⟧, x ∈ ⟦(H, N)⟧)
+type Item struct {
+	ServiceId          service.ServiceId // s ∈ NS: The service to which the work item relates.
+	CodeHash           common.Hash       // h ∈ H: The code hash of the service at the time of reporting.
+	Payload            []byte            // y ∈ Y: The payload blob.
+	RefineGasLimit     service.Gas       // g ∈ NG: The gas limit for the Refine invocation.
+	AccumulateGasLimit service.Gas       // a ∈ NG: The gas limit for the Accumulate invocation.
+	ImportSegments     []ImportSegment   // i ∈ ⟦(H ∪ (H⊞), N)⟧: The imported segments.
+	Extrinsics         []ExtrinsicRef    // x ∈ ⟦(H, N)⟧: The hashes and lengths of the extrinsic data.
+	ExportCount        uint16            // e ∈ N: The number of segments exported by the Refine invocation.
+}
+
+// (h ∈ H ∪ (H⊞), i ∈ N): A segment exported by an earlier work package.
+type ImportSegment struct {
+	TreeRoot          common.Hash // h: The segment root, or the hash of the exporting work package if IsWorkPackageHash.
+	IsWorkPackageHash bool        // h ∈ H⊞: The tree root is to be looked up by the work package hash.
+	Index             uint16      // i: The index of the segment among the exports.
+}
+
+// (h ∈ H, l ∈ N): An extrinsic data blob introduced with the work package.
+type ExtrinsicRef struct {
+	Hash   common.Hash // h: The hash of the blob.
+	Length uint32      // l: The length of the blob in octets.
+}
+
+// TotalExtrinsicSize returns Σ l over the extrinsic data of the item.
+func (i *Item) TotalExtrinsicSize() uint64 {
+	var size uint64
+	for _, extrinsic := range i.Extrinsics {
+		size += uint64(extrinsic.Length)
+	}
+	return size
+}
+
+// Gray Paper (C.29) E(h) ⌢ E2(i), or E(h) ⌢ E2(i + 2^15) if h ∈ H⊞.
+func (s ImportSegment) MarshalJAM() ([]byte, error) {
+	index := uint64(s.Index)
+	if s.IsWorkPackageHash {
+		index += workPackageHashFlag
+	}
+	return append(append([]byte{}, s.TreeRoot[:]...), codec.EncodeUint(index, 2)...), nil
+}
+
+func (s *ImportSegment) UnmarshalJAM(d *codec.Decoder) error {
+	root, err := d.ReadBytes(common.HashLength)
+	if err != nil {
+		return err
+	}
+	index, err := d.DecodeUint(2)
+	if err != nil {
+		return err
+	}
+
+	*s = ImportSegment{
+		TreeRoot:          common.BytesToHash(root),
+		IsWorkPackageHash: index&workPackageHashFlag != 0,
+		Index:             uint16(index &^ workPackageHashFlag),
+	}
+	return nil
+}
@@ -1,5 +1,9 @@
 package workpackage
 
 const (
-	MaxWorkItemsInPackage = 4 // I = 4: The maximum amount of work items in a package.
+	MaxWorkItemsInPackage = 16             // I = 16: The maximum amount of work items in a package.
+	MaxImportSegments     = 3072           // WM = 3,072: The maximum number of imports in a work-package.
+	MaxExportSegments     = 3072           // WX = 3,072: The maximum number of exports in a work-package.
+	MaxExtrinsics         = 128            // T = 128: The maximum number of extrinsics in a work-package.
+	MaxPackageSize        = 12 * (1 << 20) // WB = 12*2^20: The maximum size of an encoded work-package together with its extrinsic data and import implications, in octets.
 )
@@ -0,0 +1,13 @@
+package workpackage
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidWorkItemCount  = errors.New("invalid work item count")
+	ErrTooManyImportSegments = errors.New("too many import segments")
+	ErrTooManyExportSegments = errors.New("too many export segments")
+	ErrTooManyExtrinsics     = errors.New("too many extrinsics")
+	ErrPackageTooLarge       = errors.New("work package too large")
+	ErrRefineGasExceeded     = errors.New("refine gas limit exceeded")
+	ErrAccumulateGasExceeded = errors.New("accumulate gas limit exceeded")
+)
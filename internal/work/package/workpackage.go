@@ -1,17 +1,91 @@
 package workpackage
 
 import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/service"
 	"github.com/shunsukew/gojam/internal/work"
 	workitem "github.com/shunsukew/gojam/internal/work/item"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
 )
 
 // (14.2) P≡⎧ j ∈ Y, h ∈ Ns , u ∈ H, p ∈ Y, x ∈ X, w ∈ ⟦I⟧1:I ⎫
 type Package struct {
 	AuthToken         []byte                  // j ∈ Y
-	ServiceIndex      uint32                  // h ∈ Ns
+	ServiceIndex      service.ServiceId       // h ∈ Ns
 	AuthCodeHash      common.Hash             // u ∈ H
 	AuthParam         []byte                  // p ∈ Y
 	RefinementContext *work.RefinementContext // x ∈ X
 	WorkItems         []*workitem.Item        // w ∈ ⟦I⟧1:I
 }
+
+// Hash returns the work package hash H(E(p)).
+func (p *Package) Hash() (common.Hash, error) {
+	encoded, err := codec.Encode(p)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return blake2b.Sum256(encoded), nil
+}
+
+// AuthorizerHash returns the hash of the authorizer code and its parameterization, H(p_u ⌢ p_p).
+func (p *Package) AuthorizerHash() common.Hash {
+	return blake2b.Sum256(append(append([]byte{}, p.AuthCodeHash[:]...), p.AuthParam...))
+}
+
+// Validate checks the limits on the work items of the package, Gray Paper (14.4) ~ (14.6), (14.9).
+// The returned error tells which limit is exceeded, and by how much.
+func (p *Package) Validate() error {
+	// w ∈ ⟦I⟧1:I
+	if len(p.WorkItems) < 1 || len(p.WorkItems) > MaxWorkItemsInPackage {
+		return errors.WithMessagef(ErrInvalidWorkItemCount, "%d work items, must be between 1 and %d", len(p.WorkItems), MaxWorkItemsInPackage)
+	}
+
+	var (
+		numOfImports, numOfExports, numOfExtrinsics uint64
+		refineGas, accumulateGas                    service.Gas
+	)
+	size := uint64(len(p.AuthToken) + len(p.AuthParam))
+	for i, item := range p.WorkItems {
+		if item == nil {
+			return errors.WithMessagef(ErrInvalidWorkItemCount, "work item %d is missing", i)
+		}
+
+		numOfImports += uint64(len(item.ImportSegments))
+		numOfExports += uint64(item.ExportCount)
+		numOfExtrinsics += uint64(len(item.Extrinsics))
+
+		// |w_y| + |w_i|·WG + Σ_{(h, l) ∈ w_x} l
+		size += uint64(len(item.Payload)) + uint64(len(item.ImportSegments))*workreport.SegmentSize + item.TotalExtrinsicSize()
+
+		// The sums are checked item by item, so that they can not overflow.
+		refineGas += item.RefineGasLimit
+		if item.RefineGasLimit >= service.WorkPackageRefineGasLimit || refineGas >= service.WorkPackageRefineGasLimit {
+			return errors.WithMessagef(ErrRefineGasExceeded, "refine gas of work items up to %d must be less than %d", i, service.WorkPackageRefineGasLimit)
+		}
+		accumulateGas += item.AccumulateGasLimit
+		if item.AccumulateGasLimit >= service.WorkReportAccumulationGasLimit || accumulateGas >= service.WorkReportAccumulationGasLimit {
+			return errors.WithMessagef(ErrAccumulateGasExceeded, "accumulate gas of work items up to %d must be less than %d", i, service.WorkReportAccumulationGasLimit)
+		}
+	}
+
+	// (14.4) Σ |w_i| ≤ WM, Σ w_e ≤ WX, Σ |w_x| ≤ T
+	if numOfImports > MaxImportSegments {
+		return errors.WithMessagef(ErrTooManyImportSegments, "%d import segments, at most %d", numOfImports, MaxImportSegments)
+	}
+	if numOfExports > MaxExportSegments {
+		return errors.WithMessagef(ErrTooManyExportSegments, "%d export segments, at most %d", numOfExports, MaxExportSegments)
+	}
+	if numOfExtrinsics > MaxExtrinsics {
+		return errors.WithMessagef(ErrTooManyExtrinsics, "%d extrinsics, at most %d", numOfExtrinsics, MaxExtrinsics)
+	}
+
+	// (14.6) |p_j| + |p_p| + Σ_{w ∈ p_w} S(w) ≤ WB
+	if size > MaxPackageSize {
+		return errors.WithMessagef(ErrPackageTooLarge, "%d octets, at most %d", size, MaxPackageSize)
+	}
+
+	return nil
+}
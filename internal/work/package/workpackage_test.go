@@ -0,0 +1,156 @@
+package workpackage
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/internal/work"
+	workitem "github.com/shunsukew/gojam/internal/work/item"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func newTestPackage() *Package {
+	return &Package{
+		AuthToken:         []byte{1, 2},
+		ServiceIndex:      7,
+		AuthCodeHash:      common.Hash{3},
+		AuthParam:         []byte{4},
+		RefinementContext: &work.RefinementContext{PreRequisiteWorkPackageHashes: []common.Hash{}},
+		WorkItems: []*workitem.Item{{
+			ServiceId:          8,
+			CodeHash:           common.Hash{5},
+			Payload:            []byte{6},
+			RefineGasLimit:     100,
+			AccumulateGasLimit: 200,
+			ImportSegments: []workitem.ImportSegment{
+				{TreeRoot: common.Hash{9}, Index: 1},
+				{TreeRoot: common.Hash{10}, IsWorkPackageHash: true, Index: 2},
+			},
+			Extrinsics:  []workitem.ExtrinsicRef{{Hash: common.Hash{11}, Length: 12}},
+			ExportCount: 3,
+		}},
+	}
+}
+
+func TestPackageEncodeDecode(t *testing.T) {
+	p := newTestPackage()
+
+	encoded, err := codec.Encode(p)
+	require.NoError(t, err)
+
+	// ↕j ⌢ E4(h) ⌢ u ⌢ ↕p ⌢ x ⌢ ↕w
+	contextSize := 4*common.HashLength + 4 + 1
+	itemSize := 4 + common.HashLength + 2 + 8 + 8 + 1 + 2*(common.HashLength+2) + 1 + common.HashLength + 4 + 2
+	require.Len(t, encoded, 3+4+common.HashLength+2+contextSize+1+itemSize)
+
+	// The import of a segment exported by a work package is flagged with 2^15 on its index.
+	importsOffset := len(encoded) - itemSize + 4 + common.HashLength + 2 + 8 + 8 + 1
+	require.Equal(t, []byte{1, 0}, encoded[importsOffset+common.HashLength:importsOffset+common.HashLength+2])
+	require.Equal(t, []byte{2, 0x80}, encoded[importsOffset+2*common.HashLength+2:importsOffset+2*common.HashLength+4])
+
+	var decoded Package
+	err = codec.Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, p, &decoded)
+
+	hash, err := p.Hash()
+	require.NoError(t, err)
+	require.Equal(t, common.Hash(blake2b.Sum256(encoded)), hash)
+}
+
+func TestPackageValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(p *Package)
+		expectedErr error
+	}{
+		{
+			name:   "Valid",
+			modify: func(p *Package) {},
+		},
+		{
+			name:        "No work items",
+			modify:      func(p *Package) { p.WorkItems = nil },
+			expectedErr: ErrInvalidWorkItemCount,
+		},
+		{
+			name: "Too many work items",
+			modify: func(p *Package) {
+				for len(p.WorkItems) <= MaxWorkItemsInPackage {
+					p.WorkItems = append(p.WorkItems, &workitem.Item{})
+				}
+			},
+			expectedErr: ErrInvalidWorkItemCount,
+		},
+		{
+			name:        "Too many imports",
+			modify:      func(p *Package) { p.WorkItems[0].ImportSegments = make([]workitem.ImportSegment, MaxImportSegments+1) },
+			expectedErr: ErrTooManyImportSegments,
+		},
+		{
+			name: "Too many exports",
+			modify: func(p *Package) {
+				p.WorkItems[0].ExportCount = MaxExportSegments
+				p.WorkItems = append(p.WorkItems, &workitem.Item{ExportCount: 1})
+			},
+			expectedErr: ErrTooManyExportSegments,
+		},
+		{
+			name: "Exports at the limit",
+			modify: func(p *Package) {
+				p.WorkItems[0].ExportCount = MaxExportSegments - 1
+				p.WorkItems = append(p.WorkItems, &workitem.Item{ExportCount: 1})
+			},
+		},
+		{
+			name:        "Too many extrinsics",
+			modify:      func(p *Package) { p.WorkItems[0].Extrinsics = make([]workitem.ExtrinsicRef, MaxExtrinsics+1) },
+			expectedErr: ErrTooManyExtrinsics,
+		},
+		{
+			name:        "Too large",
+			modify:      func(p *Package) { p.WorkItems[0].Extrinsics[0].Length = MaxPackageSize },
+			expectedErr: ErrPackageTooLarge,
+		},
+		{
+			name: "Refine gas below the limit",
+			modify: func(p *Package) {
+				p.WorkItems = append(p.WorkItems, &workitem.Item{RefineGasLimit: service.WorkPackageRefineGasLimit - 101})
+			},
+		},
+		{
+			name: "Refine gas at the limit",
+			modify: func(p *Package) {
+				p.WorkItems = append(p.WorkItems, &workitem.Item{RefineGasLimit: service.WorkPackageRefineGasLimit - 100})
+			},
+			expectedErr: ErrRefineGasExceeded,
+		},
+		{
+			name:        "Accumulate gas",
+			modify:      func(p *Package) { p.WorkItems[0].AccumulateGasLimit = service.WorkReportAccumulationGasLimit },
+			expectedErr: ErrAccumulateGasExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestPackage()
+			test.modify(p)
+
+			err := p.Validate()
+			if test.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestAuthorizerHash(t *testing.T) {
+	p := newTestPackage()
+	require.Equal(t, common.Hash(blake2b.Sum256(append(p.AuthCodeHash[:], p.AuthParam...))), p.AuthorizerHash())
+}
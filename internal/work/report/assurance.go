@@ -16,8 +16,8 @@ type Assurances []*Assurance
 type Assurance struct {
 	AnchorParentHash         common.Hash             // Anchor of the assurance
 	WorkReportAvailabilities [common.NumOfCores]bool // bitstring of work report availability assurances, one bit per core
-	ValidatorIndex           uint32                  // Index of the validator in the assurance
-	Signature                []byte                  // Signature of the validator ed25519 key
+	ValidatorIndex           uint32                  `codec:"size=2"`    // Index of the validator in the assurance
+	Signature                []byte                  `codec:"length=64"` // Signature of the validator ed25519 key
 }
 
 func (assurances Assurances) validate(
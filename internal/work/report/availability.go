@@ -0,0 +1,99 @@
+package workreport
+
+import (
+	"math/bits"
+	"slices"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/erasure"
+	"github.com/shunsukew/gojam/pkg/merkle"
+)
+
+const (
+	SegmentsPerPage  = 64 // The number of segments whose leaf hashes are justified by one page of the paged proofs.
+	pageDepthInProof = 6  // log2(SegmentsPerPage)
+)
+
+// G ≡ Y_WG: An exported segment.
+type Segment [SegmentSize]byte
+
+// NewAvailabilitySpecification builds the availability specification of the work-package bundle b and its exported segments s.
+// Gray Paper (14.16)
+// A(h, b, s) ≡ (h, l: |b|, u: M_B([x̂ ⌢ ŷ ∣ (x̂, ŷ) <− T[b♣, s♣]]), e: M(s), n: |s|)
+// where b♣ = H#(C_⌈|b|/WE⌉(P_WE(b))) and s♣ = M_B#(T C#(s ⌢ P(s)))
+func NewAvailabilitySpecification(workPackageHash common.Hash, bundle []byte, segments []Segment) *AvailabilitySpecification {
+	bundleChunks := erasure.Encode(bundle)
+
+	// The chunks of each segment and paged proof, transposed to the chunks held by each validator.
+	segmentChunks := make([][][]byte, erasure.NumOfChunks)
+	for _, segment := range slices.Concat(segments, PagedProofs(segments)) {
+		for i, chunk := range erasure.Encode(segment[:]) {
+			segmentChunks[i] = append(segmentChunks[i], chunk)
+		}
+	}
+
+	leaves := make([][]byte, erasure.NumOfChunks)
+	for i := range leaves {
+		bundleChunkHash := merkle.Blake2b(bundleChunks[i])
+		segmentChunksRoot := merkle.Binary(segmentChunks[i], merkle.Blake2b)
+		leaves[i] = append(bundleChunkHash[:], segmentChunksRoot[:]...)
+	}
+
+	return &AvailabilitySpecification{
+		WorkPackageHash:  workPackageHash,
+		WorkBundleLength: uint32(len(bundle)),
+		ErasureRoot:      merkle.Binary(leaves, merkle.Blake2b),
+		SegmentRoot:      SegmentRoot(segments),
+		SegmentCount:     uint(len(segments)),
+	}
+}
+
+// SegmentRoot is the root of the constant-depth Merkle tree over the exported segments, M(s).
+func SegmentRoot(segments []Segment) common.Hash {
+	return merkle.ConstantDepth(segmentBlobs(segments), merkle.Blake2b)
+}
+
+// PagedProofs returns a page for every 64 segments, justifying their leaf hashes against the segment root.
+// Gray Paper (14.10)
+// P(s) ≡ [P_WG(E(J_6(s, i)) ⌢ E(L_6(s, i))) ∣ i <− 64 N_⌈|s|/64⌉]
+func PagedProofs(segments []Segment) []Segment {
+	blobs := segmentBlobs(segments)
+	justifications := merkle.PagedJustifications(blobs, pageDepthInProof, merkle.Blake2b)
+
+	pages := make([]Segment, len(justifications))
+	for p := range pages {
+		hashes := append(justifications[p], merkle.Leaves(blobs, p*SegmentsPerPage, pageDepthInProof, merkle.Blake2b)...)
+
+		// The page is padded with zeros to WG octets.
+		offset := 0
+		for _, hash := range hashes {
+			offset += copy(pages[p][offset:], hash[:])
+		}
+	}
+	return pages
+}
+
+// Justification returns the justification J_0(s, i) of the segment i among the exported segments s of the given count,
+// from the page of the paged proofs P(s) containing the segment.
+func Justification(page Segment, segmentCount int, i int) []common.Hash {
+	depth := bits.Len(uint(max(1, segmentCount) - 1))
+	subtreeDepth := min(pageDepthInProof, depth)
+	start := i >> pageDepthInProof << pageDepthInProof
+
+	// The page holds J_6(s, i) followed by L_6(s, i).
+	hashes := make([]common.Hash, depth-subtreeDepth+min(SegmentsPerPage, segmentCount-start))
+	for j := range hashes {
+		copy(hashes[j][:], page[j*common.HashLength:])
+	}
+	var justification []common.Hash
+	justification = append(justification, hashes[:depth-subtreeDepth]...)
+	return append(justification, merkle.SubtreeJustification(hashes[depth-subtreeDepth:], i, subtreeDepth, merkle.Blake2b)...)
+}
+
+func segmentBlobs(segments []Segment) [][]byte {
+	blobs := make([][]byte, len(segments))
+	for i := range segments {
+		blobs[i] = segments[i][:]
+	}
+	return blobs
+}
@@ -0,0 +1,85 @@
+package workreport
+
+import (
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/erasure"
+	"github.com/shunsukew/gojam/pkg/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagedProofs(t *testing.T) {
+	segments := make([]Segment, SegmentsPerPage+2)
+	for i := range segments {
+		segments[i][0] = byte(i)
+	}
+	blobs := segmentBlobs(segments)
+
+	pages := PagedProofs(segments)
+	require.Len(t, pages, 2)
+
+	// 66 segments make a tree of depth 7, so each page is justified by one sibling hash followed by its leaf hashes.
+	for p, page := range pages {
+		justification := merkle.Justification(blobs, p*SegmentsPerPage, pageDepthInProof, merkle.Blake2b)
+		require.Len(t, justification, 1)
+		require.Equal(t, justification[0][:], page[:common.HashLength])
+
+		leaves := merkle.Leaves(blobs, p*SegmentsPerPage, pageDepthInProof, merkle.Blake2b)
+		for i, leaf := range leaves {
+			offset := (1 + i) * common.HashLength
+			require.Equal(t, leaf[:], page[offset:offset+common.HashLength])
+		}
+		require.Equal(t, make([]byte, SegmentSize-(1+len(leaves))*common.HashLength), page[(1+len(leaves))*common.HashLength:])
+	}
+
+	require.Empty(t, PagedProofs(nil))
+}
+
+func TestJustification(t *testing.T) {
+	for _, count := range []int{1, 3, SegmentsPerPage, SegmentsPerPage + 2, 3 * SegmentsPerPage} {
+		segments := make([]Segment, count)
+		for i := range segments {
+			segments[i][0], segments[i][1] = byte(i), byte(i>>8)
+		}
+		blobs := segmentBlobs(segments)
+		pages := PagedProofs(segments)
+
+		for i := range segments {
+			justification := Justification(pages[i/SegmentsPerPage], count, i)
+			require.Equal(t, merkle.Justification(blobs, i, 0, merkle.Blake2b), justification, "segment %d of %d", i, count)
+			require.Equal(t, SegmentRoot(segments), merkle.JustifiedRoot(blobs[i], i, justification, merkle.Blake2b))
+		}
+	}
+}
+
+func TestNewAvailabilitySpecification(t *testing.T) {
+	bundle := []byte("work package bundle")
+
+	t.Run("without segments", func(t *testing.T) {
+		spec := NewAvailabilitySpecification(common.Hash{1}, bundle, nil)
+
+		leaves := make([][]byte, erasure.NumOfChunks)
+		for i, chunk := range erasure.Encode(bundle) {
+			hash := merkle.Blake2b(chunk)
+			leaves[i] = append(hash[:], make([]byte, common.HashLength)...)
+		}
+
+		require.Equal(t, &AvailabilitySpecification{
+			WorkPackageHash:  common.Hash{1},
+			WorkBundleLength: uint32(len(bundle)),
+			ErasureRoot:      merkle.Binary(leaves, merkle.Blake2b),
+			SegmentRoot:      common.Hash{},
+		}, spec)
+	})
+
+	t.Run("with segments", func(t *testing.T) {
+		segments := []Segment{{1}, {2}, {3}}
+		spec := NewAvailabilitySpecification(common.Hash{1}, bundle, segments)
+
+		require.Equal(t, SegmentRoot(segments), spec.SegmentRoot)
+		require.Equal(t, merkle.ConstantDepth(segmentBlobs(segments), merkle.Blake2b), spec.SegmentRoot)
+		require.Equal(t, uint(3), spec.SegmentCount)
+		require.NotEqual(t, NewAvailabilitySpecification(common.Hash{1}, bundle, nil).ErasureRoot, spec.ErasureRoot)
+	})
+}
@@ -5,6 +5,7 @@ const (
 	MaxCredentialsInGuarantee  = 3
 	MaxCodeSize                = 4000000        // WC = 4,000,000: The maximum size of service code in octets.
 	MaxWorkReportOutputsSize   = 48 * (1 << 10) // WR = 48*2^10: The maximum total size of all output blobs (sum of work report output + all work results' outputs) in a work-report, in octets.
+	SegmentSize                = 4104           // WG = WP·WE = 4104: The size of an exported segment in octets.
 
 	PendingWorkReportTimeout = 5
 )
@@ -7,9 +7,11 @@ var (
 
 	ErrInvalidGuarantee  = errors.New("invalid guarantee")
 	ErrInvalidCredential = errors.New("invalid credential")
+	ErrBadSignature      = errors.New("bad signature")
 
 	ErrTooManyGuarantees = errors.New("too many guarantees, must be less than or equal to number of cores")
 	ErrInvalidGuarantees = errors.New("invalid guarantees")
 
-	ErrInvalidWorkReport = errors.New("invalid work report")
+	ErrInvalidWorkReport    = errors.New("invalid work report")
+	ErrDuplicateWorkPackage = errors.New("duplicate work package")
 )
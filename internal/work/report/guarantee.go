@@ -7,6 +7,7 @@ import (
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/validator/keys"
 	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
 	"github.com/shunsukew/gojam/pkg/shuffle"
 )
 
@@ -39,11 +40,17 @@ type Guarantee struct {
 }
 
 type Credential struct {
-	ValidatorIndex uint32
-	Signature      []byte // 𝔼
+	ValidatorIndex uint32 `codec:"size=2"`
+	Signature      []byte `codec:"length=64"` // 𝔼
 }
 
-func (guarantee *Guarantee) checkGuaranteedWorkReport(timeSlot jamtime.TimeSlot, guarantorAssignments *GuarantorAssignments, guarantorKeys *[common.NumOfValidators]*keys.ValidatorKey) ([]ed25519.PublicKey, error) {
+// Credential signatures are not verified here but collected, so that all signatures in the extrinsic are verified at once.
+func (guarantee *Guarantee) checkGuaranteedWorkReport(
+	timeSlot jamtime.TimeSlot,
+	guarantorAssignments *GuarantorAssignments,
+	guarantorKeys *[common.NumOfValidators]*keys.ValidatorKey,
+	signatures *crypto.Ed25519Signatures,
+) ([]ed25519.PublicKey, error) {
 	// guarantee timeslot must be between start of prev guarantor assignment rotation period and current timeslot.
 	startOfLastRotationPeriod := (timeSlot/jamtime.GuarantorRotationPeriod - 1) * jamtime.GuarantorRotationPeriod
 
@@ -66,6 +73,13 @@ func (guarantee *Guarantee) checkGuaranteedWorkReport(timeSlot jamtime.TimeSlot,
 		}
 	}
 
+	// (11.26) ∀(v, s) ∈ ga ∶ s ∈ E_(k_v)e⟨XG ⌢ H(E(gw))⟩
+	workReportHash, err := guarantee.WorkReport.Hash()
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidGuarantee, "failed to hash work report: %v", err)
+	}
+	message := append([]byte(crypto.JamGuaranteeStatement), workReportHash[:]...)
+
 	reporters := make([]ed25519.PublicKey, 0, MaxCredentialsInGuarantee)
 	for _, credential := range guarantee.Credentials {
 		if credential.ValidatorIndex >= common.NumOfValidators {
@@ -83,10 +97,7 @@ func (guarantee *Guarantee) checkGuaranteedWorkReport(timeSlot jamtime.TimeSlot,
 			)
 		}
 
-		// TODO: Check signature
-		// 1. encode work report
-		// 2. concat with statement and hash
-		// 3. verify signature with validator public key
+		signatures.Add(guarantorKeys[credential.ValidatorIndex].Ed25519PublicKey, message, credential.Signature)
 
 		reporters = append(reporters, guarantorKeys[credential.ValidatorIndex].Ed25519PublicKey)
 	}
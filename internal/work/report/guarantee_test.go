@@ -0,0 +1,100 @@
+package workreport
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/history"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/internal/work"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGuaranteedWorkReportSignatures(t *testing.T) {
+	timeSlot := jamtime.TimeSlot(2 * jamtime.GuarantorRotationPeriod)
+	guarantorAssignments := &GuarantorAssignments{}
+
+	guarantorKeys := &[common.NumOfValidators]*keys.ValidatorKey{}
+	privateKeys := make([]ed25519.PrivateKey, common.NumOfValidators)
+	for i := range guarantorKeys {
+		publicKey, privateKey, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		guarantorKeys[i] = &keys.ValidatorKey{Ed25519PublicKey: publicKey}
+		privateKeys[i] = privateKey
+	}
+
+	workReport := &WorkReport{
+		AvailabilitySpecification: &AvailabilitySpecification{WorkPackageHash: common.Hash{1}},
+		RefinementContext:         &work.RefinementContext{},
+		WorkResults:               []*WorkResult{{ExecResult: &ExecResult{Output: []byte{}}}},
+	}
+	workReportHash, err := workReport.Hash()
+	require.NoError(t, err)
+	message := append([]byte(crypto.JamGuaranteeStatement), workReportHash[:]...)
+
+	guarantee := &Guarantee{
+		WorkReport: workReport,
+		Timeslot:   timeSlot,
+		Credentials: []*Credential{
+			{ValidatorIndex: 0, Signature: ed25519.Sign(privateKeys[0], message)},
+			{ValidatorIndex: 1, Signature: ed25519.Sign(privateKeys[1], message)},
+		},
+	}
+
+	signatures := crypto.NewEd25519Signatures(MaxCredentialsInGuarantee)
+	reporters, err := guarantee.checkGuaranteedWorkReport(timeSlot, guarantorAssignments, guarantorKeys, signatures)
+	require.NoError(t, err)
+	require.Equal(t, []ed25519.PublicKey{guarantorKeys[0].Ed25519PublicKey, guarantorKeys[1].Ed25519PublicKey}, reporters)
+	require.Equal(t, 2, signatures.Len())
+	require.Equal(t, -1, signatures.Verify())
+
+	// Signed by a validator other than the credential's one.
+	guarantee.Credentials[1].Signature = ed25519.Sign(privateKeys[2], message)
+	signatures = crypto.NewEd25519Signatures(MaxCredentialsInGuarantee)
+	_, err = guarantee.checkGuaranteedWorkReport(timeSlot, guarantorAssignments, guarantorKeys, signatures)
+	require.NoError(t, err)
+	require.Equal(t, 1, signatures.Verify())
+
+	// The signed message covers the refine load and the authorization gas of the 0.6.x encoding.
+	guarantee.Credentials[1].Signature = ed25519.Sign(privateKeys[1], message)
+	for _, tamper := range []func(*WorkReport){
+		func(w *WorkReport) { w.WorkResults[0].RefineLoad.Exports = 1 },
+		func(w *WorkReport) { w.AuthGasUsed = 1 },
+	} {
+		tampered := *workReport
+		tampered.WorkResults = []*WorkResult{{ExecResult: &ExecResult{Output: []byte{}}}}
+		tamper(&tampered)
+		guarantee.WorkReport = &tampered
+
+		signatures = crypto.NewEd25519Signatures(MaxCredentialsInGuarantee)
+		_, err = guarantee.checkGuaranteedWorkReport(timeSlot, guarantorAssignments, guarantorKeys, signatures)
+		require.NoError(t, err)
+		require.Equal(t, 0, signatures.Verify())
+	}
+}
+
+type workPackageSet map[common.Hash]struct{}
+
+func (s workPackageSet) ContainsWorkPackage(workPackageHash common.Hash) bool {
+	_, ok := s[workPackageHash]
+	return ok
+}
+
+func TestEnsureNewWorkPackages(t *testing.T) {
+	recentBlocks := &history.RecentHistory{{WorkPackageHashes: map[common.Hash]common.Hash{{1}: {}}}}
+	queued := workPackageSet{{2}: {}}
+	accumulated := workPackageSet{{3}: {}}
+	pending := &PendingWorkReports{}
+	pending[0] = &PendingWorkReport{WorkReport: &WorkReport{AvailabilitySpecification: &AvailabilitySpecification{WorkPackageHash: common.Hash{4}}}}
+
+	for _, reported := range []common.Hash{{1}, {2}, {3}, {4}} {
+		err := pending.ensureNewWorkPackages(map[common.Hash]struct{}{reported: {}}, recentBlocks, queued, accumulated)
+		require.ErrorIs(t, err, ErrDuplicateWorkPackage)
+	}
+
+	err := pending.ensureNewWorkPackages(map[common.Hash]struct{}{{5}: {}}, recentBlocks, queued, accumulated)
+	require.NoError(t, err)
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/shunsukew/gojam/internal/service"
 	"github.com/shunsukew/gojam/internal/work"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
 )
 
 const (
@@ -78,15 +80,16 @@ func (wr *WorkReports) ensureSegmentRoots(recentSegmentRootLookups map[common.Ha
 	return nil
 }
 
-// (11.2) W ≡ (s ∈ S, x ∈ X, c ∈ NC, a ∈ H, o ∈ Y, l ∈ D⟨H→H⟩, r ∈ ⟦L⟧1:I)
+// (11.2) W ≡ (s ∈ S, x ∈ X, c ∈ NC, a ∈ H, o ∈ Y, l ∈ D⟨H→H⟩, r ∈ ⟦L⟧1:I, g ∈ NG)
 type WorkReport struct {
 	AvailabilitySpecification *AvailabilitySpecification  // s ∈ S
 	RefinementContext         *work.RefinementContext     // x ∈ X
-	CoreIndex                 uint32                      // c ∈ NC
+	CoreIndex                 uint32                      `codec:"size=2"` // c ∈ NC
 	AuthorizerHash            common.Hash                 // a ∈ H
 	Output                    []byte                      // o ∈ Y
 	SegmentRootLookup         map[common.Hash]common.Hash // l ∈ D⟨H→H⟩ work package hash to segment root
 	WorkResults               []*WorkResult               // r ∈ ⟦L⟧1:I cannot be empty
+	AuthGasUsed               service.Gas                 `codec:"compact"` // g ∈ NG: The gas used by the Is-Authorized invocation.
 }
 
 // (11.5) S ≡ [ h ∈ H, l ∈ NL, u ∈ H, e ∈ H, n ∈ N ]
@@ -95,25 +98,83 @@ type AvailabilitySpecification struct {
 	WorkBundleLength uint32      // l ∈ NL
 	ErasureRoot      common.Hash // u ∈ H
 	SegmentRoot      common.Hash // e ∈ H
-	SegmentCount     uint        // n ∈ N
+	SegmentCount     uint        `codec:"size=2"` // n ∈ N
 }
 
-// (11.6) L ≡ (s ∈ NS , c ∈ H, l ∈ H, g ∈ NG , o ∈ Y ∪ J)
+// (11.6) L ≡ (s ∈ NS , c ∈ H, l ∈ H, g ∈ NG , o ∈ Y ∪ J, (u, i, x, z, e))
 type WorkResult struct {
 	ServiceId       service.ServiceId // s ∈ NS
 	ServiceCodeHash common.Hash       // c ∈ H
 	PayloadHash     common.Hash       // l ∈ H
 	Gas             service.Gas       // g ∈ NG
 	ExecResult      *ExecResult       // o ∈ Y ∪ J
+	RefineLoad      RefineLoad        // (u, i, x, z, e)
+}
+
+// The resources consumed by the refinement of a work item, reported for the activity statistics.
+type RefineLoad struct {
+	GasUsed        service.Gas `codec:"compact"` // u ∈ NG: The gas used by the Refine invocation.
+	Imports        uint32      `codec:"compact"` // i ∈ N: The number of imported segments.
+	ExtrinsicCount uint32      `codec:"compact"` // x ∈ N: The number of extrinsic items.
+	ExtrinsicSize  uint32      `codec:"compact"` // z ∈ N: The total size of the extrinsic items in octets.
+	Exports        uint32      `codec:"compact"` // e ∈ N: The number of exported segments.
 }
 
 type ExecError int
 
+// Output is set when the execution succeeded, otherwise Error describes the failure.
 type ExecResult struct {
 	Output []byte    // Y
 	Error  ExecError // J ∈ {∞, ☇, ⊚, BAD, BIG}
 }
 
+// Gray Paper (C.29)
+// O(o ∈ Y ∪ J) ≡ 0 ⌢ ↕o if o ∈ Y, 1 if o = ∞, 2 if o = ☇, 3 if o = ⊚, 4 if o = BAD, 5 if o = BIG
+func (r ExecResult) MarshalJAM() ([]byte, error) {
+	if r.Output != nil {
+		return append([]byte{0}, codec.EncodeLengthPrefixed(r.Output)...), nil
+	}
+
+	if r.Error < OutOfGas || r.Error > CodeTooBig {
+		return nil, errors.Errorf("unknown exec error %d", r.Error)
+	}
+
+	return []byte{byte(r.Error) + 1}, nil
+}
+
+func (r *ExecResult) UnmarshalJAM(d *codec.Decoder) error {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if kind == 0 {
+		output, err := d.DecodeLengthPrefixed()
+		if err != nil {
+			return err
+		}
+		*r = ExecResult{Output: output}
+		return nil
+	}
+
+	if kind > byte(CodeTooBig)+1 {
+		return errors.Errorf("unknown exec result kind %d", kind)
+	}
+
+	*r = ExecResult{Error: ExecError(kind - 1)}
+	return nil
+}
+
+// Hash returns the work report hash H(E(w)).
+func (wr *WorkReport) Hash() (common.Hash, error) {
+	encoded, err := codec.Encode(wr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return blake2b.Sum256(encoded), nil
+}
+
 func (wr *WorkReport) outputSize() int {
 	if wr == nil {
 		return 0
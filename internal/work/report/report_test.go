@@ -0,0 +1,104 @@
+package workreport
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/work"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecResultEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   ExecResult
+		expected []byte
+	}{
+		{name: "ok", result: ExecResult{Output: []byte{0xaa, 0xbb}}, expected: []byte{0, 2, 0xaa, 0xbb}},
+		{name: "ok empty", result: ExecResult{Output: []byte{}}, expected: []byte{0, 0}},
+		{name: "out of gas", result: ExecResult{Error: OutOfGas}, expected: []byte{1}},
+		{name: "panic", result: ExecResult{Error: Panic}, expected: []byte{2}},
+		{name: "report invalid", result: ExecResult{Error: ReportInvalid}, expected: []byte{3}},
+		{name: "service unavailable", result: ExecResult{Error: ServiceUnavailable}, expected: []byte{4}},
+		{name: "code too big", result: ExecResult{Error: CodeTooBig}, expected: []byte{5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := codec.Encode(test.result)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, encoded)
+
+			var decoded ExecResult
+			err = codec.Decode(encoded, &decoded)
+			require.NoError(t, err)
+			require.Equal(t, test.result, decoded)
+		})
+	}
+
+	var decoded ExecResult
+	require.Error(t, codec.Decode([]byte{6}, &decoded))
+}
+
+func TestGuaranteeEncodeDecode(t *testing.T) {
+	guarantee := &Guarantee{
+		WorkReport: &WorkReport{
+			AvailabilitySpecification: &AvailabilitySpecification{
+				WorkPackageHash:  common.Hash{1},
+				WorkBundleLength: 1 << 20,
+				ErasureRoot:      common.Hash{2},
+				SegmentRoot:      common.Hash{3},
+				SegmentCount:     3,
+			},
+			RefinementContext: &work.RefinementContext{
+				AnchorHeaderHash:              common.Hash{4},
+				AnchorStateRoot:               common.Hash{5},
+				AnchorBeefyRoot:               common.Hash{6},
+				LookupAnchorHeaderHash:        common.Hash{7},
+				LookupAnchorTimeSlot:          8,
+				PreRequisiteWorkPackageHashes: []common.Hash{},
+			},
+			CoreIndex:         1,
+			AuthorizerHash:    common.Hash{9},
+			Output:            []byte{},
+			SegmentRootLookup: map[common.Hash]common.Hash{{11}: {12}, {10}: {13}},
+			WorkResults: []*WorkResult{
+				{ServiceId: 1, ServiceCodeHash: common.Hash{14}, PayloadHash: common.Hash{15}, Gas: 100, ExecResult: &ExecResult{Output: []byte{1}}},
+				{ServiceId: 2, ServiceCodeHash: common.Hash{16}, PayloadHash: common.Hash{17}, Gas: 200, ExecResult: &ExecResult{Error: OutOfGas}},
+			},
+		},
+		Timeslot: 10,
+		Credentials: []*Credential{
+			{ValidatorIndex: 1, Signature: make([]byte, ed25519.SignatureSize)},
+			{ValidatorIndex: 2, Signature: make([]byte, ed25519.SignatureSize)},
+		},
+	}
+
+	encoded, err := codec.Encode(guarantee)
+	require.NoError(t, err)
+
+	decoded := &Guarantee{}
+	err = codec.Decode(encoded, decoded)
+	require.NoError(t, err)
+	require.Equal(t, guarantee, decoded)
+}
+
+func TestAssuranceEncodeDecode(t *testing.T) {
+	assurance := &Assurance{
+		AnchorParentHash: common.Hash{1},
+		ValidatorIndex:   2,
+		Signature:        make([]byte, ed25519.SignatureSize),
+	}
+	assurance.WorkReportAvailabilities[0] = true
+
+	encoded, err := codec.Encode(assurance)
+	require.NoError(t, err)
+	require.Len(t, encoded, common.HashLength+(common.NumOfCores+7)/8+2+ed25519.SignatureSize)
+
+	decoded := &Assurance{}
+	err = codec.Decode(encoded, decoded)
+	require.NoError(t, err)
+	require.Equal(t, assurance, decoded)
+}
@@ -8,7 +8,6 @@ import (
 	"maps"
 
 	"github.com/pkg/errors"
-	"github.com/shunsukew/gojam/internal/accumulate"
 	authpool "github.com/shunsukew/gojam/internal/authorizer/pool"
 	"github.com/shunsukew/gojam/internal/entropy"
 	"github.com/shunsukew/gojam/internal/history"
@@ -17,6 +16,7 @@ import (
 	"github.com/shunsukew/gojam/internal/validator/keys"
 	"github.com/shunsukew/gojam/internal/work"
 	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
 )
 
 type PendingWorkReports [common.NumOfCores]*PendingWorkReport
@@ -26,6 +26,35 @@ type PendingWorkReport struct {
 	WorkReport *WorkReport
 }
 
+// Reports judged to be bad or wonkey are removed from the pending reports, producing the intermidiate state ρ†.
+// Gray Paper (10.15) ∀c ∈ N_C : ρ†[c] = ∅ if {(H(ρ[c]w), t) ∈ V, t < ⌊2/3V⌋}, ρ[c] otherwise
+func (p *PendingWorkReports) RemoveDisputedReports(reportHashes []common.Hash) error {
+	if len(reportHashes) == 0 {
+		return nil
+	}
+
+	disputed := make(map[common.Hash]struct{}, len(reportHashes))
+	for _, hash := range reportHashes {
+		disputed[hash] = struct{}{}
+	}
+
+	for coreIndex, pendingWorkReport := range *p {
+		if pendingWorkReport == nil {
+			continue
+		}
+
+		hash, err := pendingWorkReport.WorkReport.Hash()
+		if err != nil {
+			return err
+		}
+		if _, ok := disputed[hash]; ok {
+			(*p)[coreIndex] = nil
+		}
+	}
+
+	return nil
+}
+
 // This method should be called after disputes done, which means intermidiate state ρ†
 func (p *PendingWorkReports) AssureAvailabilities(
 	timeSlot jamtime.TimeSlot,
@@ -82,7 +111,8 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 	authorizerPools *authpool.AuthorizerPools,
 	services *service.Services,
 	recentBlocks *history.RecentHistory,
-	accumulateHistory *accumulate.AccumulationHistory,
+	accumulationQueue WorkPackageLookup, // θ
+	accumulationHistory WorkPackageLookup, // ξ
 ) ([]ed25519.PublicKey, error) {
 	// At this point, PendingWorkReports must be ρ†† (intermidiate state after availability assurances).
 
@@ -108,6 +138,7 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 	refinementContexts := make([]*work.RefinementContext, len(guarantees)) // intermidiate variable x
 	workPackageHashes := make(map[common.Hash]struct{}, len(guarantees))   // intermidiate variable p
 	segmentRootLookups := make(map[common.Hash]common.Hash, len(workReports))
+	signatures := crypto.NewEd25519Signatures(len(guarantees) * MaxCredentialsInGuarantee)
 
 	for i, guarantee := range guarantees {
 		guarantorAssignments := currentGuarantorAssignments
@@ -117,7 +148,7 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 			guarantorKeys = archivedGuarantors
 		}
 
-		guarantors, err := guarantee.checkGuaranteedWorkReport(timeSlot, guarantorAssignments, guarantorKeys)
+		guarantors, err := guarantee.checkGuaranteedWorkReport(timeSlot, guarantorAssignments, guarantorKeys, signatures)
 		if err != nil {
 			return nil, errors.WithMessagef(ErrInvalidGuarantees, "guarantee validation failed: %v", err)
 		}
@@ -129,6 +160,10 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 		segmentRootLookups[guarantee.WorkReport.AvailabilitySpecification.WorkPackageHash] = guarantee.WorkReport.AvailabilitySpecification.SegmentRoot
 	}
 
+	if invalid := signatures.Verify(); invalid != -1 {
+		return nil, errors.WithMessagef(ErrBadSignature, "credential signature of guarantor %s is invalid", common.Bytes2Hex(reporters[invalid]))
+	}
+
 	// compare cardinality of work package hashes in guarantees extrinsic with the number of work reports.
 	if len(workPackageHashes) != len(workReports) {
 		return nil, errors.WithMessagef(ErrInvalidGuarantees, "work package hash must be unique in guarantees extrinsic")
@@ -160,8 +195,6 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 		if err != nil {
 			return nil, err
 		}
-
-		// TODO: Check signature, which requires JAM codec implementation.
 	}
 
 	// Contextual Validity of work reports
@@ -172,6 +205,12 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 		}
 	}
 
+	// (11.42) The work package of a report must not be the work package of some other report made in the past.
+	err = p.ensureNewWorkPackages(workPackageHashes, recentBlocks, accumulationQueue, accumulationHistory)
+	if err != nil {
+		return nil, err
+	}
+
 	recentWorkPackageHashes := workPackageHashes   // work package hashes in the incoming block + ones in recent history
 	recentSegmentRootLookups := segmentRootLookups // segment roots in the incoming block + ones in recent history
 	for _, recentBlock := range *recentBlocks {
@@ -191,9 +230,6 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 		return nil, err
 	}
 
-	// TODO: We require that the work-package of the report not be the work-package of some other report made in the past.
-	// Better to be implemented after having accumulation queue and history.
-
 	// Update ρ after all validations passed
 	for _, guarantee := range guarantees {
 		p[guarantee.WorkReport.CoreIndex] = &PendingWorkReport{
@@ -204,3 +240,46 @@ func (p *PendingWorkReports) GuaranteeNewWorkReports(
 
 	return reporters, nil
 }
+
+// WorkPackageLookup looks up work packages reported in the past, implemented by the accumulation queue θ and history ξ.
+type WorkPackageLookup interface {
+	ContainsWorkPackage(workPackageHash common.Hash) bool
+}
+
+// (11.42) ∀p ∈ p, p ∉ ⋃x∈β K(xp) ∪ ⋃x∈ξ x ∪ q ∪ a
+func (p *PendingWorkReports) ensureNewWorkPackages(
+	workPackageHashes map[common.Hash]struct{},
+	recentBlocks *history.RecentHistory,
+	accumulationQueue WorkPackageLookup,
+	accumulationHistory WorkPackageLookup,
+) error {
+	// a ≡ {((rw)s)h ∣ r ∈ ρ, r ≠ ∅}
+	pendingWorkPackageHashes := make(map[common.Hash]struct{}, common.NumOfCores)
+	for _, pendingWorkReport := range p {
+		if pendingWorkReport != nil {
+			pendingWorkPackageHashes[pendingWorkReport.WorkReport.AvailabilitySpecification.WorkPackageHash] = struct{}{}
+		}
+	}
+
+	for workPackageHash := range workPackageHashes {
+		for _, recentBlock := range *recentBlocks {
+			if _, ok := recentBlock.WorkPackageHashes[workPackageHash]; ok {
+				return errors.WithMessagef(ErrDuplicateWorkPackage, "work package %s is in recent history", workPackageHash.ToHex())
+			}
+		}
+
+		if accumulationHistory.ContainsWorkPackage(workPackageHash) {
+			return errors.WithMessagef(ErrDuplicateWorkPackage, "work package %s has already been accumulated", workPackageHash.ToHex())
+		}
+
+		if accumulationQueue.ContainsWorkPackage(workPackageHash) {
+			return errors.WithMessagef(ErrDuplicateWorkPackage, "work package %s is queued for accumulation", workPackageHash.ToHex())
+		}
+
+		if _, ok := pendingWorkPackageHashes[workPackageHash]; ok {
+			return errors.WithMessagef(ErrDuplicateWorkPackage, "work package %s is pending availability", workPackageHash.ToHex())
+		}
+	}
+
+	return nil
+}
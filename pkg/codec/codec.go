@@ -0,0 +1,154 @@
+package codec
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JAM serialization codec defined in the Gray Paper Appendix C.
+//
+// Values are encoded by reflection with the following defaults:
+//   - bool: a single octet, 0 or 1.
+//   - uint8 ~ uint64, int8 ~ int64: fixed-width little endian E_l, l being the byte size of the Go type.
+//   - uint, int: general natural number encoding E.
+//   - string, slice: length prefixed sequence ↕x.
+//   - array: fixed length sequence without length prefix.
+//   - []bool, [N]bool: bit sequence, packed LSB-first. Slices are prefixed with the number of bits.
+//   - map: length prefixed dictionary of key-value pairs sorted by key.
+//   - pointer: the pointed value. Use the `optional` tag for ¿x.
+//   - struct: exported fields in declaration order.
+//   - ed25519.PublicKey: fixed 32 octets.
+//
+// Struct field tags can override the defaults:
+//
+//	`codec:"-"`         skip the field.
+//	`codec:"compact"`   encode an integer with the general natural number encoding E.
+//	`codec:"size=N"`    encode an integer with the fixed-width encoding E_N.
+//	`codec:"length=N"`  encode a slice or string as a fixed length sequence of N items without length prefix.
+//	`codec:"optional"`  encode a pointer as ¿x, 0 for nil, otherwise 1 followed by the value.
+//
+// Types can also provide their own encoding by implementing Marshaler and Unmarshaler,
+// which is necessary for discriminated unions such as work execution results.
+
+const tagName = "codec"
+
+var (
+	ErrUnsupportedType    = errors.New("unsupported type")
+	ErrInvalidLength      = errors.New("invalid length")
+	ErrInvalidTag         = errors.New("invalid codec tag")
+	ErrUnexpectedEOF      = errors.New("unexpected end of data")
+	ErrTrailingData       = errors.New("trailing data after decoding")
+	ErrInvalidOptional    = errors.New("invalid optional discriminator")
+	ErrInvalidBool        = errors.New("invalid boolean value")
+	ErrInvalidDictionary  = errors.New("invalid dictionary")
+	ErrNaturalOutOfRange  = errors.New("natural number out of range")
+	ErrNonPointerArgument = errors.New("decode target must be a non-nil pointer")
+)
+
+// Marshaler is the interface implemented by types that can encode themselves into JAM codec bytes.
+type Marshaler interface {
+	MarshalJAM() ([]byte, error)
+}
+
+// Unmarshaler is the interface implemented by types that can decode themselves from JAM codec bytes.
+// Implementations must consume exactly the bytes of their own encoding from the decoder.
+type Unmarshaler interface {
+	UnmarshalJAM(d *Decoder) error
+}
+
+var (
+	marshalerType        = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType      = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	ed25519PublicKeyType = reflect.TypeOf(ed25519.PublicKey{})
+)
+
+type fieldOptions struct {
+	skip     bool
+	compact  bool
+	size     int
+	length   int
+	optional bool
+}
+
+func parseTag(tag string) (fieldOptions, error) {
+	var opts fieldOptions
+	if tag == "" {
+		return opts, nil
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "-":
+			opts.skip = true
+		case part == "compact":
+			opts.compact = true
+		case part == "optional":
+			opts.optional = true
+		case strings.HasPrefix(part, "size="):
+			size, err := strconv.Atoi(strings.TrimPrefix(part, "size="))
+			if err != nil || size < 1 || size > 8 {
+				return opts, errors.WithMessagef(ErrInvalidTag, "invalid size %q", part)
+			}
+			opts.size = size
+		case strings.HasPrefix(part, "length="):
+			length, err := strconv.Atoi(strings.TrimPrefix(part, "length="))
+			if err != nil || length < 0 {
+				return opts, errors.WithMessagef(ErrInvalidTag, "invalid length %q", part)
+			}
+			opts.length = length
+		default:
+			return opts, errors.WithMessagef(ErrInvalidTag, "unknown option %q", part)
+		}
+	}
+
+	return opts, nil
+}
+
+// Some types have a fixed length in the Gray Paper but are represented as slices in Go.
+func withTypeDefaults(t reflect.Type, opts fieldOptions) fieldOptions {
+	if t == ed25519PublicKeyType && opts.length == 0 {
+		opts.length = ed25519.PublicKeySize
+	}
+	return opts
+}
+
+func integerSize(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Uint8, reflect.Int8:
+		return 1
+	case reflect.Uint16, reflect.Int16:
+		return 2
+	case reflect.Uint32, reflect.Int32:
+		return 4
+	case reflect.Uint64, reflect.Int64:
+		return 8
+	}
+	return 0
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isSigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// Byte sequences are encoded octet by octet, unless the element type has its own encoding.
+func isByteType(t reflect.Type) bool {
+	return t.Kind() == reflect.Uint8 && !t.Implements(marshalerType) && !reflect.PointerTo(t).Implements(unmarshalerType)
+}
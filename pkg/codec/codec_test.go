@@ -66,3 +66,174 @@ func TestEncodeDecodeBits(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeDecodeNatural(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    uint64
+		expected []byte
+	}{
+		{name: "zero", value: 0, expected: []byte{0x00}},
+		{name: "single byte max", value: 127, expected: []byte{0x7f}},
+		{name: "two bytes min", value: 128, expected: []byte{0x80, 0x80}},
+		{name: "two bytes", value: 300, expected: []byte{0x81, 0x2c}},
+		{name: "two bytes max", value: 1<<14 - 1, expected: []byte{0xbf, 0xff}},
+		{name: "three bytes min", value: 1 << 14, expected: []byte{0xc0, 0x00, 0x40}},
+		{name: "four bytes", value: 1 << 21, expected: []byte{0xe0, 0x00, 0x00, 0x20}},
+		{name: "eight bytes max", value: 1<<56 - 1, expected: []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{name: "nine bytes", value: 1 << 56, expected: []byte{0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{name: "max uint64", value: ^uint64(0), expected: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, test := range tests {
+		t.Run("Encode_"+test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, EncodeNatural(test.value))
+		})
+
+		t.Run("Decode_"+test.name, func(t *testing.T) {
+			actual, n, err := DecodeNatural(test.expected)
+			require.NoError(t, err)
+			require.Equal(t, len(test.expected), n)
+			require.Equal(t, test.value, actual)
+		})
+	}
+}
+
+func TestEncodeDecodeUint(t *testing.T) {
+	require.Equal(t, []byte{0x78, 0x56, 0x34, 0x12}, EncodeUint(0x12345678, 4))
+	require.Equal(t, []byte{0x34, 0x12}, EncodeUint(0x1234, 2))
+	require.Equal(t, []byte{}, EncodeUint(0x1234, 0))
+	require.Equal(t, uint64(0x12345678), DecodeUint([]byte{0x78, 0x56, 0x34, 0x12}))
+}
+
+type innerStruct struct {
+	Flag  bool
+	Value uint16
+}
+
+type taggedStruct struct {
+	Fixed      uint32            `codec:"size=2"`
+	Compact    uint32            `codec:"compact"`
+	Natural    uint              // general natural by default
+	Signature  []byte            `codec:"length=4"`
+	Blob       []byte            // length prefixed
+	Hash       common.Hash       // fixed length array
+	Optional   *innerStruct      `codec:"optional"`
+	Absent     *innerStruct      `codec:"optional"`
+	Required   *innerStruct      // not optional
+	Items      []*innerStruct    // sequence of structs
+	Bits       []bool            // length prefixed bit sequence
+	FixedBits  [10]bool          // bit sequence without prefix
+	Dictionary map[uint32][]byte // sorted by key
+	Name       string
+	Skipped    uint64 `codec:"-"`
+}
+
+func TestEncodeDecodeStruct(t *testing.T) {
+	value := taggedStruct{
+		Fixed:      0x0102,
+		Compact:    300,
+		Natural:    5,
+		Signature:  []byte{1, 2, 3, 4},
+		Blob:       []byte{0xaa, 0xbb},
+		Hash:       common.Hash{0xff},
+		Optional:   &innerStruct{Flag: true, Value: 7},
+		Required:   &innerStruct{Value: 1},
+		Items:      []*innerStruct{{Flag: false, Value: 2}, {Flag: true, Value: 3}},
+		Bits:       []bool{true, false, true},
+		FixedBits:  [10]bool{true, 9: true},
+		Dictionary: map[uint32][]byte{256: {0x02}, 1: {0x01}},
+		Name:       "jam",
+	}
+
+	expected := []byte{}
+	expected = append(expected, 0x02, 0x01)                                                                   // Fixed
+	expected = append(expected, 0x81, 0x2c)                                                                   // Compact
+	expected = append(expected, 0x05)                                                                         // Natural
+	expected = append(expected, 1, 2, 3, 4)                                                                   // Signature
+	expected = append(expected, 0x02, 0xaa, 0xbb)                                                             // Blob
+	expected = append(expected, value.Hash[:]...)                                                             // Hash
+	expected = append(expected, 0x01, 0x01, 0x07, 0x00)                                                       // Optional
+	expected = append(expected, 0x00)                                                                         // Absent
+	expected = append(expected, 0x00, 0x01, 0x00)                                                             // Required
+	expected = append(expected, 0x02, 0x00, 0x02, 0x00, 0x01, 0x03, 0x00)                                     // Items
+	expected = append(expected, 0x03, 0b00000101)                                                             // Bits
+	expected = append(expected, 0b00000001, 0b00000010)                                                       // FixedBits
+	expected = append(expected, 0x02, 0x01, 0x00, 0x00, 0x00, 0x01, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x02) // Dictionary
+	expected = append(expected, 0x03, 'j', 'a', 'm')                                                          // Name
+
+	encoded, err := Encode(value)
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	var decoded taggedStruct
+	err = Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, value, decoded)
+}
+
+type union struct {
+	Ok  []byte
+	Err uint8
+}
+
+func (u union) MarshalJAM() ([]byte, error) {
+	if u.Ok != nil {
+		return append([]byte{0}, EncodeLengthPrefixed(u.Ok)...), nil
+	}
+	return []byte{u.Err}, nil
+}
+
+func (u *union) UnmarshalJAM(d *Decoder) error {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return err
+	}
+	if kind == 0 {
+		u.Ok, err = d.DecodeLengthPrefixed()
+		return err
+	}
+	u.Err = kind
+	return nil
+}
+
+func TestEncodeDecodeMarshaler(t *testing.T) {
+	type withUnion struct {
+		Results []union
+		Result  *union
+	}
+
+	value := withUnion{
+		Results: []union{{Ok: []byte{0x01}}, {Err: 2}},
+		Result:  &union{Err: 3},
+	}
+
+	encoded, err := Encode(value)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x02, 0x00, 0x01, 0x01, 0x02, 0x03}, encoded)
+
+	var decoded withUnion
+	err = Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, value, decoded)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var value taggedStruct
+	require.ErrorIs(t, Decode([]byte{0x01}, &value), ErrUnexpectedEOF)
+	require.ErrorIs(t, Decode([]byte{0x01}, value), ErrNonPointerArgument)
+
+	var optional struct {
+		Value *uint8 `codec:"optional"`
+	}
+	require.ErrorIs(t, Decode([]byte{0x02, 0x00}, &optional), ErrInvalidOptional)
+
+	var flag bool
+	require.ErrorIs(t, Decode([]byte{0x02}, &flag), ErrInvalidBool)
+
+	var num uint8
+	require.ErrorIs(t, Decode([]byte{0x01, 0x02}, &num), ErrTrailingData)
+
+	var dict map[uint8]uint8
+	require.ErrorIs(t, Decode([]byte{0x02, 0x02, 0x00, 0x01, 0x00}, &dict), ErrInvalidDictionary)
+}
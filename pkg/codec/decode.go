@@ -1,11 +1,60 @@
 package codec
 
+import (
+	"math/bits"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Decode deserializes JAM codec bytes into v, which must be a non-nil pointer.
+// All of data must be consumed by the decoding.
 func Decode(data []byte, v interface{}) error {
-	// TODO: implement actual decode logic
-	// Placeholder for decoding logic
+	d := NewDecoder(data)
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+
+	if d.Remaining() != 0 {
+		return errors.WithMessagef(ErrTrailingData, "%d bytes left", d.Remaining())
+	}
+
 	return nil
 }
 
+// DecodeNatural decodes the general natural number serialization E from the head of data,
+// and returns the decoded number with the number of consumed bytes.
+func DecodeNatural(data []byte) (uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrUnexpectedEOF
+	}
+
+	prefix := data[0]
+	l := bits.LeadingZeros8(^prefix) // number of leading 1 bits
+	if l == 8 {
+		if len(data) < 9 {
+			return 0, 0, ErrUnexpectedEOF
+		}
+		return DecodeUint(data[1:9]), 9, nil
+	}
+
+	if len(data) < 1+l {
+		return 0, 0, ErrUnexpectedEOF
+	}
+
+	high := uint64(prefix) & (1<<(7-l) - 1)
+	return high<<(8*l) + DecodeUint(data[1:1+l]), 1 + l, nil
+}
+
+// DecodeUint decodes the fixed-length little endian serialization E_l, where l is the length of data.
+func DecodeUint(data []byte) uint64 {
+	var x uint64
+	for i, b := range data {
+		x |= uint64(b) << (8 * i)
+	}
+	return x
+}
+
 // TODO: Sequence length prefix handling
 // Currently, only handling known length of bits sequence.
 // We might need to add length prefix handling both for encoding and decoding later.
@@ -23,3 +72,358 @@ func DecodeBitSequence(bytes []byte, bitCount int) []bool {
 
 	return bits
 }
+
+// Decoder reads JAM codec values sequentially from a byte slice.
+type Decoder struct {
+	data   []byte
+	offset int
+}
+
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// Remaining returns the number of bytes not consumed yet.
+func (d *Decoder) Remaining() int {
+	return len(d.data) - d.offset
+}
+
+// Decode decodes the next value into v, which must be a non-nil pointer.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrNonPointerArgument
+	}
+
+	return d.decode(rv.Elem(), fieldOptions{})
+}
+
+// ReadBytes consumes the next n bytes.
+func (d *Decoder) ReadBytes(n int) ([]byte, error) {
+	if n < 0 || d.Remaining() < n {
+		return nil, errors.WithMessagef(ErrUnexpectedEOF, "need %d bytes, %d left", n, d.Remaining())
+	}
+
+	b := d.data[d.offset : d.offset+n]
+	d.offset += n
+	return b, nil
+}
+
+// ReadByte consumes the next byte.
+func (d *Decoder) ReadByte() (byte, error) {
+	b, err := d.ReadBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// DecodeNatural consumes the next general natural number.
+func (d *Decoder) DecodeNatural() (uint64, error) {
+	x, n, err := DecodeNatural(d.data[d.offset:])
+	if err != nil {
+		return 0, err
+	}
+	d.offset += n
+	return x, nil
+}
+
+// DecodeUint consumes the next fixed-length integer of l bytes.
+func (d *Decoder) DecodeUint(l int) (uint64, error) {
+	b, err := d.ReadBytes(l)
+	if err != nil {
+		return 0, err
+	}
+	return DecodeUint(b), nil
+}
+
+// DecodeLengthPrefixed consumes the next length prefixed blob ↕x.
+func (d *Decoder) DecodeLengthPrefixed() ([]byte, error) {
+	length, err := d.decodeLength()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := d.ReadBytes(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, b...), nil
+}
+
+func (d *Decoder) decodeLength() (int, error) {
+	length, err := d.DecodeNatural()
+	if err != nil {
+		return 0, err
+	}
+
+	// Each item takes at least one bit, so any length beyond that cannot be valid.
+	if length > uint64(d.Remaining())*8 {
+		return 0, errors.WithMessagef(ErrInvalidLength, "length %d exceeds remaining data", length)
+	}
+
+	return int(length), nil
+}
+
+func (d *Decoder) decode(v reflect.Value, opts fieldOptions) error {
+	opts = withTypeDefaults(v.Type(), opts)
+
+	if v.Kind() == reflect.Pointer && opts.optional {
+		discriminator, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch discriminator {
+		case 0:
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		case 1:
+			v.Set(reflect.New(v.Type().Elem()))
+			return d.decode(v.Elem(), fieldOptions{})
+		default:
+			return errors.WithMessagef(ErrInvalidOptional, "got %d", discriminator)
+		}
+	}
+
+	if ok, err := d.decodeUnmarshaler(v); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b > 1 {
+			return errors.WithMessagef(ErrInvalidBool, "got %d", b)
+		}
+		v.SetBool(b == 1)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := d.decodeUint(v.Kind(), opts)
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(x) {
+			return errors.WithMessagef(ErrNaturalOutOfRange, "value %d overflows %s", x, v.Type())
+		}
+		v.SetUint(x)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := d.decodeUint(v.Kind(), opts)
+		if err != nil {
+			return err
+		}
+		size := opts.size
+		if size == 0 {
+			size = integerSize(v.Kind())
+		}
+		signed := int64(x)
+		if !opts.compact && size > 0 && size < 8 {
+			// sign extension of two's complement E_l
+			shift := 64 - 8*size
+			signed = int64(x<<shift) >> shift
+		}
+		if v.OverflowInt(signed) {
+			return errors.WithMessagef(ErrNaturalOutOfRange, "value %d overflows %s", signed, v.Type())
+		}
+		v.SetInt(signed)
+		return nil
+
+	case reflect.String:
+		b, err := d.decodeBytes(opts)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+		return nil
+
+	case reflect.Slice:
+		return d.decodeSlice(v, opts)
+
+	case reflect.Array:
+		return d.decodeSequence(v)
+
+	case reflect.Struct:
+		return d.decodeStruct(v)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem(), opts)
+
+	case reflect.Map:
+		return d.decodeMap(v)
+	}
+
+	return errors.WithMessagef(ErrUnsupportedType, "cannot decode %s", v.Type())
+}
+
+func (d *Decoder) decodeUnmarshaler(v reflect.Value) (bool, error) {
+	if v.Kind() == reflect.Pointer && v.Type().Implements(unmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return true, v.Interface().(Unmarshaler).UnmarshalJAM(d)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		return true, v.Addr().Interface().(Unmarshaler).UnmarshalJAM(d)
+	}
+
+	return false, nil
+}
+
+func (d *Decoder) decodeUint(kind reflect.Kind, opts fieldOptions) (uint64, error) {
+	if opts.compact || (opts.size == 0 && (kind == reflect.Uint || kind == reflect.Int)) {
+		return d.DecodeNatural()
+	}
+
+	size := opts.size
+	if size == 0 {
+		size = integerSize(kind)
+	}
+	return d.DecodeUint(size)
+}
+
+func (d *Decoder) decodeBytes(opts fieldOptions) ([]byte, error) {
+	length := opts.length
+	if length == 0 {
+		var err error
+		length, err = d.decodeLength()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := d.ReadBytes(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, b...), nil
+}
+
+func (d *Decoder) decodeSlice(v reflect.Value, opts fieldOptions) error {
+	elemType := v.Type().Elem()
+
+	if isByteType(elemType) {
+		b, err := d.decodeBytes(opts)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(b), len(b))
+		reflect.Copy(slice, reflect.ValueOf(b))
+		v.Set(slice)
+		return nil
+	}
+
+	length := opts.length
+	if length == 0 {
+		var err error
+		length, err = d.decodeLength()
+		if err != nil {
+			return err
+		}
+	}
+
+	v.Set(reflect.MakeSlice(v.Type(), length, length))
+	return d.decodeSequence(v)
+}
+
+// Decode items of an array or slice, whose length is already known.
+func (d *Decoder) decodeSequence(v reflect.Value) error {
+	elemType := v.Type().Elem()
+
+	if elemType.Kind() == reflect.Bool {
+		b, err := d.ReadBytes((v.Len() + 7) / 8)
+		if err != nil {
+			return err
+		}
+		for i, bit := range DecodeBitSequence(b, v.Len()) {
+			v.Index(i).SetBool(bit)
+		}
+		return nil
+	}
+
+	if isByteType(elemType) {
+		b, err := d.ReadBytes(v.Len())
+		if err != nil {
+			return err
+		}
+		for i := range b {
+			v.Index(i).SetUint(uint64(b[i]))
+		}
+		return nil
+	}
+
+	for i := range v.Len() {
+		if err := d.decode(v.Index(i), fieldOptions{}); err != nil {
+			return errors.WithMessagef(err, "index %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		opts, err := parseTag(field.Tag.Get(tagName))
+		if err != nil {
+			return errors.WithMessagef(err, "%s.%s", t.Name(), field.Name)
+		}
+		if opts.skip {
+			continue
+		}
+
+		if err := d.decode(v.Field(i), opts); err != nil {
+			return errors.WithMessagef(err, "%s.%s", t.Name(), field.Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeMap(v reflect.Value) error {
+	length, err := d.decodeLength()
+	if err != nil {
+		return err
+	}
+
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, length)
+	var prevKey reflect.Value
+	for i := range length {
+		key := reflect.New(t.Key()).Elem()
+		if err := d.decode(key, fieldOptions{}); err != nil {
+			return errors.WithMessage(err, "map key")
+		}
+
+		// Keys must be strictly ascending for the encoding to be canonical.
+		if i > 0 && compareKeys(prevKey, key) >= 0 {
+			return errors.WithMessage(ErrInvalidDictionary, "keys are not sorted or contain duplicates")
+		}
+		prevKey = key
+
+		value := reflect.New(t.Elem()).Elem()
+		if err := d.decode(value, fieldOptions{}); err != nil {
+			return errors.WithMessage(err, "map value")
+		}
+		m.SetMapIndex(key, value)
+	}
+
+	v.Set(m)
+	return nil
+}
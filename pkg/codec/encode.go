@@ -1,8 +1,46 @@
 package codec
 
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Encode serializes data into JAM codec bytes, E(data).
 func Encode(data interface{}) ([]byte, error) {
-	// TODO: implement actual encode logic
-	return nil, nil
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(data), fieldOptions{}); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// EncodeNatural encodes x with the general natural number serialization E, Gray Paper (C.6).
+func EncodeNatural(x uint64) []byte {
+	for l := 0; l < 8; l++ {
+		if x < 1<<(7*(l+1)) {
+			prefix := byte(256 - (1 << (8 - l)) + int(x>>(8*l)))
+			return append([]byte{prefix}, EncodeUint(x, l)...)
+		}
+	}
+
+	return append([]byte{math.MaxUint8}, EncodeUint(x, 8)...)
+}
+
+// EncodeUint encodes x with the fixed-length little endian serialization E_l, Gray Paper (C.5).
+func EncodeUint(x uint64, l int) []byte {
+	encoded := make([]byte, l)
+	for i := range l {
+		encoded[i] = byte(x >> (8 * i))
+	}
+	return encoded
+}
+
+// EncodeLengthPrefixed encodes blob as ↕x, its length followed by the blob itself.
+func EncodeLengthPrefixed(blob []byte) []byte {
+	return append(EncodeNatural(uint64(len(blob))), blob...)
 }
 
 func EncodeBitSequence(bits []bool) []byte {
@@ -23,3 +61,277 @@ func EncodeBitSequence(bits []bool) []byte {
 
 	return encoded
 }
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) write(b ...byte) {
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) encode(v reflect.Value, opts fieldOptions) error {
+	if !v.IsValid() {
+		return errors.WithMessage(ErrUnsupportedType, "cannot encode nil value")
+	}
+
+	opts = withTypeDefaults(v.Type(), opts)
+
+	if v.Kind() == reflect.Pointer && opts.optional {
+		if v.IsNil() {
+			e.write(0)
+			return nil
+		}
+		e.write(1)
+		return e.encode(v.Elem(), fieldOptions{})
+	}
+
+	if ok, err := e.encodeMarshaler(v); ok {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.write(1)
+		} else {
+			e.write(0)
+		}
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return e.encodeUint(v.Uint(), v.Kind(), opts)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 && (opts.compact || v.Kind() == reflect.Int) {
+			return errors.WithMessagef(ErrNaturalOutOfRange, "negative value %d cannot be encoded as natural number", v.Int())
+		}
+		return e.encodeUint(uint64(v.Int()), v.Kind(), opts)
+
+	case reflect.String:
+		return e.encodeBytes([]byte(v.String()), opts)
+
+	case reflect.Slice:
+		return e.encodeSlice(v, opts)
+
+	case reflect.Array:
+		return e.encodeSequence(v)
+
+	case reflect.Struct:
+		return e.encodeStruct(v)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return e.encode(reflect.Zero(v.Type().Elem()), opts)
+		}
+		return e.encode(v.Elem(), opts)
+
+	case reflect.Map:
+		return e.encodeMap(v)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return errors.WithMessagef(ErrUnsupportedType, "cannot encode nil interface %s", v.Type())
+		}
+		return e.encode(v.Elem(), opts)
+	}
+
+	return errors.WithMessagef(ErrUnsupportedType, "cannot encode %s", v.Type())
+}
+
+func (e *encoder) encodeMarshaler(v reflect.Value) (bool, error) {
+	var marshaler Marshaler
+	switch {
+	case v.Type().Implements(marshalerType):
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return false, nil
+		}
+		marshaler = v.Interface().(Marshaler)
+	case v.CanAddr() && v.Addr().Type().Implements(marshalerType):
+		marshaler = v.Addr().Interface().(Marshaler)
+	case reflect.PointerTo(v.Type()).Implements(marshalerType):
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		marshaler = ptr.Interface().(Marshaler)
+	default:
+		return false, nil
+	}
+
+	encoded, err := marshaler.MarshalJAM()
+	if err != nil {
+		return true, err
+	}
+	e.write(encoded...)
+	return true, nil
+}
+
+func (e *encoder) encodeUint(x uint64, kind reflect.Kind, opts fieldOptions) error {
+	if opts.compact || (opts.size == 0 && (kind == reflect.Uint || kind == reflect.Int)) {
+		e.write(EncodeNatural(x)...)
+		return nil
+	}
+
+	size := opts.size
+	if size == 0 {
+		size = integerSize(kind)
+	}
+	if size < 8 && x >= 1<<(8*size) && !isSigned(kind) {
+		return errors.WithMessagef(ErrNaturalOutOfRange, "value %d does not fit in %d octets", x, size)
+	}
+
+	e.write(EncodeUint(x, size)...)
+	return nil
+}
+
+func (e *encoder) encodeBytes(b []byte, opts fieldOptions) error {
+	if opts.length > 0 {
+		if len(b) != opts.length {
+			return errors.WithMessagef(ErrInvalidLength, "expected fixed length %d, got %d", opts.length, len(b))
+		}
+	} else {
+		e.write(EncodeNatural(uint64(len(b)))...)
+	}
+	e.write(b...)
+	return nil
+}
+
+func (e *encoder) encodeSlice(v reflect.Value, opts fieldOptions) error {
+	elemType := v.Type().Elem()
+
+	if isByteType(elemType) {
+		return e.encodeBytes(v.Bytes(), opts)
+	}
+
+	if opts.length > 0 {
+		if v.Len() != opts.length {
+			return errors.WithMessagef(ErrInvalidLength, "expected fixed length %d, got %d", opts.length, v.Len())
+		}
+	} else {
+		e.write(EncodeNatural(uint64(v.Len()))...)
+	}
+
+	return e.encodeSequence(v)
+}
+
+// Encode items of an array or slice without length prefix.
+func (e *encoder) encodeSequence(v reflect.Value) error {
+	elemType := v.Type().Elem()
+
+	if elemType.Kind() == reflect.Bool {
+		bits := make([]bool, v.Len())
+		for i := range v.Len() {
+			bits[i] = v.Index(i).Bool()
+		}
+		e.write(EncodeBitSequence(bits)...)
+		return nil
+	}
+
+	if isByteType(elemType) {
+		for i := range v.Len() {
+			e.write(byte(v.Index(i).Uint()))
+		}
+		return nil
+	}
+
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i), fieldOptions{}); err != nil {
+			return errors.WithMessagef(err, "index %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		opts, err := parseTag(field.Tag.Get(tagName))
+		if err != nil {
+			return errors.WithMessagef(err, "%s.%s", t.Name(), field.Name)
+		}
+		if opts.skip {
+			continue
+		}
+
+		if err := e.encode(v.Field(i), opts); err != nil {
+			return errors.WithMessagef(err, "%s.%s", t.Name(), field.Name)
+		}
+	}
+
+	return nil
+}
+
+// Dictionary encoding, Gray Paper (C.10).
+// E(d ∈ D⟨K→V⟩) ≡ E(↕[⎩E(k), E(d[k])⎭ ∣ k <− K(d)]), ordered by key.
+func (e *encoder) encodeMap(v reflect.Value) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return compareKeys(keys[i], keys[j]) < 0
+	})
+
+	e.write(EncodeNatural(uint64(len(keys)))...)
+	for _, key := range keys {
+		if err := e.encode(key, fieldOptions{}); err != nil {
+			return errors.WithMessage(err, "map key")
+		}
+		if err := e.encode(v.MapIndex(key), fieldOptions{}); err != nil {
+			return errors.WithMessage(err, "map value")
+		}
+	}
+
+	return nil
+}
+
+// compareKeys orders dictionary keys. Integers are compared numerically,
+// octet sequences lexicographically, and composite keys item by item.
+func compareKeys(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.String:
+		return compareOrdered(a.String(), b.String())
+	case reflect.Bool:
+		return compareOrdered(boolToInt(a.Bool()), boolToInt(b.Bool()))
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			if c := compareKeys(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return compareOrdered(a.Len(), b.Len())
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if c := compareKeys(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Pointer, reflect.Interface:
+		return compareKeys(a.Elem(), b.Elem())
+	}
+	return 0
+}
+
+func compareOrdered[T ~int | ~int64 | ~uint64 | ~string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -12,11 +12,25 @@ const (
 	PublicKeySize      = 32
 	RingCommitmentSize = 144
 	SignatureSize      = 784
+	IetfSignatureSize  = 96
 	VrfOutputSize      = 32
 )
 
 type PrivateKey [32]byte
 
+// NewPrivateKeyFromSeed deterministically derives a secret key from the seed.
+func NewPrivateKeyFromSeed(seed []byte) (PrivateKey, error) {
+	if len(seed) == 0 {
+		return PrivateKey{}, errors.New("seed must not be empty")
+	}
+
+	return newSecretFromSeed(seed)
+}
+
+func (pk PrivateKey) PublicKey() (PublicKey, error) {
+	return newPublicKeyFromSecret(pk)
+}
+
 func (pk *PrivateKey) UnmarshalJSON(data []byte) error {
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
@@ -102,6 +116,45 @@ func (sig *Signature) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Non-anonymous (IETF) VRF signature, defined as V;tilde in the Gray Paper.
+// It is composed of the VRF output point and the proof.
+type IetfSignature [IetfSignatureSize]byte
+
+// IetfSign signs the VRF input and auxiliary data with the secret key.
+func IetfSign(secret PrivateKey, input, auxData []byte) (IetfSignature, error) {
+	return ietfSign(secret, input, auxData)
+}
+
+// Verify verifies the signature against the public key, and returns the VRF output Y(s).
+func (sig IetfSignature) Verify(input, auxData []byte, publicKey PublicKey) (VrfOutput, error) {
+	output, err := ietfVerify(publicKey, input, auxData, sig)
+	if err != nil {
+		return VrfOutput{}, err
+	}
+
+	return output, nil
+}
+
+// Output returns the VRF output Y(s) without verifying the signature.
+func (sig IetfSignature) Output() (VrfOutput, error) {
+	return ietfOutput(sig)
+}
+
+func (sig *IetfSignature) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	bytes := common.FromHex(s)
+	if len(bytes) != IetfSignatureSize {
+		return errors.New("invalid bandersnatch ietf signature length")
+	}
+	copy(sig[:], bytes)
+
+	return nil
+}
+
 type VrfOutput [VrfOutputSize]byte
 
 func (vo *VrfOutput) UnmarshalJSON(data []byte) error {
@@ -0,0 +1,98 @@
+package bandersnatch
+
+// #cgo pkg-config: bandersnatch-ring-vrf
+// #include <stddef.h>
+// #include "bandersnatch-ring-vrf.h"
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+func ietfSign(
+	secret PrivateKey,
+	input []byte,
+	auxData []byte,
+) (IetfSignature, error) {
+	var signature IetfSignature
+
+	// input and auxData can be empty, but we must pass a valid pointer
+	inputLen, auxDataLen := len(input), len(auxData)
+	if len(input) == 0 {
+		input = make([]byte, 1)
+	}
+	if len(auxData) == 0 {
+		auxData = make([]byte, 1)
+	}
+
+	ok := C.ietf_vrf_sign(
+		(*C.uchar)(unsafe.Pointer(&secret[0])),
+		(*C.uchar)(unsafe.Pointer(&input[0])),
+		C.size_t(inputLen),
+		(*C.uchar)(unsafe.Pointer(&auxData[0])),
+		C.size_t(auxDataLen),
+		(*C.uchar)(unsafe.Pointer(&signature[0])),
+	)
+	if !ok {
+		return signature, errors.New("failed to sign ietf vrf")
+	}
+
+	if unsafe.Sizeof(signature) != IetfSignatureSize {
+		return signature, errors.New("signature buffer size mismatch")
+	}
+
+	return signature, nil
+}
+
+func ietfVerify(
+	publicKey PublicKey,
+	input []byte,
+	auxData []byte,
+	signature IetfSignature,
+) (VrfOutput, error) {
+	var output VrfOutput
+
+	// input and auxData can be empty, but we must pass a valid pointer
+	inputLen, auxDataLen := len(input), len(auxData)
+	if len(input) == 0 {
+		input = make([]byte, 1)
+	}
+	if len(auxData) == 0 {
+		auxData = make([]byte, 1)
+	}
+
+	ok := C.ietf_vrf_verify(
+		(*C.uchar)(unsafe.Pointer(&publicKey[0])),
+		(*C.uchar)(unsafe.Pointer(&input[0])),
+		C.size_t(inputLen),
+		(*C.uchar)(unsafe.Pointer(&auxData[0])),
+		C.size_t(auxDataLen),
+		(*C.uchar)(unsafe.Pointer(&signature[0])),
+		(*C.uchar)(unsafe.Pointer(&output[0])),
+	)
+	if !ok {
+		return output, errors.New("failed to verify ietf vrf")
+	}
+
+	if unsafe.Sizeof(output) != VrfOutputSize {
+		return output, errors.New("output buffer size mismatch")
+	}
+
+	return output, nil
+}
+
+func ietfOutput(signature IetfSignature) (VrfOutput, error) {
+	var output VrfOutput
+
+	ok := C.ietf_vrf_output(
+		(*C.uchar)(unsafe.Pointer(&signature[0])),
+		(*C.uchar)(unsafe.Pointer(&output[0])),
+	)
+	if !ok {
+		return output, errors.New("failed to derive ietf vrf output")
+	}
+
+	return output, nil
+}
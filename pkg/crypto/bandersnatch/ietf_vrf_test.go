@@ -0,0 +1,53 @@
+package bandersnatch
+
+import (
+	"testing"
+)
+
+func TestIetfVRFSignAndVerify(t *testing.T) {
+	secret, err := newSecretFromSeed([]byte("prover secret"))
+	if err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	publicKey, err := newPublicKeyFromSecret(secret)
+	if err != nil {
+		t.Fatalf("failed to create public key: %v", err)
+	}
+
+	input := []byte("input data")
+	auxData := []byte("aux data")
+
+	signature, err := IetfSign(secret, input, auxData)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	output, err := signature.Verify(input, auxData, publicKey)
+	if err != nil {
+		t.Fatal("signature verification failed")
+	}
+
+	unverifiedOutput, err := signature.Output()
+	if err != nil {
+		t.Fatalf("failed to derive output: %v", err)
+	}
+	if output != unverifiedOutput {
+		t.Fatal("expected verified and unverified outputs to match")
+	}
+
+	if _, err := signature.Verify(input, []byte("other aux data"), publicKey); err == nil {
+		t.Fatal("expected verification to fail with different aux data")
+	}
+
+	otherSecret, err := newSecretFromSeed([]byte("other secret"))
+	if err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	otherPublicKey, err := newPublicKeyFromSecret(otherSecret)
+	if err != nil {
+		t.Fatalf("failed to create public key: %v", err)
+	}
+	if _, err := signature.Verify(input, auxData, otherPublicKey); err == nil {
+		t.Fatal("expected verification to fail with different public key")
+	}
+}
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"encoding/hex"
 	"testing"
 )
@@ -28,3 +29,34 @@ func checkhash(t *testing.T, name string, f func([]byte) []byte, msg, exp []byte
 		t.Fatalf("hash %s mismatch: want: %x have: %x", name, exp, sum)
 	}
 }
+
+func TestEd25519Signatures(t *testing.T) {
+	signatures := NewEd25519Signatures(10)
+	if signatures.Verify() != -1 {
+		t.Fatal("empty signatures must be valid")
+	}
+
+	for i := range 10 {
+		publicKey, privateKey, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := []byte{byte(i)}
+		signatures.Add(publicKey, message, ed25519.Sign(privateKey, message))
+	}
+	if invalid := signatures.Verify(); invalid != -1 {
+		t.Fatalf("valid signatures reported invalid signature at %d", invalid)
+	}
+
+	signatures.entries[7].message = []byte{0xff}
+	signatures.entries[3].signature = make([]byte, ed25519.SignatureSize)
+	if invalid := signatures.Verify(); invalid != 3 {
+		t.Fatalf("expected first invalid signature at 3, got %d", invalid)
+	}
+
+	signatures.entries = signatures.entries[:3]
+	signatures.entries[1].publicKey = signatures.entries[1].publicKey[:ed25519.PublicKeySize-1]
+	if invalid := signatures.Verify(); invalid != 1 {
+		t.Fatalf("expected malformed public key at 1, got %d", invalid)
+	}
+}
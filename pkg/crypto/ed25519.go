@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// Ed25519Signatures collects Ed25519 signatures so that they are batch verified once collected.
+// Verification follows the ZIP-215 rules, under which a batch is valid if and only if each of its signatures is valid.
+type Ed25519Signatures struct {
+	entries []ed25519Entry
+}
+
+type ed25519Entry struct {
+	publicKey ed25519.PublicKey
+	message   []byte
+	signature []byte
+}
+
+func NewEd25519Signatures(size int) *Ed25519Signatures {
+	return &Ed25519Signatures{entries: make([]ed25519Entry, 0, size)}
+}
+
+func (b *Ed25519Signatures) Add(publicKey ed25519.PublicKey, message, signature []byte) {
+	b.entries = append(b.entries, ed25519Entry{publicKey: publicKey, message: message, signature: signature})
+}
+
+func (b *Ed25519Signatures) Len() int {
+	return len(b.entries)
+}
+
+// Verify returns the index of the first invalid signature, or -1 if all signatures are valid.
+// The signatures are verified one by one only when the batch fails, to find the invalid one.
+func (b *Ed25519Signatures) Verify() int {
+	if len(b.entries) == 0 {
+		return -1
+	}
+
+	verifier := ed25519consensus.NewPreallocatedBatchVerifier(len(b.entries))
+	for _, entry := range b.entries {
+		verifier.Add(entry.publicKey, entry.message, entry.signature)
+	}
+	if verifier.Verify() {
+		return -1
+	}
+
+	for i, entry := range b.entries {
+		if !ed25519consensus.Verify(entry.publicKey, entry.message, entry.signature) {
+			return i
+		}
+	}
+
+	return -1
+}
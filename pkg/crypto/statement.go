@@ -2,6 +2,8 @@ package crypto
 
 const (
 	JamTicketSealStatement       = "jam_ticket_seal"
+	JamFallbackSealStatement     = "jam_fallback_seal"
+	JamEntropyStatement          = "jam_entropy"
 	JamValidJudgementStatement   = "jam_valid"
 	JamInvalidJudgementStatement = "jam_invalid"
 	JamGuaranteeStatement        = "jam_guarantee"
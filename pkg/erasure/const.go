@@ -0,0 +1,11 @@
+//go:build !tiny
+
+package erasure
+
+import "github.com/shunsukew/gojam/pkg/common"
+
+const (
+	NumOfChunks     = common.NumOfValidators // V = 1023: The number of chunks, one per validator.
+	NumOfDataChunks = 342                    // The number of chunks from which the data can be reconstructed.
+	PieceSize       = 684                    // W_E = 684: The basic size of erasure-coded pieces in octets.
+)
@@ -0,0 +1,11 @@
+//go:build tiny
+
+package erasure
+
+import "github.com/shunsukew/gojam/pkg/common"
+
+const (
+	NumOfChunks     = common.NumOfValidators
+	NumOfDataChunks = 2
+	PieceSize       = 4
+)
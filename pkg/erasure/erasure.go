@@ -0,0 +1,190 @@
+package erasure
+
+import (
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// The Reed-Solomon erasure code of the Gray Paper Appendix H.
+//
+// Data d is padded to k pieces of W_E octets and split into NumOfDataChunks pieces of 2k octets.
+// The i-th words of the pieces, read as elements of GF(2^16) in the layout of the reference coder (see wordOffsets),
+// are the values of a polynomial at the points ω_0 ... ω_{NumOfDataChunks−1}, which also vanishes on the remaining points
+// of the smallest subspace domain containing them.
+// The recovery chunks are the evaluations of the polynomials at the points following the domain, so that the first
+// NumOfDataChunks chunks are the data itself and any NumOfDataChunks chunks reconstruct it.
+// Both directions go through the additive FFT, see fft.go.
+
+const numOfRecoveryChunks = NumOfChunks - NumOfDataChunks
+
+var (
+	// domainSize is the smallest power of two of at least NumOfDataChunks points.
+	domainSize = 1 << bits.Len(uint(NumOfDataChunks-1))
+	// transformSize is the smallest power of two of at least the points of the domain and the recovery chunks.
+	transformSize = 1 << bits.Len(uint(domainSize+numOfRecoveryChunks-1))
+)
+
+// ChunkSize returns the size in octets of each chunk of data of the given length, 2⌈|d|/W_E⌉.
+func ChunkSize(dataLength int) int {
+	return 2 * ((dataLength + PieceSize - 1) / PieceSize)
+}
+
+// Encode splits the data, such as a work-package bundle or an export segment, into NumOfChunks chunks of ChunkSize octets.
+// Gray Paper (H.4) C_⌈|d|/W_E⌉(P_W_E(d))
+func Encode(data []byte) [][]byte {
+	chunkSize := ChunkSize(len(data))
+
+	padded := make([]byte, NumOfDataChunks*chunkSize)
+	copy(padded, data)
+
+	chunks := make([][]byte, NumOfChunks)
+	for i := range NumOfDataChunks {
+		chunks[i] = padded[i*chunkSize : (i+1)*chunkSize]
+	}
+
+	transformTablesOnce.Do(initTransformTables)
+
+	// The recovery chunks are evaluated a domain at a time, so the work holds whole domains.
+	work := make([][]uint16, (numOfRecoveryChunks+domainSize-1)/domainSize*domainSize)
+	for i := range work {
+		work[i] = make([]uint16, chunkSize/2)
+	}
+
+	// The coefficients of the polynomials from the data chunks at ω_0 ... ω_{domainSize−1}, zero beyond them.
+	for i := range NumOfDataChunks {
+		toWords(chunks[i], work[i])
+	}
+	ifft(work[:domainSize], NumOfDataChunks, 0)
+
+	for start := domainSize; start < len(work); start += domainSize {
+		for i := range domainSize {
+			copy(work[start+i], work[i])
+		}
+	}
+
+	// The recovery chunks at ω_{domainSize} onwards.
+	for start := 0; start < numOfRecoveryChunks; start += domainSize {
+		fft(work[start:start+domainSize], min(domainSize, numOfRecoveryChunks-start), domainSize+start)
+	}
+
+	for r := range numOfRecoveryChunks {
+		chunks[NumOfDataChunks+r] = fromWords(work[r], chunkSize)
+	}
+
+	return chunks
+}
+
+// Reconstruct recovers the data from at least NumOfDataChunks distinct chunks, keyed by their chunk index.
+// The data is returned padded to a multiple of W_E octets, Gray Paper (H.5) R_k.
+func Reconstruct(chunks map[int][]byte) ([]byte, error) {
+	if len(chunks) < NumOfDataChunks {
+		return nil, errors.WithMessagef(ErrNotEnoughChunks, "%d chunks given, %d required", len(chunks), NumOfDataChunks)
+	}
+
+	chunkSize := -1
+	for index, chunk := range chunks {
+		if index < 0 || index >= NumOfChunks {
+			return nil, errors.WithMessagef(ErrInvalidChunkIndex, "chunk index %d", index)
+		}
+		if chunkSize == -1 {
+			chunkSize = len(chunk)
+		}
+		if len(chunk) != chunkSize || chunkSize%2 != 0 {
+			return nil, errors.WithMessagef(ErrInvalidChunkSize, "chunk %d has %d octets", index, len(chunk))
+		}
+	}
+
+	// Nothing needs to be recovered when the data chunks are all given.
+	missing := false
+	for i := range NumOfDataChunks {
+		if _, ok := chunks[i]; !ok {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		data := make([]byte, 0, NumOfDataChunks*chunkSize)
+		for i := range NumOfDataChunks {
+			data = append(data, chunks[i]...)
+		}
+		return data, nil
+	}
+
+	transformTablesOnce.Do(initTransformTables)
+
+	// Every point of the transform is erased but the given chunks and the points of the domain on which the polynomials vanish.
+	erasures := new([fieldSize]uint16)
+	for x := range transformSize {
+		erasures[x] = 1
+	}
+	for x := NumOfDataChunks; x < domainSize; x++ {
+		erasures[x] = 0
+	}
+	for index := range chunks {
+		erasures[point(index)] = 0
+	}
+	evaluateErrorLocator(erasures)
+
+	// The polynomials multiplied by the error locator ℓ are known at every point, zero at the erased ones.
+	// The formal derivative of the product at an erased point ω_e is the value of the polynomial times ℓ′(ω_e).
+	work := make([][]uint16, transformSize)
+	for x := range work {
+		work[x] = make([]uint16, chunkSize/2)
+	}
+	for index, chunk := range chunks {
+		x := point(index)
+		toWords(chunk, work[x])
+		mulWords(work[x], erasures[x])
+	}
+	ifft(work, domainSize+numOfRecoveryChunks, 0)
+	formalDerivative(work)
+	fft(work, NumOfDataChunks, 0)
+
+	data := make([]byte, 0, NumOfDataChunks*chunkSize)
+	for i := range NumOfDataChunks {
+		if chunk, ok := chunks[i]; ok {
+			data = append(data, chunk...)
+			continue
+		}
+		mulWords(work[i], fieldModulus-erasures[i])
+		data = append(data, fromWords(work[i], chunkSize)...)
+	}
+
+	return data, nil
+}
+
+// point returns the index of the point ω at which the chunk of the index is evaluated.
+func point(index int) int {
+	if index < NumOfDataChunks {
+		return index
+	}
+	return domainSize + index - NumOfDataChunks
+}
+
+// toWords reads the words of the chunk in the layout of wordOffsets.
+func toWords(chunk []byte, words []uint16) {
+	for w := range words {
+		lo, hi := wordOffsets(len(chunk), w)
+		words[w] = uint16(chunk[lo]) | uint16(chunk[hi])<<8
+	}
+}
+
+// fromWords lays out the words in a chunk of the size as wordOffsets.
+func fromWords(words []uint16, chunkSize int) []byte {
+	chunk := make([]byte, chunkSize)
+	for w, word := range words {
+		lo, hi := wordOffsets(chunkSize, w)
+		chunk[lo], chunk[hi] = byte(word), byte(word>>8)
+	}
+	return chunk
+}
+
+// wordOffsets returns the offsets of the low and high octets of the w-th word of a chunk, laid out as in reed-solomon-simd.
+// Every block of 64 octets holds the low octets of 32 words followed by their high octets,
+// and a final block of t < 64 octets the low octets of t/2 words followed by their high octets.
+func wordOffsets(chunkSize int, w int) (int, int) {
+	block := w / 32 * 64
+	half := min(64, chunkSize-block) / 2
+	return block + w%32, block + half + w%32
+}
@@ -0,0 +1,207 @@
+package erasure
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mulPolynomial multiplies two elements in the polynomial basis.
+func mulPolynomial(a, b uint16) uint16 {
+	var product uint32
+	for i := range fieldBits {
+		if b&(1<<i) != 0 {
+			product ^= uint32(a) << i
+		}
+	}
+	for i := 2*fieldBits - 2; i >= fieldBits; i-- {
+		if product&(1<<i) != 0 {
+			product ^= fieldPolynomial << (i - fieldBits)
+		}
+	}
+	return uint16(product)
+}
+
+// The Lagrange interpolation of the code, a dense O(k²) oracle of the FFT.
+
+// oracleRecoveryCoefficients returns L_i(x_r) of each recovery chunk r over the data points i.
+func oracleRecoveryCoefficients() [][]uint16 {
+	points := make([]uint16, domainSize)
+	for x := range points {
+		points[x] = uint16(x)
+	}
+	weights := barycentricWeights(points)
+
+	coefficients := make([][]uint16, numOfRecoveryChunks)
+	for r := range coefficients {
+		// The values on the rest of the domain are zero, only the data points contribute.
+		coefficients[r] = lagrangeCoefficients(points, weights, uint16(point(NumOfDataChunks+r)))[:NumOfDataChunks]
+	}
+	return coefficients
+}
+
+// interpolate returns the chunk of which each word is Σ c_i·y_i over the words y_i of the chunks at the same position.
+func interpolate(chunks [][]byte, coefficients []uint16, chunkSize int) []byte {
+	words := make([]uint16, chunkSize/2)
+	for i, coefficient := range coefficients {
+		for w := range words {
+			lo, hi := wordOffsets(chunkSize, w)
+			words[w] ^= mul(coefficient, uint16(chunks[i][lo])|uint16(chunks[i][hi])<<8)
+		}
+	}
+	return fromWords(words, chunkSize)
+}
+
+// barycentricWeights returns w_i = 1 / Π_{j≠i} (x_i − x_j) of the distinct interpolation points.
+func barycentricWeights(points []uint16) []uint16 {
+	weights := make([]uint16, len(points))
+	for i, xi := range points {
+		product := uint16(1)
+		for j, xj := range points {
+			if i != j {
+				product = mul(product, xi^xj)
+			}
+		}
+		weights[i] = inv(product)
+	}
+	return weights
+}
+
+// lagrangeCoefficients returns L_i(x) = ℓ(x)·w_i / (x − x_i) with ℓ(x) = Π_j (x − x_j), for x which is not an interpolation point.
+func lagrangeCoefficients(points []uint16, weights []uint16, x uint16) []uint16 {
+	l := uint16(1)
+	for _, xj := range points {
+		l = mul(l, x^xj)
+	}
+
+	coefficients := make([]uint16, len(points))
+	for i, xi := range points {
+		coefficients[i] = mul(l, mul(weights[i], inv(x^xi)))
+	}
+	return coefficients
+}
+
+func TestField(t *testing.T) {
+	require.Equal(t, uint16(1), cantorBasis[0])
+	for i := 1; i < fieldBits; i++ {
+		v := cantorBasis[i]
+		require.Equalf(t, cantorBasis[i-1], mulPolynomial(v, v)^v, "v_%d^2 + v_%d = v_%d", i, i, i-1)
+	}
+
+	// x generates the multiplicative group, so that every non-zero element has a logarithm.
+	for a := 1; a < fieldSize; a++ {
+		require.Equal(t, uint16(a), expTable[logTable[a]])
+		require.Equal(t, uint16(1), mul(uint16(a), inv(uint16(a))))
+	}
+	require.Equal(t, uint16(0), mul(0, 0x1234))
+
+	// The element with the Cantor representation 1 is the unit.
+	require.Equal(t, uint16(0x1234), mul(1, 0x1234))
+}
+
+func TestEncodeReconstruct(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, length := range []int{1, PieceSize, 3*PieceSize + 1} {
+		data := make([]byte, length)
+		rng.Read(data)
+
+		chunks := Encode(data)
+		require.Len(t, chunks, NumOfChunks)
+		for _, chunk := range chunks {
+			require.Len(t, chunk, ChunkSize(length))
+		}
+
+		padded := make([]byte, NumOfDataChunks*ChunkSize(length))
+		copy(padded, data)
+
+		// Any NumOfDataChunks chunks reconstruct the data.
+		for _, indices := range [][]int{
+			rng.Perm(NumOfChunks)[:NumOfDataChunks],
+			rng.Perm(NumOfChunks)[:NumOfDataChunks],
+			func() []int { // Every chunk but the first.
+				indices := make([]int, NumOfChunks-1)
+				for i := range indices {
+					indices[i] = i + 1
+				}
+				return indices
+			}(),
+			func() []int { // Recovery chunks only.
+				indices := make([]int, NumOfDataChunks)
+				for i := range indices {
+					indices[i] = NumOfChunks - 1 - i
+				}
+				return indices
+			}(),
+		} {
+			subset := make(map[int][]byte, len(indices))
+			for _, index := range indices {
+				subset[index] = chunks[index]
+			}
+
+			reconstructed, err := Reconstruct(subset)
+			require.NoError(t, err)
+			require.Equal(t, padded, reconstructed)
+		}
+	}
+}
+
+func TestEncodeMatchesOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	coefficients := oracleRecoveryCoefficients()
+
+	for _, length := range []int{1, 2*PieceSize - 1, 40 * PieceSize} {
+		data := make([]byte, length)
+		rng.Read(data)
+
+		chunks := Encode(data)
+		for r := range numOfRecoveryChunks {
+			expected := interpolate(chunks[:NumOfDataChunks], coefficients[r], ChunkSize(length))
+			require.Equal(t, expected, chunks[NumOfDataChunks+r], "recovery chunk %d", r)
+		}
+	}
+}
+
+func TestWordLayout(t *testing.T) {
+	// Chunks of 68 octets hold a block of 32 words and a final block of 2 words.
+	data := make([]byte, 34*PieceSize)
+	require.Equal(t, 68, ChunkSize(len(data)))
+
+	// The word 1 of the first data chunk has its low octet at 1 and its high octet at 33,
+	// and the word 32 in the final block its low octet at 64 and its high octet at 66.
+	data[1], data[33], data[64], data[66] = 0xab, 0xcd, 0x12, 0x34
+
+	chunks := Encode(data)
+	for r, coefficients := range oracleRecoveryCoefficients() {
+		chunk := chunks[NumOfDataChunks+r]
+		word1, word32 := mul(coefficients[0], 0xcdab), mul(coefficients[0], 0x3412)
+
+		expected := make([]byte, 68)
+		expected[1], expected[33] = byte(word1), byte(word1>>8)
+		expected[64], expected[66] = byte(word32), byte(word32>>8)
+		require.Equal(t, expected, chunk, "recovery chunk %d", r)
+	}
+}
+
+func TestReconstructErrors(t *testing.T) {
+	chunks := Encode([]byte{1, 2, 3})
+
+	_, err := Reconstruct(map[int][]byte{0: chunks[0]})
+	require.ErrorIs(t, err, ErrNotEnoughChunks)
+
+	subset := make(map[int][]byte)
+	for i := range NumOfDataChunks {
+		subset[i+1] = chunks[i+1]
+	}
+	invalid := map[int][]byte{NumOfChunks: chunks[0]}
+	for index, chunk := range subset {
+		invalid[index] = chunk
+	}
+	_, err = Reconstruct(invalid)
+	require.ErrorIs(t, err, ErrInvalidChunkIndex)
+
+	subset[0] = []byte{1}
+	_, err = Reconstruct(subset)
+	require.ErrorIs(t, err, ErrInvalidChunkSize)
+}
@@ -0,0 +1,9 @@
+package erasure
+
+import "github.com/pkg/errors"
+
+var (
+	ErrNotEnoughChunks   = errors.New("not enough chunks")
+	ErrInvalidChunkIndex = errors.New("invalid chunk index")
+	ErrInvalidChunkSize  = errors.New("invalid chunk size")
+)
@@ -0,0 +1,161 @@
+package erasure
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// The additive FFT of Lin, Chung and Han over the Cantor basis, as in reed-solomon-simd.
+//
+// A polynomial of degree below n is held by its coefficients in the novel polynomial basis X̄_0 ... X̄_{n−1}.
+// fft evaluates it at the n points ω_offset ... ω_{offset+n−1}, where ω_i is the element of which the Cantor basis
+// representation is i, and ifft interpolates it from them, both in O(n log n) operations of GF(2^16).
+// Each shard holds the words at the same position of the chunks, so that a butterfly works on whole chunks.
+
+var (
+	transformTablesOnce sync.Once
+	skew                [fieldModulus]uint16 // log of the twiddle factor of each butterfly, fieldModulus for zero.
+	logWalsh            [fieldSize]uint16    // The Walsh-Hadamard transform of the logarithms, to evaluate error locator polynomials.
+)
+
+func initTransformTables() {
+	var temp [fieldBits - 1]uint16
+	for i := 1; i < fieldBits; i++ {
+		temp[i-1] = 1 << i
+	}
+
+	for m := range fieldBits - 1 {
+		step := 1 << (m + 1)
+		skew[1<<m-1] = 0
+
+		for i := m; i < fieldBits-1; i++ {
+			s := 1 << (i + 1)
+			for j := 1<<m - 1; j < s; j += step {
+				skew[j+s] = skew[j] ^ temp[i]
+			}
+		}
+
+		temp[m] = fieldModulus - logOf(mulLog(temp[m], logOf(temp[m]^1)))
+		for i := m + 1; i < fieldBits-1; i++ {
+			temp[i] = mulLog(temp[i], addMod(logOf(temp[i]^1), temp[m]))
+		}
+	}
+
+	for i, element := range skew {
+		skew[i] = logOf(element)
+	}
+
+	for i := range logWalsh {
+		logWalsh[i] = logTable[i]
+	}
+	logWalsh[0] = 0
+	fwht(&logWalsh)
+}
+
+// logOf returns the logarithm of the element, fieldModulus for zero.
+func logOf(a uint16) uint16 {
+	if a == 0 {
+		return fieldModulus
+	}
+	return logTable[a]
+}
+
+// mulLog multiplies the element by the element of the logarithm.
+func mulLog(a uint16, log uint16) uint16 {
+	if a == 0 {
+		return 0
+	}
+	return expTable[uint32(logTable[a])+uint32(log)]
+}
+
+// addMod and subMod add and subtract logarithms modulo fieldModulus, of which fieldModulus itself is a representation of 0.
+func addMod(a, b uint16) uint16 {
+	sum := uint32(a) + uint32(b)
+	return uint16(sum + sum>>fieldBits)
+}
+
+func subMod(a, b uint16) uint16 {
+	dif := uint32(a) - uint32(b)
+	return uint16(dif + dif>>fieldBits)
+}
+
+// fwht is the Walsh-Hadamard transform modulo fieldModulus, its own inverse as fieldSize ≡ 1 (mod fieldModulus).
+func fwht(data *[fieldSize]uint16) {
+	for dist := 1; dist < fieldSize; dist *= 2 {
+		for r := 0; r < fieldSize; r += 2 * dist {
+			for i := r; i < r+dist; i++ {
+				data[i], data[i+dist] = addMod(data[i], data[i+dist]), subMod(data[i], data[i+dist])
+			}
+		}
+	}
+}
+
+// fft evaluates the polynomial of the coefficients in the shards at ω_offset ... ω_{offset+len(shards)−1}.
+// Only the evaluations at the first truncatedSize points are computed.
+func fft(shards [][]uint16, truncatedSize int, offset int) {
+	for dist := len(shards) / 2; dist > 0; dist /= 2 {
+		for r := 0; r < truncatedSize; r += 2 * dist {
+			log := skew[r+dist+offset-1]
+			for i := r; i < r+dist; i++ {
+				if log != fieldModulus {
+					mulAddWords(shards[i], shards[i+dist], log)
+				}
+				xorWords(shards[i+dist], shards[i])
+			}
+		}
+	}
+}
+
+// ifft interpolates the coefficients of the polynomial from its evaluations in the shards at ω_offset ... ω_{offset+len(shards)−1}.
+// The evaluations from truncatedSize on must be zero.
+func ifft(shards [][]uint16, truncatedSize int, offset int) {
+	for dist := 1; dist < len(shards); dist *= 2 {
+		for r := 0; r < truncatedSize; r += 2 * dist {
+			log := skew[r+dist+offset-1]
+			for i := r; i < r+dist; i++ {
+				xorWords(shards[i+dist], shards[i])
+				if log != fieldModulus {
+					mulAddWords(shards[i], shards[i+dist], log)
+				}
+			}
+		}
+	}
+}
+
+// formalDerivative replaces the coefficients in the novel polynomial basis with those of the formal derivative.
+func formalDerivative(shards [][]uint16) {
+	for i := 1; i < len(shards); i++ {
+		width := 1 << bits.TrailingZeros(uint(i))
+		for j := range width {
+			xorWords(shards[i-width+j], shards[i+j])
+		}
+	}
+}
+
+// evaluateErrorLocator replaces the erasure indicators of the points ω_0 ... ω_{fieldSize−1} with log ℓ(ω_i),
+// where ℓ(x) = Π (x − ω_e) over the erased points ω_e, and with log ℓ′(ω_i) at the erased points.
+func evaluateErrorLocator(erasures *[fieldSize]uint16) {
+	fwht(erasures)
+	for i := range erasures {
+		erasures[i] = uint16(uint32(erasures[i]) * uint32(logWalsh[i]) % fieldModulus)
+	}
+	fwht(erasures)
+}
+
+func xorWords(x, y []uint16) {
+	for w := range x {
+		x[w] ^= y[w]
+	}
+}
+
+func mulAddWords(x, y []uint16, log uint16) {
+	for w := range x {
+		x[w] ^= mulLog(y[w], log)
+	}
+}
+
+func mulWords(x []uint16, log uint16) {
+	for w := range x {
+		x[w] = mulLog(x[w], log)
+	}
+}
@@ -0,0 +1,63 @@
+package erasure
+
+// GF(2^16) arithmetic, Gray Paper Appendix H.
+// The field is GF(2)[x] modulo x^16 + x^5 + x^3 + x^2 + 1, and its elements are represented
+// in the Cantor basis v_0 ... v_15, so that the element with representation i is Σ i_j·v_j.
+// Addition is XOR in either representation, multiplication goes through logarithm tables.
+
+const (
+	fieldBits       = 16
+	fieldSize       = 1 << fieldBits
+	fieldModulus    = fieldSize - 1 // The order of the multiplicative group.
+	fieldPolynomial = 0x1002d       // x^16 + x^5 + x^3 + x^2 + 1
+)
+
+// The Cantor basis v_0 ... v_15 in the polynomial basis, satisfying v_0 = 1 and v_i^2 + v_i = v_{i−1}.
+var cantorBasis = [fieldBits]uint16{
+	0x0001, 0xacca, 0x3c0e, 0x163e, 0xc582, 0xed2e, 0x914c, 0x4012,
+	0x6c98, 0x10d8, 0x6a72, 0xb900, 0xfdb8, 0xfb34, 0xff38, 0x991e,
+}
+
+var (
+	logTable [fieldSize]uint16        // log_g(a) of the element a in the Cantor basis.
+	expTable [2 * fieldModulus]uint16 // g^i in the Cantor basis, doubled so that a sum of two logarithms needs no reduction.
+)
+
+func init() {
+	// toCantor maps the polynomial basis representation of an element to its Cantor basis representation.
+	var toCantor [fieldSize]uint16
+	for c := range fieldSize {
+		var p uint16
+		for j := range fieldBits {
+			if c&(1<<j) != 0 {
+				p ^= cantorBasis[j]
+			}
+		}
+		toCantor[p] = uint16(c)
+	}
+
+	// x is a generator of the multiplicative group.
+	p := 1
+	for i := range fieldModulus {
+		element := toCantor[p]
+		expTable[i] = element
+		expTable[i+fieldModulus] = element
+		logTable[element] = uint16(i)
+
+		p <<= 1
+		if p&fieldSize != 0 {
+			p ^= fieldPolynomial
+		}
+	}
+}
+
+func mul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[uint32(logTable[a])+uint32(logTable[b])]
+}
+
+func inv(a uint16) uint16 {
+	return expTable[fieldModulus-uint32(logTable[a])]
+}
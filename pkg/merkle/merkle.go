@@ -0,0 +1,161 @@
+package merkle
+
+import (
+	"math/bits"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Merklization of sequences, Gray Paper Appendix E.1.
+
+var (
+	nodePrefix = []byte("node") // $node
+	leafPrefix = []byte("leaf") // $leaf
+)
+
+// Hasher is the hash function H the trees are built with.
+type Hasher func(data []byte) common.Hash
+
+// Blake2b is the default hash function H.
+func Blake2b(data []byte) common.Hash {
+	return blake2b.Sum256(data)
+}
+
+// Keccak is the hash function H_K, used for the trees committed to by BEEFY.
+func Keccak(data []byte) common.Hash {
+	return crypto.Keccak256Hash(data)
+}
+
+// Binary returns the root of the well-balanced binary Merkle tree over the blobs, Gray Paper (E.3)
+// M_B(v, H) ≡ H(v_0) if |v| = 1, N(v, H) otherwise
+func Binary(v [][]byte, hash Hasher) common.Hash {
+	if len(v) == 1 {
+		return hash(v[0])
+	}
+	return common.BytesToHash(node(v, hash))
+}
+
+// ConstantDepth returns the root of the constant-depth binary Merkle tree over the blobs, Gray Paper (E.4)
+// M(v, H) ≡ N(C(v, H), H)
+func ConstantDepth(v [][]byte, hash Hasher) common.Hash {
+	return common.BytesToHash(node(constancyPreprocess(v, hash), hash))
+}
+
+// Justification returns the hashes of the siblings on the path from the root of the constant-depth tree
+// down to the subtree of 2^x leaves containing the leaf i, Gray Paper (E.5)
+// J_x(v, i, H) ≡ T(C(v, H), 2^x⌊i/2^x⌋, H)...max(0, ⌈log2(max(1, |v|)) − x⌉)
+func Justification(v [][]byte, i int, x int, hash Hasher) []common.Hash {
+	return justification(constancyPreprocess(v, hash), len(v), i, x, hash)
+}
+
+// PagedJustifications returns J_x(v, i, H) of every subtree of 2^x leaves, i ∈ 2^x N_⌈|v|/2^x⌉,
+// preprocessing the leaves only once.
+func PagedJustifications(v [][]byte, x int, hash Hasher) [][]common.Hash {
+	leaves := constancyPreprocess(v, hash)
+
+	justifications := make([][]common.Hash, (len(v)+1<<x-1)>>x)
+	for page := range justifications {
+		justifications[page] = justification(leaves, len(v), page<<x, x, hash)
+	}
+	return justifications
+}
+
+func justification(leaves [][]byte, length int, i int, x int, hash Hasher) []common.Hash {
+	depth := max(0, bits.Len(uint(max(1, length)-1))-x)
+	trace := trace(leaves, (i>>x)<<x, hash)
+	return trace[:min(depth, len(trace))]
+}
+
+// SubtreeJustification returns the hashes of the siblings on the path from the root of the subtree of 2^x leaves
+// down to the leaf i, given the leaf hashes L_x(v, i, H) of the subtree. It completes J_x(v, i, H) into J_0(v, i, H).
+// J_0(v, i, H) = J_x(v, i, H) ⌢ T(L_x(v, i, H) padded with zero hashes to 2^x, i mod 2^x, H)
+func SubtreeJustification(leaves []common.Hash, i int, x int, hash Hasher) []common.Hash {
+	padded := make([][]byte, 1<<x)
+	for j := range padded {
+		if j < len(leaves) {
+			padded[j] = leaves[j][:]
+		} else {
+			padded[j] = make([]byte, common.HashLength)
+		}
+	}
+	return trace(padded, i&(1<<x-1), hash)
+}
+
+// JustifiedRoot returns the root of the constant-depth tree of which the blob is the leaf i with the justification J_0(v, i, H).
+func JustifiedRoot(blob []byte, i int, justification []common.Hash, hash Hasher) common.Hash {
+	root := hash(append(append([]byte{}, leafPrefix...), blob...))
+	for depth := len(justification) - 1; depth >= 0; depth-- {
+		data := append([]byte{}, nodePrefix...)
+		if (i>>(len(justification)-1-depth))&1 == 0 {
+			data = append(append(data, root[:]...), justification[depth][:]...)
+		} else {
+			data = append(append(data, justification[depth][:]...), root[:]...)
+		}
+		root = hash(data)
+	}
+	return root
+}
+
+// Leaves returns the leaf hashes of the subtree of 2^x leaves containing the leaf i, Gray Paper (E.6)
+// L_x(v, i, H) ≡ [H($leaf ⌢ l) ∣ l <− v_2^x⌊i/2^x⌋...min(2^x⌊i/2^x⌋+2^x, |v|)]
+func Leaves(v [][]byte, i int, x int, hash Hasher) []common.Hash {
+	start := (i >> x) << x
+	end := min(start+1<<x, len(v))
+
+	leaves := make([]common.Hash, 0, max(0, end-start))
+	for _, leaf := range v[start:max(start, end)] {
+		leaves = append(leaves, hash(append(append([]byte{}, leafPrefix...), leaf...)))
+	}
+	return leaves
+}
+
+// N(v, H) ≡ H^0 if |v| = 0, v_0 if |v| = 1, H($node ⌢ N(v...⌈|v|/2⌉, H) ⌢ N(v⌈|v|/2⌉..., H)) otherwise
+func node(v [][]byte, hash Hasher) []byte {
+	switch len(v) {
+	case 0:
+		return make([]byte, common.HashLength)
+	case 1:
+		return v[0]
+	}
+
+	mid := (len(v) + 1) / 2
+	data := append(append([]byte{}, nodePrefix...), node(v[:mid], hash)...)
+	data = append(data, node(v[mid:], hash)...)
+	h := hash(data)
+	return h[:]
+}
+
+// T(v, i, H) ≡ [N(P⊥(v, i), H)] ⌢ T(P⊤(v, i), i − P_I(v, i), H) if |v| > 1, [] otherwise
+// where P⊤ is the half of v containing the index i and P⊥ is the other half.
+func trace(v [][]byte, i int, hash Hasher) []common.Hash {
+	var hashes []common.Hash
+	for len(v) > 1 {
+		mid := (len(v) + 1) / 2
+		if i < mid {
+			hashes = append(hashes, common.BytesToHash(node(v[mid:], hash)))
+			v = v[:mid]
+		} else {
+			hashes = append(hashes, common.BytesToHash(node(v[:mid], hash)))
+			v, i = v[mid:], i-mid
+		}
+	}
+	return hashes
+}
+
+// C(v, H) pads the leaf hashes H($leaf ⌢ v_i) with zero hashes to the next power of two.
+func constancyPreprocess(v [][]byte, hash Hasher) [][]byte {
+	size := 1 << bits.Len(uint(max(1, len(v))-1))
+
+	leaves := make([][]byte, size)
+	for i := range leaves {
+		if i < len(v) {
+			h := hash(append(append([]byte{}, leafPrefix...), v[i]...))
+			leaves[i] = h[:]
+		} else {
+			leaves[i] = make([]byte, common.HashLength)
+		}
+	}
+	return leaves
+}
@@ -0,0 +1,93 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func nodeHash(left, right []byte) common.Hash {
+	return Blake2b(append(append([]byte("node"), left...), right...))
+}
+
+func leafHash(leaf []byte) common.Hash {
+	return Blake2b(append([]byte("leaf"), leaf...))
+}
+
+func TestBinary(t *testing.T) {
+	a, b, c := []byte{1}, []byte{2}, []byte{3}
+
+	require.Equal(t, common.Hash{}, Binary(nil, Blake2b))
+	require.Equal(t, Blake2b(a), Binary([][]byte{a}, Blake2b))
+	require.Equal(t, nodeHash(a, b), Binary([][]byte{a, b}, Blake2b))
+
+	// The left half takes the larger part, ⌈|v|/2⌉.
+	ab := nodeHash(a, b)
+	require.Equal(t, nodeHash(ab[:], c), Binary([][]byte{a, b, c}, Blake2b))
+}
+
+func TestConstantDepth(t *testing.T) {
+	a, b, c := []byte{1}, []byte{2}, []byte{3}
+	la, lb, lc := leafHash(a), leafHash(b), leafHash(c)
+
+	require.Equal(t, common.Hash{}, ConstantDepth(nil, Blake2b))
+	require.Equal(t, la, ConstantDepth([][]byte{a}, Blake2b))
+
+	// The leaves are padded with zero hashes to a power of two.
+	ab, c0 := nodeHash(la[:], lb[:]), nodeHash(lc[:], make([]byte, common.HashLength))
+	require.Equal(t, nodeHash(ab[:], c0[:]), ConstantDepth([][]byte{a, b, c}, Blake2b))
+}
+
+func TestJustification(t *testing.T) {
+	v := make([][]byte, 11)
+	for i := range v {
+		v[i] = []byte(fmt.Sprintf("leaf %d", i))
+	}
+	root := ConstantDepth(v, Blake2b)
+
+	// The full trace of each leaf recomputes the root from the leaf hash.
+	for i := range v {
+		path := Justification(v, i, 0, Blake2b)
+		require.Len(t, path, 4)
+
+		hash := leafHash(v[i])
+		for depth := len(path) - 1; depth >= 0; depth-- {
+			if (i>>(len(path)-1-depth))&1 == 0 {
+				hash = nodeHash(hash[:], path[depth][:])
+			} else {
+				hash = nodeHash(path[depth][:], hash[:])
+			}
+		}
+		require.Equal(t, root, hash)
+	}
+
+	// A page of 4 leaves is justified by the top 2 levels, and contains the hashes of its leaves.
+	require.Equal(t, Justification(v, 8, 0, Blake2b)[:2], Justification(v, 9, 2, Blake2b))
+	require.Equal(t, []common.Hash{leafHash(v[8]), leafHash(v[9]), leafHash(v[10])}, Leaves(v, 9, 2, Blake2b))
+
+	paged := PagedJustifications(v, 2, Blake2b)
+	require.Len(t, paged, 3)
+	for page := range paged {
+		require.Equal(t, Justification(v, page*4, 2, Blake2b), paged[page])
+	}
+}
+
+func TestSubtreeJustification(t *testing.T) {
+	v := make([][]byte, 11)
+	for i := range v {
+		v[i] = []byte(fmt.Sprintf("leaf %d", i))
+	}
+	root := ConstantDepth(v, Blake2b)
+
+	// The justification of the subtree of 4 leaves and the one within it make the full justification.
+	for i := range v {
+		justification := append(Justification(v, i, 2, Blake2b), SubtreeJustification(Leaves(v, i, 2, Blake2b), i, 2, Blake2b)...)
+		require.Equal(t, Justification(v, i, 0, Blake2b), justification)
+		require.Equal(t, root, JustifiedRoot(v[i], i, justification, Blake2b))
+	}
+
+	require.NotEqual(t, root, JustifiedRoot(v[1], 0, Justification(v, 0, 0, Blake2b), Blake2b))
+	require.Equal(t, ConstantDepth(v[:1], Blake2b), JustifiedRoot(v[0], 0, nil, Blake2b))
+}
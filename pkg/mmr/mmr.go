@@ -3,11 +3,63 @@ package mmr
 // TODO: implement own kecc
 import (
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 )
 
 type MMR []*common.Hash
 
+// Gray Paper (E.9)
+// E_M(b) ≡ E(↕[¿x ∣ x <− b]), each peak is encoded as an optional hash.
+func (mmr MMR) MarshalJAM() ([]byte, error) {
+	encoded := codec.EncodeNatural(uint64(len(mmr)))
+	for _, peak := range mmr {
+		if peak == nil {
+			encoded = append(encoded, 0)
+			continue
+		}
+		encoded = append(encoded, 1)
+		encoded = append(encoded, peak[:]...)
+	}
+	return encoded, nil
+}
+
+func (mmr *MMR) UnmarshalJAM(d *codec.Decoder) error {
+	length, err := d.DecodeNatural()
+	if err != nil {
+		return err
+	}
+	if length > uint64(d.Remaining()) {
+		return errors.WithMessagef(codec.ErrInvalidLength, "mmr length %d exceeds remaining data", length)
+	}
+
+	peaks := make(MMR, length)
+	for i := range peaks {
+		discriminator, err := d.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch discriminator {
+		case 0:
+			continue
+		case 1:
+			bytes, err := d.ReadBytes(common.HashLength)
+			if err != nil {
+				return err
+			}
+			peak := common.BytesToHash(bytes)
+			peaks[i] = &peak
+		default:
+			return errors.WithMessagef(codec.ErrInvalidOptional, "mmr peak %d", i)
+		}
+	}
+
+	*mmr = peaks
+	return nil
+}
+
 func (mmr MMR) SuperPeak() common.Hash {
 	// Collect all non-nil peaks
 	var peaks []*common.Hash
@@ -3,6 +3,7 @@ package mmr
 import (
 	"testing"
 
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 	"github.com/shunsukew/gojam/pkg/crypto"
 	"github.com/stretchr/testify/require"
@@ -77,3 +78,24 @@ func TestAppend(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeDecode(t *testing.T) {
+	hash_1 := common.Hash{1}
+	hash_2 := common.Hash{2}
+
+	mmr := MMR{nil, &hash_1, nil, &hash_2}
+
+	encoded, err := codec.Encode(mmr)
+	require.NoError(t, err)
+
+	expected := []byte{4, 0, 1}
+	expected = append(expected, hash_1[:]...)
+	expected = append(expected, 0, 1)
+	expected = append(expected, hash_2[:]...)
+	require.Equal(t, expected, encoded)
+
+	var decoded MMR
+	err = codec.Decode(encoded, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, mmr, decoded)
+}
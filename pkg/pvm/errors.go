@@ -0,0 +1,9 @@
+package pvm
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidProgram         = errors.New("invalid program blob")
+	ErrInvalidStandardProgram = errors.New("invalid standard program blob")
+	ErrInvalidMemoryRange     = errors.New("memory range out of the address space")
+)
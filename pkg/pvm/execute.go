@@ -0,0 +1,589 @@
+package pvm
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// execute executes the instruction at ı, Gray Paper (A.6) ~ (A.9).
+func (m *Machine) execute() (ExitReason, uint64, bool) {
+	pc := m.PC
+	opcode := Opcode(m.Program.codeAt(pc))
+	skip := m.Program.skip(pc)
+	next := pc + 1 + skip
+
+	switch {
+	// Instructions without arguments
+	case opcode == Trap:
+		return Panic, 0, true
+	case opcode == Fallthrough:
+		m.PC = next
+		return 0, 0, false
+
+	// Instructions with arguments of one immediate
+	case opcode == Ecalli:
+		lX := min(4, skip)
+		return HostCall, m.immediate(pc+1, lX), true
+
+	// Instructions with arguments of one register and one extended width immediate
+	case opcode == LoadImm64:
+		rA := m.register(pc+1, 0)
+		m.Registers[rA] = m.immediate(pc+2, 8)
+		m.PC = next
+		return 0, 0, false
+
+	// Instructions with arguments of two immediates
+	case opcode >= StoreImmU8 && opcode <= StoreImmU64:
+		lX := min(4, uint32(m.Program.codeAt(pc+1)%8))
+		lY := min(4, subtractOrZero(skip, lX+1))
+		vX := m.immediate(pc+2, lX)
+		vY := m.immediate(pc+2+lX, lY)
+		return m.store(uint32(vX), vY, storeSize(opcode-StoreImmU8), next)
+
+	// Instructions with arguments of one offset
+	case opcode == Jump:
+		lX := min(4, skip)
+		return m.branch(m.offset(pc+1, lX), true, next)
+
+	// Instructions with arguments of one register and one immediate
+	case opcode >= JumpInd && opcode <= StoreU64:
+		rA := m.register(pc+1, 0)
+		lX := min(4, subtractOrZero(skip, 1))
+		vX := m.immediate(pc+2, lX)
+		switch {
+		case opcode == JumpInd:
+			return m.dynamicJump(uint32(m.Registers[rA] + vX))
+		case opcode == LoadImm:
+			m.Registers[rA] = vX
+			m.PC = next
+			return 0, 0, false
+		case opcode >= LoadU8 && opcode <= LoadU64:
+			size, signed := loadSize(opcode - LoadU8)
+			return m.load(rA, uint32(vX), size, signed, next)
+		default:
+			return m.store(uint32(vX), m.Registers[rA], storeSize(opcode-StoreU8), next)
+		}
+
+	// Instructions with arguments of one register and two immediates
+	case opcode >= StoreImmIndU8 && opcode <= StoreImmIndU64:
+		rA := m.register(pc+1, 0)
+		lX := min(4, uint32(m.Program.codeAt(pc+1)/16%8))
+		lY := min(4, subtractOrZero(skip, lX+1))
+		vX := m.immediate(pc+2, lX)
+		vY := m.immediate(pc+2+lX, lY)
+		return m.store(uint32(m.Registers[rA]+vX), vY, storeSize(opcode-StoreImmIndU8), next)
+
+	// Instructions with arguments of one register, one immediate and one offset
+	case opcode >= LoadImmJump && opcode <= BranchGtSImm:
+		rA := m.register(pc+1, 0)
+		lX := min(4, uint32(m.Program.codeAt(pc+1)/16%8))
+		lY := min(4, subtractOrZero(skip, lX+1))
+		vX := m.immediate(pc+2, lX)
+		vY := m.offset(pc+2+lX, lY)
+		if opcode == LoadImmJump {
+			m.Registers[rA] = vX
+			return m.branch(vY, true, next)
+		}
+		return m.branch(vY, compare(immediateBranchConditions[opcode-BranchEqImm], m.Registers[rA], vX), next)
+
+	// Instructions with arguments of two registers
+	case opcode >= MoveReg && opcode <= ReverseBytes:
+		rD := m.register(pc+1, 0)
+		rA := m.register(pc+1, 1)
+		m.Registers[rD] = m.twoRegisters(opcode, m.Registers[rA])
+		m.PC = next
+		return 0, 0, false
+
+	// Instructions with arguments of two registers and one immediate
+	case opcode >= StoreIndU8 && opcode <= RotR32ImmAlt:
+		rA := m.register(pc+1, 0)
+		rB := m.register(pc+1, 1)
+		lX := min(4, subtractOrZero(skip, 1))
+		vX := m.immediate(pc+2, lX)
+		switch {
+		case opcode <= StoreIndU64:
+			return m.store(uint32(m.Registers[rB]+vX), m.Registers[rA], storeSize(opcode-StoreIndU8), next)
+		case opcode <= LoadIndU64:
+			size, signed := loadSize(opcode - LoadIndU8)
+			return m.load(rA, uint32(m.Registers[rB]+vX), size, signed, next)
+		default:
+			m.Registers[rA] = twoRegistersAndImmediate(opcode, m.Registers[rA], m.Registers[rB], vX)
+			m.PC = next
+			return 0, 0, false
+		}
+
+	// Instructions with arguments of two registers and one offset
+	case opcode >= BranchEq && opcode <= BranchGeS:
+		rA := m.register(pc+1, 0)
+		rB := m.register(pc+1, 1)
+		lX := min(4, subtractOrZero(skip, 1))
+		vX := m.offset(pc+2, lX)
+		return m.branch(vX, compare(registerBranchConditions[opcode-BranchEq], m.Registers[rA], m.Registers[rB]), next)
+
+	// Instructions with arguments of two registers and two immediates
+	case opcode == LoadImmJumpInd:
+		rA := m.register(pc+1, 0)
+		rB := m.register(pc+1, 1)
+		lX := min(4, uint32(m.Program.codeAt(pc+2)%8))
+		lY := min(4, subtractOrZero(skip, lX+2))
+		vX := m.immediate(pc+3, lX)
+		vY := m.immediate(pc+3+lX, lY)
+		target := uint32(m.Registers[rB] + vY)
+		m.Registers[rA] = vX
+		return m.dynamicJump(target)
+
+	// Instructions with arguments of three registers
+	case opcode >= Add32 && opcode <= MinU:
+		rA := m.register(pc+1, 0)
+		rB := m.register(pc+1, 1)
+		rD := min(12, int(m.Program.codeAt(pc+2))) // r_D = min(12, ζ_ı+2), the whole octet rather than its low 4 bits.
+		m.Registers[rD] = threeRegisters(opcode, m.Registers[rA], m.Registers[rB], m.Registers[rD])
+		m.PC = next
+		return 0, 0, false
+	}
+
+	// Undefined opcodes are treated as trap.
+	return Panic, 0, true
+}
+
+// register decodes the register index from the low (nibble = 0) or high (nibble = 1) 4 bits of the octet.
+func (m *Machine) register(at uint32, nibble int) int {
+	octet := m.Program.codeAt(at)
+	if nibble == 1 {
+		octet >>= 4
+	}
+	return min(12, int(octet%16))
+}
+
+// immediate decodes the length octets at the pc as a little endian integer, sign extended to 64 bit, X_l(E^-1_l(ζ_at...+l)).
+func (m *Machine) immediate(at uint32, length uint32) uint64 {
+	var value uint64
+	for i := range length {
+		value |= uint64(m.Program.codeAt(at+i)) << (8 * i)
+	}
+	return signExtend(value, length)
+}
+
+// offset decodes the relative branch target, ı + Z_l(E^-1_l(ζ_at...+l)).
+func (m *Machine) offset(at uint32, length uint32) uint32 {
+	return m.PC + uint32(m.immediate(at, length))
+}
+
+// branch(b, C) ≡ (▸, ı) if ¬C, (☇, ı) if b ∉ ϖ, (▸, b) otherwise
+func (m *Machine) branch(target uint32, condition bool, next uint32) (ExitReason, uint64, bool) {
+	if !condition {
+		m.PC = next
+		return 0, 0, false
+	}
+	if !m.Program.isBasicBlockStart(target) {
+		return Panic, 0, true
+	}
+
+	m.PC = target
+	return 0, 0, false
+}
+
+// djump(a) ≡ (∎, ı) if a = 2³² − 2¹⁶,
+// (☇, ı) if a = 0 ∨ a > |j|·Z_A ∨ a mod Z_A ≠ 0 ∨ j_(a/Z_A)−1 ∉ ϖ,
+// (▸, j_(a/Z_A)−1) otherwise
+func (m *Machine) dynamicJump(address uint32) (ExitReason, uint64, bool) {
+	if address == HaltAddress {
+		return Halt, 0, true
+	}
+
+	jumpTable := m.Program.JumpTable
+	if address == 0 || uint64(address) > uint64(len(jumpTable))*JumpAlignment || address%JumpAlignment != 0 {
+		return Panic, 0, true
+	}
+
+	target := jumpTable[address/JumpAlignment-1]
+	if !m.Program.isBasicBlockStart(target) {
+		return Panic, 0, true
+	}
+
+	m.PC = target
+	return 0, 0, false
+}
+
+func (m *Machine) load(rA int, address uint32, size uint32, signed bool, next uint32) (ExitReason, uint64, bool) {
+	data, err := m.Memory.Read(address, size)
+	if err != nil {
+		return m.fault(err)
+	}
+
+	var value uint64
+	for i, b := range data {
+		value |= uint64(b) << (8 * i)
+	}
+	if signed {
+		value = signExtend(value, size)
+	}
+
+	m.Registers[rA] = value
+	m.PC = next
+	return 0, 0, false
+}
+
+func (m *Machine) store(address uint32, value uint64, size uint32, next uint32) (ExitReason, uint64, bool) {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(value >> (8 * i))
+	}
+
+	if err := m.Memory.Write(address, data); err != nil {
+		return m.fault(err)
+	}
+
+	m.PC = next
+	return 0, 0, false
+}
+
+// Accessing the reserved lowest addresses is a panic, otherwise a page fault at the inaccessible page.
+func (m *Machine) fault(err error) (ExitReason, uint64, bool) {
+	var pageFault *PageFaultError
+	if !errors.As(err, &pageFault) || pageFault.Address < reservedMemorySize {
+		return Panic, 0, true
+	}
+
+	return PageFault, uint64(pageFault.Address), true
+}
+
+func (m *Machine) twoRegisters(opcode Opcode, a uint64) uint64 {
+	switch opcode {
+	case MoveReg:
+		return a
+	case Sbrk:
+		return m.Memory.sbrk(a)
+	case CountSetBits64:
+		return uint64(bits.OnesCount64(a))
+	case CountSetBits32:
+		return uint64(bits.OnesCount32(uint32(a)))
+	case LeadingZeroBits64:
+		return uint64(bits.LeadingZeros64(a))
+	case LeadingZeroBits32:
+		return uint64(bits.LeadingZeros32(uint32(a)))
+	case TrailingZeroBits64:
+		return uint64(bits.TrailingZeros64(a))
+	case TrailingZeroBits32:
+		return uint64(bits.TrailingZeros32(uint32(a)))
+	case SignExtend8:
+		return signExtend(a&0xff, 1)
+	case SignExtend16:
+		return signExtend(a&0xffff, 2)
+	case ZeroExtend16:
+		return a & 0xffff
+	default: // ReverseBytes
+		return bits.ReverseBytes64(a)
+	}
+}
+
+// rA is the destination register, and b, x are the register ω_B and the immediate ν_X.
+func twoRegistersAndImmediate(opcode Opcode, a, b, x uint64) uint64 {
+	switch opcode {
+	case AddImm32:
+		return signExtend32(uint32(b + x))
+	case AndImm:
+		return b & x
+	case XorImm:
+		return b ^ x
+	case OrImm:
+		return b | x
+	case MulImm32:
+		return signExtend32(uint32(b * x))
+	case SetLtUImm:
+		return boolToUint(b < x)
+	case SetLtSImm:
+		return boolToUint(int64(b) < int64(x))
+	case ShloLImm32:
+		return signExtend32(uint32(b) << (x % 32))
+	case ShloRImm32:
+		return signExtend32(uint32(b) >> (x % 32))
+	case SharRImm32:
+		return uint64(int64(int32(b) >> (x % 32)))
+	case NegAddImm32:
+		return signExtend32(uint32(x - b))
+	case SetGtUImm:
+		return boolToUint(b > x)
+	case SetGtSImm:
+		return boolToUint(int64(b) > int64(x))
+	case ShloLImmAlt32:
+		return signExtend32(uint32(x) << (b % 32))
+	case ShloRImmAlt32:
+		return signExtend32(uint32(x) >> (b % 32))
+	case SharRImmAlt32:
+		return uint64(int64(int32(x) >> (b % 32)))
+	case CmovIzImm:
+		if b == 0 {
+			return x
+		}
+		return a
+	case CmovNzImm:
+		if b != 0 {
+			return x
+		}
+		return a
+	case AddImm64:
+		return b + x
+	case MulImm64:
+		return b * x
+	case ShloLImm64:
+		return b << (x % 64)
+	case ShloRImm64:
+		return b >> (x % 64)
+	case SharRImm64:
+		return uint64(int64(b) >> (x % 64))
+	case NegAddImm64:
+		return x - b
+	case ShloLImmAlt64:
+		return x << (b % 64)
+	case ShloRImmAlt64:
+		return x >> (b % 64)
+	case SharRImmAlt64:
+		return uint64(int64(x) >> (b % 64))
+	case RotR64Imm:
+		return bits.RotateLeft64(b, -int(x%64))
+	case RotR64ImmAlt:
+		return bits.RotateLeft64(x, -int(b%64))
+	case RotR32Imm:
+		return signExtend32(bits.RotateLeft32(uint32(b), -int(x%32)))
+	default: // RotR32ImmAlt
+		return signExtend32(bits.RotateLeft32(uint32(x), -int(b%32)))
+	}
+}
+
+// a, b are the source registers ω_A, ω_B and d is the destination register ω_D.
+func threeRegisters(opcode Opcode, a, b, d uint64) uint64 {
+	switch opcode {
+	case Add32:
+		return signExtend32(uint32(a + b))
+	case Sub32:
+		return signExtend32(uint32(a - b))
+	case Mul32:
+		return signExtend32(uint32(a * b))
+	case DivU32:
+		if uint32(b) == 0 {
+			return math.MaxUint64
+		}
+		return signExtend32(uint32(a) / uint32(b))
+	case DivS32:
+		x, y := int32(a), int32(b)
+		switch {
+		case y == 0:
+			return math.MaxUint64
+		case x == math.MinInt32 && y == -1:
+			return uint64(int64(x))
+		}
+		return uint64(int64(x / y))
+	case RemU32:
+		if uint32(b) == 0 {
+			return signExtend32(uint32(a))
+		}
+		return signExtend32(uint32(a) % uint32(b))
+	case RemS32:
+		x, y := int32(a), int32(b)
+		switch {
+		case y == 0:
+			return uint64(int64(x))
+		case x == math.MinInt32 && y == -1:
+			return 0
+		}
+		return uint64(int64(x % y))
+	case ShloL32:
+		return signExtend32(uint32(a) << (b % 32))
+	case ShloR32:
+		return signExtend32(uint32(a) >> (b % 32))
+	case SharR32:
+		return uint64(int64(int32(a) >> (b % 32)))
+	case Add64:
+		return a + b
+	case Sub64:
+		return a - b
+	case Mul64:
+		return a * b
+	case DivU64:
+		if b == 0 {
+			return math.MaxUint64
+		}
+		return a / b
+	case DivS64:
+		x, y := int64(a), int64(b)
+		switch {
+		case y == 0:
+			return math.MaxUint64
+		case x == math.MinInt64 && y == -1:
+			return a
+		}
+		return uint64(x / y)
+	case RemU64:
+		if b == 0 {
+			return a
+		}
+		return a % b
+	case RemS64:
+		x, y := int64(a), int64(b)
+		switch {
+		case y == 0:
+			return a
+		case x == math.MinInt64 && y == -1:
+			return 0
+		}
+		return uint64(x % y)
+	case ShloL64:
+		return a << (b % 64)
+	case ShloR64:
+		return a >> (b % 64)
+	case SharR64:
+		return uint64(int64(a) >> (b % 64))
+	case And:
+		return a & b
+	case Xor:
+		return a ^ b
+	case Or:
+		return a | b
+	case MulUpperSS:
+		return mulUpperSigned(int64(a), int64(b))
+	case MulUpperUU:
+		hi, _ := bits.Mul64(a, b)
+		return hi
+	case MulUpperSU:
+		// ⌊Z8(a)·b ÷ 2⁶⁴⌋: the unsigned product, corrected by b·2⁶⁴ when a is negative.
+		hi, _ := bits.Mul64(a, b)
+		if int64(a) < 0 {
+			hi -= b
+		}
+		return hi
+	case SetLtU:
+		return boolToUint(a < b)
+	case SetLtS:
+		return boolToUint(int64(a) < int64(b))
+	case CmovIz:
+		if b == 0 {
+			return a
+		}
+		return d
+	case CmovNz:
+		if b != 0 {
+			return a
+		}
+		return d
+	case RotL64:
+		return bits.RotateLeft64(a, int(b%64))
+	case RotL32:
+		return signExtend32(bits.RotateLeft32(uint32(a), int(b%32)))
+	case RotR64:
+		return bits.RotateLeft64(a, -int(b%64))
+	case RotR32:
+		return signExtend32(bits.RotateLeft32(uint32(a), -int(b%32)))
+	case AndInv:
+		return a &^ b
+	case OrInv:
+		return a | ^b
+	case Xnor:
+		return ^(a ^ b)
+	case Max:
+		return uint64(max(int64(a), int64(b)))
+	case MaxU:
+		return max(a, b)
+	case Min:
+		return uint64(min(int64(a), int64(b)))
+	default: // MinU
+		return min(a, b)
+	}
+}
+
+type condition int
+
+const (
+	equal condition = iota
+	notEqual
+	lessUnsigned
+	lessOrEqualUnsigned
+	greaterOrEqualUnsigned
+	greaterUnsigned
+	lessSigned
+	lessOrEqualSigned
+	greaterOrEqualSigned
+	greaterSigned
+)
+
+// Branch conditions in the order of the opcodes.
+var (
+	immediateBranchConditions = [...]condition{equal, notEqual, lessUnsigned, lessOrEqualUnsigned, greaterOrEqualUnsigned, greaterUnsigned, lessSigned, lessOrEqualSigned, greaterOrEqualSigned, greaterSigned}
+	registerBranchConditions  = [...]condition{equal, notEqual, lessUnsigned, lessSigned, greaterOrEqualUnsigned, greaterOrEqualSigned}
+)
+
+func compare(c condition, a, b uint64) bool {
+	switch c {
+	case equal:
+		return a == b
+	case notEqual:
+		return a != b
+	case lessUnsigned:
+		return a < b
+	case lessOrEqualUnsigned:
+		return a <= b
+	case greaterOrEqualUnsigned:
+		return a >= b
+	case greaterUnsigned:
+		return a > b
+	case lessSigned:
+		return int64(a) < int64(b)
+	case lessOrEqualSigned:
+		return int64(a) <= int64(b)
+	case greaterOrEqualSigned:
+		return int64(a) >= int64(b)
+	default: // greaterSigned
+		return int64(a) > int64(b)
+	}
+}
+
+// ⌊Z8(a)·Z8(b) ÷ 2⁶⁴⌋
+func mulUpperSigned(a, b int64) uint64 {
+	hi, _ := bits.Mul64(uint64(a), uint64(b))
+	if a < 0 {
+		hi -= uint64(b)
+	}
+	if b < 0 {
+		hi -= uint64(a)
+	}
+	return hi
+}
+
+// load instructions in the order of u8, i8, u16, i16, u32, i32, u64.
+func loadSize(index Opcode) (uint32, bool) {
+	return 1 << (index / 2), index%2 == 1 && index < 6
+}
+
+// store instructions in the order of u8, u16, u32, u64.
+func storeSize(index Opcode) uint32 {
+	return 1 << index
+}
+
+// X_n(x) ≡ x + ⌊x ÷ 2^(8n−1)⌋(2⁶⁴ − 2^(8n))
+func signExtend(value uint64, length uint32) uint64 {
+	if length == 0 || length >= 8 {
+		return value
+	}
+	shift := 64 - 8*length
+	return uint64(int64(value<<shift) >> shift)
+}
+
+func signExtend32(value uint32) uint64 {
+	return uint64(int64(int32(value)))
+}
+
+func subtractOrZero(a, b uint32) uint32 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package pvm
+
+import "github.com/pkg/errors"
+
+// HostCallHandler handles the host call identified by the id, Ω in the Gray Paper (A.35).
+// It reports the exit reason and whether the machine exits, otherwise the execution continues after the host call.
+type HostCallHandler func(id uint64, m *Machine) (ExitReason, bool)
+
+// RunWithHostCalls runs the machine, dispatching host calls to the handler until it exits otherwise, Ψ_H defined in the Gray Paper (A.34).
+func (m *Machine) RunWithHostCalls(handler HostCallHandler) (ExitReason, uint64) {
+	for {
+		reason, value := m.Run()
+		if reason != HostCall {
+			return reason, value
+		}
+
+		if reason, exited := handler(value, m); exited {
+			return reason, 0
+		}
+		m.ResumeAfterHostCall()
+	}
+}
+
+// ConsumeGas deducts the gas charged by a host call, and reports whether enough gas remained.
+func (m *Machine) ConsumeGas(gas Gas) bool {
+	m.Gas -= gas
+	return m.Gas >= 0
+}
+
+// ReadMemory reads the length octets at the address given by registers, which must lie within the 32 bit address space.
+func (m *Machine) ReadMemory(address uint64, length uint64) ([]byte, error) {
+	if address+length > 1<<32 || address+length < address {
+		return nil, errors.WithMessagef(ErrInvalidMemoryRange, "address %#x, length %d", address, length)
+	}
+	return m.Memory.Read(uint32(address), uint32(length))
+}
+
+// WriteMemory writes the data at the address given by a register, which must lie within the 32 bit address space.
+func (m *Machine) WriteMemory(address uint64, data []byte) error {
+	if address+uint64(len(data)) > 1<<32 || address+uint64(len(data)) < address {
+		return errors.WithMessagef(ErrInvalidMemoryRange, "address %#x, length %d", address, len(data))
+	}
+	return m.Memory.Write(uint32(address), data)
+}
+
+// Invoke initializes the standard program with the arguments and runs it from the pc, Ψ_M defined in the Gray Paper (A.44).
+// It returns the gas used and the exit reason, either Halt, Panic or OutOfGas.
+// The output is the memory µ′[ω′7...+ω′8] on Halt, or empty if it is inaccessible.
+func Invoke(blob []byte, pc uint32, gas Gas, arguments []byte, handler HostCallHandler) (Gas, []byte, ExitReason) {
+	program, registers, memory, err := InitStandardProgram(blob, arguments)
+	if err != nil {
+		return 0, nil, Panic
+	}
+
+	m := NewMachine(program, pc, gas, registers, memory)
+	reason, _ := m.RunWithHostCalls(handler)
+	used := gas - max(m.Gas, 0)
+
+	switch reason {
+	case Halt:
+		output, err := m.ReadMemory(m.Registers[7], m.Registers[8])
+		if err != nil {
+			return used, []byte{}, Halt
+		}
+		return used, output, Halt
+	case OutOfGas:
+		return used, nil, OutOfGas
+	default:
+		return used, nil, Panic
+	}
+}
@@ -0,0 +1,152 @@
+package pvm
+
+import (
+	"fmt"
+)
+
+// Access is the permission of a memory page.
+type Access uint8
+
+const (
+	Inaccessible Access = iota // ∅
+	ReadOnly                   // R
+	Writable                   // W
+)
+
+// PageFaultError is returned when an inaccessible address is accessed.
+// Address is the start of the lowest inaccessible page.
+type PageFaultError struct {
+	Address uint32
+}
+
+func (f *PageFaultError) Error() string {
+	return fmt.Sprintf("page fault at %#x", f.Address)
+}
+
+type page struct {
+	access Access
+	data   [PageSize]byte
+}
+
+// Memory is the RAM µ ≡ (v ∈ Y2^32, a ∈ ⟦{W, R, ∅}⟧p), addressed by 32 bit and divided into pages of Z_P octets.
+type Memory struct {
+	pages   map[uint32]*page
+	heapTop uint32
+}
+
+func NewMemory() *Memory {
+	return &Memory{pages: make(map[uint32]*page)}
+}
+
+// SetAccess sets the access of all pages overlapping with [address, address + length).
+func (m *Memory) SetAccess(address uint32, length uint32, access Access) {
+	if length == 0 {
+		return
+	}
+
+	last := uint32((uint64(address) + uint64(length) - 1) / PageSize)
+	for index := address / PageSize; index <= last; index++ {
+		p, ok := m.pages[index]
+		if !ok {
+			p = &page{}
+			m.pages[index] = p
+		}
+		p.access = access
+	}
+}
+
+// SetHeapTop sets the address from which sbrk extends the heap.
+func (m *Memory) SetHeapTop(address uint32) {
+	m.heapTop = address
+}
+
+// Access returns the access of the page containing the address.
+func (m *Memory) Access(address uint32) Access {
+	if p, ok := m.pages[address/PageSize]; ok {
+		return p.access
+	}
+	return Inaccessible
+}
+
+// Read reads the length octets at the address, µ_v[address...+length].
+func (m *Memory) Read(address uint32, length uint32) ([]byte, error) {
+	if err := m.check(address, length, ReadOnly); err != nil {
+		return nil, err
+	}
+
+	return m.Get(address, length), nil
+}
+
+// Write writes the data at the address, when all of the target pages are writable.
+func (m *Memory) Write(address uint32, data []byte) error {
+	if err := m.check(address, uint32(len(data)), Writable); err != nil {
+		return err
+	}
+
+	m.Set(address, data)
+	return nil
+}
+
+// Get reads the memory regardless of the page access, inaccessible pages read as zeros.
+func (m *Memory) Get(address uint32, length uint32) []byte {
+	data := make([]byte, length)
+	for i := range length {
+		if p, ok := m.pages[(address+i)/PageSize]; ok {
+			data[i] = p.data[(address+i)%PageSize]
+		}
+	}
+	return data
+}
+
+// Set writes the memory regardless of the page access, allocating inaccessible pages if not mapped yet.
+func (m *Memory) Set(address uint32, data []byte) {
+	for i, b := range data {
+		index := (address + uint32(i)) / PageSize
+		p, ok := m.pages[index]
+		if !ok {
+			p = &page{}
+			m.pages[index] = p
+		}
+		p.data[(address+uint32(i))%PageSize] = b
+	}
+}
+
+// check returns the page fault of the lowest address in [address, address + length) not satisfying the access.
+// Addresses wrap around at 2³².
+func (m *Memory) check(address uint32, length uint32, access Access) error {
+	for offset := uint64(0); offset < uint64(length); {
+		current := address + uint32(offset)
+		p, ok := m.pages[current/PageSize]
+		if !ok || p.access < access {
+			return &PageFaultError{Address: current / PageSize * PageSize}
+		}
+		offset += uint64(PageSize - current%PageSize)
+	}
+
+	return nil
+}
+
+// sbrk extends the heap by the size, mapping new pages as writable, and returns the previous heap top.
+// It returns 0 if the heap cannot be extended.
+func (m *Memory) sbrk(size uint64) uint64 {
+	top := m.heapTop
+	if size == 0 {
+		return uint64(top)
+	}
+	if uint64(top)+size > 1<<32 {
+		return 0
+	}
+
+	next := uint64(top) + size
+	for index := uint64(top) / PageSize; index < (next+PageSize-1)/PageSize; index++ {
+		p, ok := m.pages[uint32(index)]
+		if !ok {
+			p = &page{}
+			m.pages[uint32(index)] = p
+		}
+		p.access = Writable
+	}
+	m.heapTop = uint32(min(next, 1<<32-1))
+
+	return uint64(top)
+}
@@ -0,0 +1,42 @@
+package pvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	memory := NewMemory()
+	memory.SetAccess(0x10000, PageSize, ReadOnly)
+	memory.SetAccess(0x11000, PageSize, Writable)
+
+	// Writes across the read only page fault at the read only page.
+	err := memory.Write(0x10ffe, []byte{1, 2, 3, 4})
+	require.Equal(t, &PageFaultError{Address: 0x10000}, err)
+
+	require.NoError(t, memory.Write(0x11ffe, []byte{1, 2}))
+	data, err := memory.Read(0x11ffe, 2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, data)
+
+	// Reads across the end of the writable page fault at the next page.
+	_, err = memory.Read(0x11ffe, 4)
+	require.Equal(t, &PageFaultError{Address: 0x12000}, err)
+
+	// Reads wrap around at 2³².
+	_, err = memory.Read(0xffffffff, 2)
+	require.Equal(t, &PageFaultError{Address: 0xfffff000}, err)
+}
+
+func TestSbrk(t *testing.T) {
+	memory := NewMemory()
+	memory.SetAccess(0x10000, PageSize, Writable)
+	memory.SetHeapTop(0x11000)
+
+	require.Equal(t, uint64(0x11000), memory.sbrk(0))
+	require.Equal(t, uint64(0x11000), memory.sbrk(PageSize+1))
+	require.Equal(t, Writable, memory.Access(0x12000))
+	require.Equal(t, Inaccessible, memory.Access(0x13000))
+	require.Equal(t, uint64(0x12001), memory.sbrk(0))
+}
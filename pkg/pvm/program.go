@@ -0,0 +1,115 @@
+package pvm
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/pkg/codec"
+)
+
+// Program is a deserialized program blob, Gray Paper (A.2)
+// p = E(|j|) ⌢ E1(z) ⌢ E(|c|) ⌢ Ez(j) ⌢ E(c) ⌢ E(k), |k| = |c|
+type Program struct {
+	JumpTable []uint32 // j: dynamic jump targets
+	Code      []byte   // c: instruction data
+	Bitmask   []bool   // k: marks the opcode octets of c
+
+	basicBlocks []bool // ϖ: start of basic blocks
+}
+
+func ParseProgram(blob []byte) (*Program, error) {
+	d := codec.NewDecoder(blob)
+
+	jumpTableLength, err := d.DecodeNatural()
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode jump table length: %v", err)
+	}
+	jumpTableEntrySize, err := d.ReadByte()
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode jump table entry size: %v", err)
+	}
+	if jumpTableEntrySize > 4 {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "jump table entry size %d must be at most 4", jumpTableEntrySize)
+	}
+	codeLength, err := d.DecodeNatural()
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode code length: %v", err)
+	}
+
+	bitmaskLength := (codeLength + 7) / 8
+	if uint64(d.Remaining()) != jumpTableLength*uint64(jumpTableEntrySize)+codeLength+bitmaskLength {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "program blob length does not match jump table length %d and code length %d", jumpTableLength, codeLength)
+	}
+
+	jumpTable := make([]uint32, jumpTableLength)
+	for i := range jumpTable {
+		entry, err := d.DecodeUint(int(jumpTableEntrySize))
+		if err != nil {
+			return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode jump table entry: %v", err)
+		}
+		jumpTable[i] = uint32(entry)
+	}
+
+	code, err := d.ReadBytes(int(codeLength))
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode code: %v", err)
+	}
+	bitmask, err := d.ReadBytes(int(bitmaskLength))
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidProgram, "failed to decode bitmask: %v", err)
+	}
+
+	return NewProgram(jumpTable, code, codec.DecodeBitSequence(bitmask, int(codeLength))), nil
+}
+
+func NewProgram(jumpTable []uint32, code []byte, bitmask []bool) *Program {
+	p := &Program{JumpTable: jumpTable, Code: code, Bitmask: bitmask}
+
+	// (A.5) ϖ ≡ {0} ∪ {n + 1 + skip(n) ∣ n ∈ N|c| ∧ kn = 1 ∧ cn ∈ T}
+	p.basicBlocks = make([]bool, len(code))
+	if len(code) > 0 {
+		p.basicBlocks[0] = true
+	}
+	for n := range code {
+		if p.isInstruction(uint32(n)) && isTermination(Opcode(code[n])) {
+			next := uint32(n) + 1 + p.skip(uint32(n))
+			if next < uint32(len(code)) {
+				p.basicBlocks[next] = true
+			}
+		}
+	}
+
+	return p
+}
+
+func (p *Program) isInstruction(pc uint32) bool {
+	return pc < uint32(len(p.Bitmask)) && p.Bitmask[pc]
+}
+
+// isBasicBlockStart reports whether the pc is a valid branch target.
+func (p *Program) isBasicBlockStart(pc uint32) bool {
+	return pc < uint32(len(p.basicBlocks)) && p.basicBlocks[pc] && p.isInstruction(pc)
+}
+
+// (A.3) skip(i) ≡ min(24, j ∈ N ∶ (k ⌢ [1, 1, ...])i+1+j = 1)
+func (p *Program) skip(pc uint32) uint32 {
+	for j := range uint32(24) {
+		next := pc + 1 + j
+		if next >= uint32(len(p.Bitmask)) || p.Bitmask[next] {
+			return j
+		}
+	}
+
+	return 24
+}
+
+// (A.4) ζ ≡ c ⌢ [0, 0, ...]
+func (p *Program) codeAt(pc uint32) byte {
+	if pc >= uint32(len(p.Code)) {
+		return 0
+	}
+	return p.Code[pc]
+}
+
+// NextInstruction returns the pc of the instruction following the one at the pc, ı + 1 + skip(ı).
+func (p *Program) NextInstruction(pc uint32) uint32 {
+	return pc + 1 + p.skip(pc)
+}
@@ -0,0 +1,33 @@
+package pvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProgram(t *testing.T) {
+	// |j| = 1, z = 1, |c| = 2, j = [0], c = [fallthrough, trap], k = [1, 1]
+	program, err := ParseProgram([]byte{1, 1, 2, 0, 1, 0, 0b11})
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0}, program.JumpTable)
+	require.Equal(t, []byte{1, 0}, program.Code)
+	require.Equal(t, []bool{true, true}, program.Bitmask)
+	require.True(t, program.isBasicBlockStart(0))
+	require.True(t, program.isBasicBlockStart(1))
+
+	_, err = ParseProgram([]byte{1, 1, 2, 0, 1, 0})
+	require.ErrorIs(t, err, ErrInvalidProgram)
+
+	_, err = ParseProgram([]byte{0, 5, 0})
+	require.ErrorIs(t, err, ErrInvalidProgram)
+}
+
+func TestSkip(t *testing.T) {
+	program := NewProgram(nil, []byte{51, 7, 5, 0}, []bool{true, false, false, true})
+	require.Equal(t, uint32(2), program.skip(0))
+	require.Equal(t, uint32(0), program.skip(3))
+	require.Equal(t, uint32(3), program.NextInstruction(0))
+	require.False(t, program.isBasicBlockStart(1))
+	require.False(t, program.isBasicBlockStart(3))
+}
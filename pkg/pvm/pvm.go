@@ -0,0 +1,85 @@
+// Package pvm implements the Polkadot Virtual Machine defined in the Gray Paper Appendix A.
+package pvm
+
+const (
+	NumOfRegisters = 13   // The number of 64 bit registers ω.
+	PageSize       = 4096 // Z_P: The pvm memory page size.
+	JumpAlignment  = 2    // Z_A: The pvm dynamic address alignment factor.
+
+	// Dynamic jump to this address halts the machine.
+	HaltAddress = 1<<32 - 1<<16
+
+	// Memory access below this address panics instead of page faulting.
+	reservedMemorySize = 1 << 16
+)
+
+type Gas int64 // ϱ ∈ Z_G
+
+type Registers [NumOfRegisters]uint64 // ω ∈ ⟦N_R⟧13
+
+type ExitReason int
+
+const (
+	Halt      ExitReason = iota // ∎: regular halt
+	Panic                       // ☇: irregular halt
+	OutOfGas                    // ∞
+	PageFault                   // F: page fault, with the faulting address
+	HostCall                    // h̵: host call, with the host call identifier
+)
+
+func (r ExitReason) String() string {
+	switch r {
+	case Halt:
+		return "halt"
+	case Panic:
+		return "panic"
+	case OutOfGas:
+		return "out-of-gas"
+	case PageFault:
+		return "page-fault"
+	case HostCall:
+		return "host-call"
+	}
+	return "unknown"
+}
+
+// Machine is the pvm state (ı, ϱ, ω, µ) running the program c.
+type Machine struct {
+	Program   *Program
+	PC        uint32    // ı
+	Gas       Gas       // ϱ
+	Registers Registers // ω
+	Memory    *Memory   // µ
+}
+
+func NewMachine(program *Program, pc uint32, gas Gas, registers Registers, memory *Memory) *Machine {
+	return &Machine{Program: program, PC: pc, Gas: gas, Registers: registers, Memory: memory}
+}
+
+// Run executes the program until it exits, Ψ defined in the Gray Paper (A.1).
+// The returned value is the host call identifier for HostCall, and the page address for PageFault.
+// The pc is left at the instruction which caused the exit.
+func (m *Machine) Run() (ExitReason, uint64) {
+	for {
+		reason, value, exited := m.Step()
+		if exited {
+			return reason, value
+		}
+	}
+}
+
+// Step executes a single instruction, Ψ1. It reports whether the machine exited.
+func (m *Machine) Step() (ExitReason, uint64, bool) {
+	// Every instruction costs a unit of gas.
+	m.Gas--
+	if m.Gas < 0 {
+		return OutOfGas, 0, true
+	}
+
+	return m.execute()
+}
+
+// ResumeAfterHostCall moves the pc past the host call instruction, to continue the execution after it is handled.
+func (m *Machine) ResumeAfterHostCall() {
+	m.PC = m.Program.NextInstruction(m.PC)
+}
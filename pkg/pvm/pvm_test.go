@@ -0,0 +1,223 @@
+package pvm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           []byte
+		bitmask        []bool
+		jumpTable      []uint32
+		gas            Gas
+		memory         func() *Memory
+		expectedReason ExitReason
+		expectedValue  uint64
+		expectedPC     uint32
+		expectedGas    Gas
+		expectedRegs   func(*Registers)
+	}{
+		{
+			name: "add and trap",
+			// load_imm ω7 5, load_imm ω8 7, add_64 ω9 = ω7 + ω8, trap
+			code:           []byte{51, 0x07, 5, 51, 0x08, 7, 200, 0x87, 0x09, 0},
+			bitmask:        []bool{true, false, false, true, false, false, true, false, false, true},
+			gas:            100,
+			expectedReason: Panic,
+			expectedPC:     9,
+			expectedGas:    96,
+			expectedRegs: func(r *Registers) {
+				r[7], r[8], r[9] = 5, 7, 12
+			},
+		},
+		{
+			name: "three registers destination is clamped",
+			// load_imm ω7 5, load_imm ω8 7, add_64 ω12 = ω7 + ω8 encoded with the octet 0x19, trap
+			code:           []byte{51, 0x07, 5, 51, 0x08, 7, 200, 0x87, 0x19, 0},
+			bitmask:        []bool{true, false, false, true, false, false, true, false, false, true},
+			gas:            100,
+			expectedReason: Panic,
+			expectedPC:     9,
+			expectedGas:    96,
+			expectedRegs: func(r *Registers) {
+				r[7], r[8], r[12] = 5, 7, 12
+			},
+		},
+		{
+			name: "halt by dynamic jump",
+			// load_imm ω0 0xffff0000, jump_ind ω0 0
+			code:           []byte{51, 0x00, 0x00, 0x00, 0xff, 0xff, 50, 0x00},
+			bitmask:        []bool{true, false, false, false, false, false, true, false},
+			gas:            100,
+			expectedReason: Halt,
+			expectedPC:     6,
+			expectedGas:    98,
+			expectedRegs: func(r *Registers) {
+				r[0] = 0xffffffffffff0000
+			},
+		},
+		{
+			name: "dynamic jump through jump table",
+			// load_imm ω0 2, jump_ind ω0 0, trap, fallthrough, trap
+			code:           []byte{51, 0x00, 2, 50, 0x00, 0, 1, 0},
+			bitmask:        []bool{true, false, false, true, false, true, true, true},
+			jumpTable:      []uint32{6},
+			gas:            100,
+			expectedReason: Panic,
+			expectedPC:     7,
+			expectedGas:    96,
+			expectedRegs: func(r *Registers) {
+				r[0] = 2
+			},
+		},
+		{
+			name: "jump to non basic block start",
+			// jump +1
+			code:           []byte{40, 1, 0},
+			bitmask:        []bool{true, false, true},
+			gas:            100,
+			expectedReason: Panic,
+			expectedPC:     0,
+			expectedGas:    99,
+		},
+		{
+			name: "page fault",
+			// store_u8 ω7 0x20001
+			code:           []byte{59, 0x07, 0x01, 0x00, 0x02},
+			bitmask:        []bool{true, false, false, false, false},
+			gas:            100,
+			expectedReason: PageFault,
+			expectedValue:  0x20000,
+			expectedPC:     0,
+			expectedGas:    99,
+		},
+		{
+			name: "write to read only memory",
+			// store_u8 ω7 0x20001
+			code:    []byte{59, 0x07, 0x01, 0x00, 0x02},
+			bitmask: []bool{true, false, false, false, false},
+			gas:     100,
+			memory: func() *Memory {
+				memory := NewMemory()
+				memory.SetAccess(0x20000, PageSize, ReadOnly)
+				return memory
+			},
+			expectedReason: PageFault,
+			expectedValue:  0x20000,
+			expectedPC:     0,
+			expectedGas:    99,
+		},
+		{
+			name: "access to reserved memory",
+			// store_u8 ω7 0x100
+			code:           []byte{59, 0x07, 0x00, 0x01},
+			bitmask:        []bool{true, false, false, false},
+			gas:            100,
+			expectedReason: Panic,
+			expectedPC:     0,
+			expectedGas:    99,
+		},
+		{
+			name: "out of gas",
+			// fallthrough, fallthrough
+			code:           []byte{1, 1},
+			bitmask:        []bool{true, true},
+			gas:            1,
+			expectedReason: OutOfGas,
+			expectedPC:     1,
+			expectedGas:    -1,
+		},
+		{
+			name: "host call",
+			// ecalli 3
+			code:           []byte{10, 3, 0},
+			bitmask:        []bool{true, false, true},
+			gas:            100,
+			expectedReason: HostCall,
+			expectedValue:  3,
+			expectedPC:     0,
+			expectedGas:    99,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := NewMemory()
+			if tt.memory != nil {
+				memory = tt.memory()
+			}
+
+			machine := NewMachine(NewProgram(tt.jumpTable, tt.code, tt.bitmask), 0, tt.gas, Registers{}, memory)
+			reason, value := machine.Run()
+
+			var expectedRegs Registers
+			if tt.expectedRegs != nil {
+				tt.expectedRegs(&expectedRegs)
+			}
+
+			require.Equal(t, tt.expectedReason, reason)
+			require.Equal(t, tt.expectedValue, value)
+			require.Equal(t, tt.expectedPC, machine.PC)
+			require.Equal(t, tt.expectedGas, machine.Gas)
+			require.Equal(t, expectedRegs, machine.Registers)
+		})
+	}
+}
+
+func TestResumeAfterHostCall(t *testing.T) {
+	// ecalli 3, load_imm ω7 1, trap
+	program := NewProgram(nil, []byte{10, 3, 51, 0x07, 1, 0}, []bool{true, false, true, false, false, true})
+	machine := NewMachine(program, 0, 100, Registers{}, NewMemory())
+
+	reason, value := machine.Run()
+	require.Equal(t, HostCall, reason)
+	require.Equal(t, uint64(3), value)
+
+	machine.ResumeAfterHostCall()
+	require.Equal(t, uint32(2), machine.PC)
+
+	reason, _ = machine.Run()
+	require.Equal(t, Panic, reason)
+	require.Equal(t, uint64(1), machine.Registers[7])
+	require.Equal(t, uint32(5), machine.PC)
+}
+
+func TestThreeRegisters(t *testing.T) {
+	tests := []struct {
+		name     string
+		opcode   Opcode
+		a, b     uint64
+		expected uint64
+	}{
+		{name: "add_32 sign extends", opcode: Add32, a: 0x7fffffff, b: 1, expected: 0xffffffff80000000},
+		{name: "div_u_64 by zero", opcode: DivU64, a: 5, b: 0, expected: math.MaxUint64},
+		{name: "div_s_64 overflow", opcode: DivS64, a: 1 << 63, b: math.MaxUint64, expected: 1 << 63},
+		{name: "rem_u_32 by zero", opcode: RemU32, a: 0x80000000, b: 0, expected: 0xffffffff80000000},
+		{name: "rem_s_64 overflow", opcode: RemS64, a: 1 << 63, b: math.MaxUint64, expected: 0},
+		{name: "rem_s_64 takes sign of dividend", opcode: RemS64, a: uint64(math.MaxUint64 - 6), b: 3, expected: math.MaxUint64},
+		{name: "mul_upper_s_s", opcode: MulUpperSS, a: math.MaxUint64, b: math.MaxUint64, expected: 0},
+		{name: "mul_upper_s_u", opcode: MulUpperSU, a: math.MaxUint64, b: 2, expected: math.MaxUint64},
+		{name: "mul_upper_u_u", opcode: MulUpperUU, a: math.MaxUint64, b: 2, expected: 1},
+		{name: "rot_r_32", opcode: RotR32, a: 1, b: 1, expected: 0xffffffff80000000},
+		{name: "min", opcode: Min, a: math.MaxUint64, b: 1, expected: math.MaxUint64},
+		{name: "min_u", opcode: MinU, a: math.MaxUint64, b: 1, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, threeRegisters(tt.opcode, tt.a, tt.b, 0))
+		})
+	}
+}
+
+func TestSignExtend(t *testing.T) {
+	require.Equal(t, uint64(0x7f), signExtend(0x7f, 1))
+	require.Equal(t, uint64(0xffffffffffffff80), signExtend(0x80, 1))
+	require.Equal(t, uint64(0xffffffffffff8000), signExtend(0x8000, 2))
+	require.Equal(t, uint64(0), signExtend(0, 0))
+	require.Equal(t, uint64(1<<63), signExtend(1<<63, 8))
+}
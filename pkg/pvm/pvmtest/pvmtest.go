@@ -0,0 +1,68 @@
+// Package pvmtest builds PVM program blobs of a few instructions for the tests of the invocations running them,
+// and machines for the tests of the host calls.
+package pvmtest
+
+import (
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/pvm"
+)
+
+// Address is the start of the writable page of the machines built by Machine.
+const Address = 0x20000
+
+// Machine builds a machine of an empty program with the gas, of which the page at Address is writable.
+func Machine(gas pvm.Gas) *pvm.Machine {
+	memory := pvm.NewMemory()
+	memory.SetAccess(Address, pvm.PageSize, pvm.Writable)
+	return pvm.NewMachine(pvm.NewProgram(nil, nil, nil), 0, gas, pvm.Registers{}, memory)
+}
+
+var (
+	Halt = []byte{50, 0x00} // jump_ind ω0 0, ω0 is the halt address of a standard program.
+	Trap = []byte{0}
+)
+
+// LoadImm is load_imm ω_r of a 24 bit immediate.
+func LoadImm(register byte, value uint32) []byte {
+	return []byte{51, register, byte(value), byte(value >> 8), byte(value >> 16)}
+}
+
+// Ecalli is ecalli of the host call id.
+func Ecalli(id uint64) []byte {
+	return []byte{10, byte(id)}
+}
+
+// Program builds a program blob without jump table running the instructions, E(|j|) ⌢ E1(z) ⌢ E(|c|) ⌢ E_z(j) ⌢ E(c) ⌢ E(k).
+func Program(instructions ...[]byte) []byte {
+	var code []byte
+	var bitmask []bool
+	for _, instruction := range instructions {
+		code = append(code, instruction...)
+		bitmask = append(bitmask, true)
+		bitmask = append(bitmask, make([]bool, len(instruction)-1)...)
+	}
+
+	blob := append(codec.EncodeNatural(0), 0)
+	blob = append(blob, codec.EncodeNatural(uint64(len(code)))...)
+	blob = append(blob, code...)
+	return append(blob, codec.EncodeBitSequence(bitmask)...)
+}
+
+// StandardProgram builds a standard program blob with the read-only data and a zeroed read-write data zone of the size,
+// of which the entry point runs the instructions. The program counters before the entry point trap.
+// E3(|o|) ⌢ E3(|w|) ⌢ E2(z) ⌢ E3(s) ⌢ o ⌢ w ⌢ E4(|c|) ⌢ c
+func StandardProgram(entryPoint int, readOnlyData []byte, readWriteDataSize int, instructions ...[]byte) []byte {
+	for range entryPoint {
+		instructions = append([][]byte{Trap}, instructions...)
+	}
+	program := Program(instructions...)
+
+	blob := codec.EncodeUint(uint64(len(readOnlyData)), 3)
+	blob = append(blob, codec.EncodeUint(uint64(readWriteDataSize), 3)...)
+	blob = append(blob, codec.EncodeUint(0, 2)...)
+	blob = append(blob, codec.EncodeUint(0, 3)...)
+	blob = append(blob, readOnlyData...)
+	blob = append(blob, make([]byte, readWriteDataSize)...)
+	blob = append(blob, codec.EncodeUint(uint64(len(program)), 4)...)
+	return append(blob, program...)
+}
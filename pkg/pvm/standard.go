@@ -0,0 +1,103 @@
+package pvm
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/pkg/codec"
+)
+
+const (
+	ZoneSize       = 1 << 16 // Z_Z: The standard pvm program initialization zone size.
+	MaxInputSize   = 1 << 24 // Z_I: The standard pvm program initialization input data size.
+	addressSpace   = 1 << 32
+	argumentsStart = addressSpace - ZoneSize - MaxInputSize
+	stackTop       = addressSpace - 2*ZoneSize - MaxInputSize
+)
+
+// InitStandardProgram is the standard program initialization function Y defined in the Gray Paper (A.37) ~ (A.43).
+// It decodes the standard program blob p = E3(|o|) ⌢ E3(|w|) ⌢ E2(z) ⌢ E3(s) ⌢ o ⌢ w ⌢ E4(|c|) ⌢ c
+// into the program c, and lays out the read-only data o, the read-write data w with z heap pages,
+// the stack of s octets and the arguments a in the RAM.
+func InitStandardProgram(blob []byte, arguments []byte) (*Program, Registers, *Memory, error) {
+	d := codec.NewDecoder(blob)
+
+	var header [4]uint64
+	for i, l := range []int{3, 3, 2, 3} {
+		value, err := d.DecodeUint(l)
+		if err != nil {
+			return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "failed to decode header: %v", err)
+		}
+		header[i] = value
+	}
+	readOnlyLength, readWriteLength, heapPages, stackSize := header[0], header[1], header[2], header[3]
+
+	readOnlyData, err := d.ReadBytes(int(readOnlyLength))
+	if err != nil {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "failed to decode read-only data: %v", err)
+	}
+	readWriteData, err := d.ReadBytes(int(readWriteLength))
+	if err != nil {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "failed to decode read-write data: %v", err)
+	}
+	codeLength, err := d.DecodeUint(4)
+	if err != nil {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "failed to decode code length: %v", err)
+	}
+	if uint64(d.Remaining()) != codeLength {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "code length %d does not match remaining %d octets", codeLength, d.Remaining())
+	}
+	code, err := d.ReadBytes(int(codeLength))
+	if err != nil {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "failed to decode code: %v", err)
+	}
+
+	// (A.38) 5Z_Z + Z(|o|) + Z(|w| + zZ_P) + Z(s) + Z_I ≤ 2³²
+	if uint64(len(arguments)) > MaxInputSize {
+		return nil, Registers{}, nil, errors.WithMessagef(ErrInvalidStandardProgram, "arguments length %d exceeds %d", len(arguments), MaxInputSize)
+	}
+	if 5*ZoneSize+zoneAlign(readOnlyLength)+zoneAlign(readWriteLength+heapPages*PageSize)+zoneAlign(stackSize)+MaxInputSize > addressSpace {
+		return nil, Registers{}, nil, errors.WithMessage(ErrInvalidStandardProgram, "program does not fit in the address space")
+	}
+
+	program, err := ParseProgram(code)
+	if err != nil {
+		return nil, Registers{}, nil, err
+	}
+
+	// (A.41) Read-only data zone, followed by the read-write data zone with heap pages.
+	memory := NewMemory()
+	readOnlyStart := uint32(ZoneSize)
+	memory.SetAccess(readOnlyStart, uint32(pageAlign(readOnlyLength)), ReadOnly)
+	memory.Set(readOnlyStart, readOnlyData)
+
+	readWriteStart := uint32(2*ZoneSize + zoneAlign(readOnlyLength))
+	readWriteSize := pageAlign(readWriteLength) + heapPages*PageSize
+	memory.SetAccess(readWriteStart, uint32(readWriteSize), Writable)
+	memory.Set(readWriteStart, readWriteData)
+	memory.SetHeapTop(readWriteStart + uint32(readWriteSize))
+
+	// Stack zone, growing downwards from below the arguments zone.
+	memory.SetAccess(uint32(stackTop-pageAlign(stackSize)), uint32(pageAlign(stackSize)), Writable)
+
+	// Arguments zone.
+	memory.SetAccess(argumentsStart, uint32(pageAlign(uint64(len(arguments)))), ReadOnly)
+	memory.Set(argumentsStart, arguments)
+
+	// (A.43)
+	var registers Registers
+	registers[0] = HaltAddress
+	registers[1] = stackTop
+	registers[7] = argumentsStart
+	registers[8] = uint64(len(arguments))
+
+	return program, registers, memory, nil
+}
+
+// (A.39) P(x) ≡ Z_P⌈x / Z_P⌉
+func pageAlign(x uint64) uint64 {
+	return (x + PageSize - 1) / PageSize * PageSize
+}
+
+// (A.40) Z(x) ≡ Z_Z⌈x / Z_Z⌉
+func zoneAlign(x uint64) uint64 {
+	return (x + ZoneSize - 1) / ZoneSize * ZoneSize
+}
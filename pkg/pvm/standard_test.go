@@ -0,0 +1,58 @@
+package pvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitStandardProgram(t *testing.T) {
+	code := []byte{0, 0, 1, 0, 0b1} // |j| = 0, z = 0, |c| = 1, c = [trap], k = [1]
+
+	blob := []byte{
+		2, 0, 0, // |o|
+		1, 0, 0, // |w|
+		1, 0, // z
+		100, 0, 0, // s
+		0xaa, 0xbb, // o
+		0xcc,                     // w
+		byte(len(code)), 0, 0, 0} // |c|
+	blob = append(blob, code...)
+
+	program, registers, memory, err := InitStandardProgram(blob, []byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0}, program.Code)
+
+	var expectedRegisters Registers
+	expectedRegisters[0] = HaltAddress
+	expectedRegisters[1] = 1<<32 - 2*ZoneSize - MaxInputSize
+	expectedRegisters[7] = 1<<32 - ZoneSize - MaxInputSize
+	expectedRegisters[8] = 3
+	require.Equal(t, expectedRegisters, registers)
+
+	// Read-only data
+	require.Equal(t, ReadOnly, memory.Access(ZoneSize))
+	require.Equal(t, []byte{0xaa, 0xbb, 0}, memory.Get(ZoneSize, 3))
+	require.Equal(t, Inaccessible, memory.Access(ZoneSize+PageSize))
+
+	// Read-write data followed by a heap page
+	readWriteStart := uint32(3 * ZoneSize)
+	require.Equal(t, Writable, memory.Access(readWriteStart))
+	require.Equal(t, Writable, memory.Access(readWriteStart+PageSize))
+	require.Equal(t, Inaccessible, memory.Access(readWriteStart+2*PageSize))
+	require.Equal(t, []byte{0xcc}, memory.Get(readWriteStart, 1))
+	require.Equal(t, uint64(readWriteStart+2*PageSize), memory.sbrk(0))
+
+	// Stack
+	require.Equal(t, Writable, memory.Access(uint32(registers[1]-1)))
+	require.Equal(t, Inaccessible, memory.Access(uint32(registers[1]-PageSize-1)))
+
+	// Arguments
+	require.Equal(t, ReadOnly, memory.Access(uint32(registers[7])))
+	data, err := memory.Read(uint32(registers[7]), 3)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+
+	_, _, _, err = InitStandardProgram(blob[:len(blob)-1], nil)
+	require.ErrorIs(t, err, ErrInvalidStandardProgram)
+}
@@ -0,0 +1,102 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Binary Patricia Merkle trie defined in the Gray Paper Appendix D.2.
+//
+// Every node is 512 bits. The first bit discriminates branches (0) from leaves (1),
+// and for leaves the second bit discriminates embedded-value leaves (0) from regular leaves (1).
+// Keys are read MSB-first, and only the first 248 bits of a key are committed to in leaves.
+
+const (
+	// Values up to this size are embedded into the leaf node itself, otherwise their hash is.
+	MaxEmbeddedValueSize = 32
+
+	nodeSize = 64
+
+	embeddedLeafHead = 0b10000000
+	regularLeafHead  = 0b11000000
+	branchHeadMask   = 0b01111111
+)
+
+type entry struct {
+	key   common.Hash
+	value []byte
+}
+
+// Merklize computes the root of the trie composed of the given key-value pairs.
+// Gray Paper (D.6)
+//
+//	M(d) ≡ H_0 if |d| = 0,
+//	       H(bits⁻¹(L(k, v))) if V(d) = {(k, v)},
+//	       H(bits⁻¹(B(M(l), M(r)))) otherwise, where l and r are split by the next key bit.
+func Merklize(kvs map[common.Hash][]byte) common.Hash {
+	entries := make([]entry, 0, len(kvs))
+	for key, value := range kvs {
+		entries = append(entries, entry{key: key, value: value})
+	}
+	// Sorting MSB-first makes every split at bit i a contiguous partition.
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key[:], entries[j].key[:]) < 0
+	})
+
+	return merklize(entries, 0)
+}
+
+func merklize(entries []entry, i int) common.Hash {
+	switch len(entries) {
+	case 0:
+		return common.Hash{}
+	case 1:
+		leaf := EncodeLeaf(entries[0].key, entries[0].value)
+		return blake2b.Sum256(leaf[:])
+	}
+
+	split := sort.Search(len(entries), func(j int) bool {
+		return bit(entries[j].key, i)
+	})
+
+	branch := EncodeBranch(merklize(entries[:split], i+1), merklize(entries[split:], i+1))
+	return blake2b.Sum256(branch[:])
+}
+
+// EncodeBranch encodes a branch node, Gray Paper (D.3).
+// B(l, r) ≡ [0] ⌢ bits(l)₁... ⌢ bits(r)
+func EncodeBranch(left, right common.Hash) [nodeSize]byte {
+	var node [nodeSize]byte
+	copy(node[:32], left[:])
+	copy(node[32:], right[:])
+	node[0] &= branchHeadMask
+	return node
+}
+
+// EncodeLeaf encodes a leaf node, Gray Paper (D.4).
+//
+//	L(k, v) ≡ [1, 0] ⌢ bits(E₁(|v|))₂... ⌢ bits(k)...₂₄₈ ⌢ bits(v) ⌢ [0, 0, ...] if |v| ≤ 32,
+//	          [1, 1, 0, 0, 0, 0, 0, 0] ⌢ bits(k)...₂₄₈ ⌢ bits(H(v)) otherwise
+func EncodeLeaf(key common.Hash, value []byte) [nodeSize]byte {
+	var node [nodeSize]byte
+	copy(node[1:32], key[:31])
+
+	if len(value) <= MaxEmbeddedValueSize {
+		node[0] = embeddedLeafHead | byte(len(value))
+		copy(node[32:], value)
+	} else {
+		node[0] = regularLeafHead
+		valueHash := blake2b.Sum256(value)
+		copy(node[32:], valueHash[:])
+	}
+
+	return node
+}
+
+// i-th bit of the key, MSB-first.
+func bit(key common.Hash, i int) bool {
+	return key[i/8]&(0x80>>(i%8)) != 0
+}
@@ -0,0 +1,97 @@
+package trie_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/trie"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	vectorFolderPath = "../../@jamtestvectors-davxy/trie"
+)
+
+func TestMerklize(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		require.Equal(t, common.Hash{}, trie.Merklize(map[common.Hash][]byte{}))
+	})
+
+	t.Run("Single leaf", func(t *testing.T) {
+		key := common.Hash{0xff, 0x01}
+		value := []byte{1, 2, 3}
+
+		leaf := trie.EncodeLeaf(key, value)
+		require.Equal(t, byte(0b10000011), leaf[0])
+		require.Equal(t, key[:31], leaf[1:32])
+		require.Equal(t, value, leaf[32:35])
+
+		require.Equal(t, common.Hash(blake2b.Sum256(leaf[:])), trie.Merklize(map[common.Hash][]byte{key: value}))
+	})
+
+	t.Run("Regular leaf", func(t *testing.T) {
+		value := make([]byte, trie.MaxEmbeddedValueSize+1)
+		leaf := trie.EncodeLeaf(common.Hash{}, value)
+		valueHash := blake2b.Sum256(value)
+		require.Equal(t, byte(0b11000000), leaf[0])
+		require.Equal(t, valueHash[:], leaf[32:])
+	})
+
+	t.Run("Branch", func(t *testing.T) {
+		left, right := common.Hash{0x00}, common.Hash{0x80}
+		kvs := map[common.Hash][]byte{left: {1}, right: {2}}
+
+		leftLeaf := trie.EncodeLeaf(left, []byte{1})
+		rightLeaf := trie.EncodeLeaf(right, []byte{2})
+		branch := trie.EncodeBranch(blake2b.Sum256(leftLeaf[:]), blake2b.Sum256(rightLeaf[:]))
+		require.Zero(t, branch[0]&0x80)
+
+		require.Equal(t, common.Hash(blake2b.Sum256(branch[:])), trie.Merklize(kvs))
+	})
+}
+
+func TestMerklizeTestVectors(t *testing.T) {
+	t.Run("Trie", func(t *testing.T) {
+		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+		if err != nil {
+			require.NoError(t, err, "failed to get JSON file paths")
+		}
+
+		for _, filePath := range filePaths {
+			testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+			t.Run(testCase, func(t *testing.T) {
+				file, err := os.ReadFile(filePath)
+				if err != nil {
+					require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+				}
+
+				var testVectors []TestVector
+				err = json.Unmarshal(file, &testVectors)
+				if err != nil {
+					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
+				}
+
+				for i, testVector := range testVectors {
+					t.Run(fmt.Sprintf("Case %d", i), func(t *testing.T) {
+						kvs := make(map[common.Hash][]byte, len(testVector.Input))
+						for key, value := range testVector.Input {
+							kvs[common.BytesToHash(common.FromHex(key))] = common.FromHex(value)
+						}
+						require.Equal(t, testVector.Output, trie.Merklize(kvs), "merkle root does not match expected output")
+					})
+				}
+			})
+		}
+	})
+}
+
+type TestVector struct {
+	Input  map[string]string `json:"input"`
+	Output common.Hash       `json:"output"`
+}
@@ -15,6 +15,8 @@
 
 #define RING_VRF_SIGNATURE_SIZE 784
 
+#define IETF_VRF_SIGNATURE_SIZE 96
+
 #define OUTPUT_HASH_SIZE 32
 
 bool init_ring_size(size_t ring_size);
@@ -48,3 +50,20 @@ bool ring_vrf_verify(const unsigned char *vrf_input_data_ptr,
                      const unsigned char *ring_commitment_ptr,
                      const unsigned char *signature_ptr,
                      unsigned char *output_hash_out_ptr);
+
+bool ietf_vrf_sign(const unsigned char *secret_ptr,
+                   const unsigned char *vrf_input_data_ptr,
+                   size_t vrf_input_data_len,
+                   const unsigned char *aux_data_ptr,
+                   size_t aux_data_len,
+                   unsigned char *signature_out_ptr);
+
+bool ietf_vrf_verify(const unsigned char *public_ptr,
+                     const unsigned char *vrf_input_data_ptr,
+                     size_t vrf_input_data_len,
+                     const unsigned char *aux_data_ptr,
+                     size_t aux_data_len,
+                     const unsigned char *signature_ptr,
+                     unsigned char *output_hash_out_ptr);
+
+bool ietf_vrf_output(const unsigned char *signature_ptr, unsigned char *output_hash_out_ptr);
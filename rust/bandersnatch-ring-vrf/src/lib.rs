@@ -5,7 +5,7 @@ use libc::size_t;
 use ark_vrf::ring::{Prover, Verifier, RingCommitment};
 use ark_vrf::reexports::ark_serialize::{self, CanonicalDeserialize, CanonicalSerialize};
 use ark_vrf::suites::bandersnatch;
-use bandersnatch::{BandersnatchSha512Ell2, Input, Output, RingProofParams, RingProof, Public, Secret, PcsParams};
+use bandersnatch::{BandersnatchSha512Ell2, Input, Output, RingProofParams, RingProof, IetfProof, Public, Secret, PcsParams};
 
 macro_rules! srs_file_path {
     () => {
@@ -21,6 +21,7 @@ pub const RING_COMMITMENT_SIZE: usize = 144;
 pub const PUBKEY_SIZE: usize = 32;
 pub const SECRET_SIZE: usize = 32;
 pub const RING_VRF_SIGNATURE_SIZE: usize = 784;
+pub const IETF_VRF_SIGNATURE_SIZE: usize = 96;
 pub const OUTPUT_HASH_SIZE: usize = 32;
 
 static RING_SIZE: OnceLock<usize> = OnceLock::new();
@@ -61,6 +62,12 @@ struct RingVrfSignature {
     proof: RingProof,
 }
 
+#[derive(CanonicalSerialize, CanonicalDeserialize)]
+struct IetfVrfSignature {
+    output: Output,
+    proof: IetfProof,
+}
+
 #[no_mangle]
 pub unsafe extern "C" fn new_secret_from_seed(
     seed_ptr: *const c_uchar,
@@ -274,3 +281,127 @@ pub unsafe extern "C" fn ring_vrf_verify(
 
     true
 }
+
+#[no_mangle]
+pub unsafe extern "C" fn ietf_vrf_sign(
+    secret_ptr: *const c_uchar,
+    vrf_input_data_ptr: *const c_uchar,
+    vrf_input_data_len: size_t,
+    aux_data_ptr: *const c_uchar,
+    aux_data_len: size_t,
+    signature_out_ptr: *mut c_uchar,
+) -> bool {
+    if secret_ptr.is_null()
+        || vrf_input_data_ptr.is_null()
+        || aux_data_ptr.is_null()
+        || signature_out_ptr.is_null()
+    {
+        return false;
+    }
+
+    let secret: &[u8] = slice::from_raw_parts(secret_ptr, SECRET_SIZE);
+    let secret = match Secret::deserialize_compressed(secret) {
+        Ok(s) => s,
+        Err(_) => return false,
+    };
+    let vrf_input_data: &[u8] = slice::from_raw_parts(vrf_input_data_ptr, vrf_input_data_len as usize);
+    let aux_data: &[u8] = slice::from_raw_parts(aux_data_ptr, aux_data_len as usize);
+
+    let input = vrf_input_point(vrf_input_data);
+    let output = secret.output(input);
+    // Fully qualified, as the ring prover trait shares the method name.
+    let proof = ark_vrf::ietf::Prover::prove(&secret, input, output, aux_data);
+
+    let signature = IetfVrfSignature { output, proof };
+
+    let mut serialized = [0u8; IETF_VRF_SIGNATURE_SIZE];
+    match signature.serialize_compressed(&mut serialized[..]) {
+        Ok(bytes) => bytes,
+        Err(_) => return false,
+    };
+
+    std::ptr::copy_nonoverlapping(serialized.as_ptr(), signature_out_ptr, serialized.len());
+
+    true
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn ietf_vrf_verify(
+    public_ptr: *const c_uchar,
+    vrf_input_data_ptr: *const c_uchar,
+    vrf_input_data_len: size_t,
+    aux_data_ptr: *const c_uchar,
+    aux_data_len: size_t,
+    signature_ptr: *const c_uchar,
+    output_hash_out_ptr: *mut c_uchar,
+) -> bool {
+    if public_ptr.is_null()
+        || vrf_input_data_ptr.is_null()
+        || aux_data_ptr.is_null()
+        || signature_ptr.is_null()
+        || output_hash_out_ptr.is_null()
+    {
+        return false;
+    }
+
+    let (
+        public,
+        vrf_input_data,
+        aux_data,
+        signature,
+    ) = (
+        slice::from_raw_parts(public_ptr, PUBKEY_SIZE),
+        slice::from_raw_parts(vrf_input_data_ptr, vrf_input_data_len as usize),
+        slice::from_raw_parts(aux_data_ptr, aux_data_len as usize),
+        slice::from_raw_parts(signature_ptr, IETF_VRF_SIGNATURE_SIZE),
+    );
+
+    let public = match Public::deserialize_compressed(public) {
+        Ok(p) => p,
+        Err(_) => return false,
+    };
+
+    let signature = match IetfVrfSignature::deserialize_compressed(signature) {
+        Ok(s) => s,
+        Err(_) => return false,
+    };
+
+    let input = vrf_input_point(vrf_input_data);
+    let output = signature.output;
+
+    // Fully qualified, as the ring verifier trait shares the method name.
+    if ark_vrf::ietf::Verifier::verify(&public, input, output, aux_data, &signature.proof).is_err() {
+        return false;
+    }
+
+    let mut output_hash = [0u8; OUTPUT_HASH_SIZE];
+    output_hash.copy_from_slice(&output.hash()[..OUTPUT_HASH_SIZE]);
+
+    std::ptr::copy_nonoverlapping(output_hash.as_ptr(), output_hash_out_ptr, output_hash.len());
+
+    true
+}
+
+// Output hash Y(s) of an IETF VRF signature, which can be derived without verifying the proof.
+#[no_mangle]
+pub unsafe extern "C" fn ietf_vrf_output(
+    signature_ptr: *const c_uchar,
+    output_hash_out_ptr: *mut c_uchar,
+) -> bool {
+    if signature_ptr.is_null() || output_hash_out_ptr.is_null() {
+        return false;
+    }
+
+    let signature = slice::from_raw_parts(signature_ptr, IETF_VRF_SIGNATURE_SIZE);
+    let signature = match IetfVrfSignature::deserialize_compressed(signature) {
+        Ok(s) => s,
+        Err(_) => return false,
+    };
+
+    let mut output_hash = [0u8; OUTPUT_HASH_SIZE];
+    output_hash.copy_from_slice(&signature.output.hash()[..OUTPUT_HASH_SIZE]);
+
+    std::ptr::copy_nonoverlapping(output_hash.as_ptr(), output_hash_out_ptr, output_hash.len());
+
+    true
+}
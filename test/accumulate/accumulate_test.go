@@ -0,0 +1,254 @@
+package accumulate_test
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/accumulate"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The accumulation queue θ, history ξ, privileged services χ, service accounts δ and the accumulation result root are compared.
+func TestAccumulate(t *testing.T) {
+	t.Run(testSpec, func(t *testing.T) {
+		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+		if err != nil {
+			require.NoError(t, err, "failed to get JSON file paths")
+		}
+
+		for _, filePath := range filePaths {
+			testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+			t.Run(testCase, func(t *testing.T) {
+				file, err := os.ReadFile(filePath)
+				if err != nil {
+					require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+				}
+
+				var testVector TestVector
+				err = json.Unmarshal(file, &testVector)
+				if err != nil {
+					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
+				}
+
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
+				queue := toAccumulationQueue(testVector.PreState.ReadyQueue)
+				history := toAccumulationHistory(testVector.PreState.Accumulated)
+
+				reports := make([]*workreport.WorkReport, len(testVector.Input.Reports))
+				for i, report := range testVector.Input.Reports {
+					reports[i] = test_utils.ToWorkReport(report)
+				}
+
+				partialState := &accumulate.PartialState{
+					Services:           *toServices(testVector.PreState.Accounts),
+					PrivilegedServices: toPrivilegedServices(testVector.PreState.Privileges),
+				}
+
+				outcome, err := accumulate.Update(
+					queue,
+					history,
+					testVector.Input.Slot,
+					testVector.PreState.Slot,
+					reports,
+					partialState,
+					testVector.PreState.Entropy,
+				)
+				require.NoError(t, err, "failed to accumulate")
+
+				require.Equal(t, toAccumulationQueue(testVector.PostState.ReadyQueue), queue, "accumulation queue should match expected state")
+				require.Equal(t, toAccumulationHistory(testVector.PostState.Accumulated), history, "accumulation history should match expected state")
+				require.Equal(t, toPrivilegedServices(testVector.PostState.Privileges), outcome.State.PrivilegedServices, "privileged services should match expected state")
+				require.Equal(t, sortedAccounts(testVector.PostState.Accounts), fromServices(&outcome.State.Services, testVector.PreState.Accounts), "service accounts should match expected state")
+				require.Equal(t, testVector.Output.Ok, outcome.ResultRoot(), "accumulation result root should match expected output")
+			})
+		}
+	})
+}
+
+func toServices(input []Account) *service.Services {
+	services := &service.Services{}
+	for _, account := range input {
+		preimages := make(map[common.Hash]common.Blob, len(account.Data.Preimages))
+		for _, preimage := range account.Data.Preimages {
+			preimages[preimage.Hash] = preimage.Blob
+		}
+
+		services.Save(account.Id, &service.ServiceAccount{
+			Preimages:     preimages,
+			CodeHash:      account.Data.Service.CodeHash,
+			Balance:       account.Data.Service.Balance,
+			AccumulateGas: account.Data.Service.MinItemGas,
+			OnTransferGas: account.Data.Service.MinMemoGas,
+		})
+	}
+	return services
+}
+
+// fromServices converts the service accounts back into the accounts of the test vectors.
+// The vectors omit the storage and preimage lookups of the accounts, so the footprint is offset by the one of the prior accounts.
+func fromServices(services *service.Services, prior []Account) []Account {
+	offsets := make(map[service.ServiceId]test_utils.ServiceInfo, len(prior))
+	for _, account := range prior {
+		offsets[account.Id] = account.Data.Service
+	}
+
+	var accounts []Account
+	for serviceId, serviceAccount := range services.All() {
+		footprint := serviceAccount.Footprint()
+		account := Account{Id: serviceId}
+		account.Data.Service = test_utils.ServiceInfo{
+			CodeHash:   serviceAccount.CodeHash,
+			Balance:    serviceAccount.Balance,
+			MinItemGas: serviceAccount.AccumulateGas,
+			MinMemoGas: serviceAccount.OnTransferGas,
+			Bytes:      offsets[serviceId].Bytes + footprint.SizeOfStorageItems,
+			Items:      offsets[serviceId].Items + footprint.NumOfStorageItems,
+		}
+		for hash, blob := range serviceAccount.Preimages {
+			account.Data.Preimages = append(account.Data.Preimages, Preimage{Hash: hash, Blob: blob})
+		}
+		accounts = append(accounts, account)
+	}
+	return sortedAccounts(accounts)
+}
+
+// sortedAccounts orders the accounts by service and their preimages by hash, with no preimages as nil.
+func sortedAccounts(input []Account) []Account {
+	accounts := make([]Account, len(input))
+	for i, account := range input {
+		if len(account.Data.Preimages) == 0 {
+			account.Data.Preimages = nil
+		}
+		account.Data.Preimages = slices.Clone(account.Data.Preimages)
+		slices.SortFunc(account.Data.Preimages, func(a, b Preimage) int {
+			return bytes.Compare(a.Hash[:], b.Hash[:])
+		})
+		accounts[i] = account
+	}
+	slices.SortFunc(accounts, func(a, b Account) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return accounts
+}
+
+// The test vectors have a single assigner for all cores.
+func toPrivilegedServices(input Privileges) service.PrivilegedServices {
+	privileged := service.PrivilegedServices{
+		Manager:          input.Bless,
+		Designator:       input.Designate,
+		AlwaysAccumulate: make(map[service.ServiceId]service.Gas, len(input.AlwaysAcc)),
+	}
+	for coreIndex := range privileged.Assigners {
+		privileged.Assigners[coreIndex] = input.Assign
+	}
+	for _, item := range input.AlwaysAcc {
+		privileged.AlwaysAccumulate[item.Id] = item.Gas
+	}
+	return privileged
+}
+
+func toAccumulationQueue(input [jamtime.TimeSlotsPerEpoch][]ReadyRecord) *accumulate.AccumulationQueue {
+	queue := &accumulate.AccumulationQueue{}
+	for i, records := range input {
+		for _, record := range records {
+			dependencies := make(map[common.Hash]struct{}, len(record.Dependencies))
+			for _, dependency := range record.Dependencies {
+				dependencies[dependency] = struct{}{}
+			}
+			queue[i] = append(queue[i], &accumulate.QueuedWorkReport{
+				WorkReport:   test_utils.ToWorkReport(record.Report),
+				Dependencies: dependencies,
+			})
+		}
+	}
+	return queue
+}
+
+func toAccumulationHistory(input [jamtime.TimeSlotsPerEpoch][]common.Hash) *accumulate.AccumulationHistory {
+	history := &accumulate.AccumulationHistory{}
+	for i, workPackageHashes := range input {
+		history[i] = make(map[common.Hash]struct{}, len(workPackageHashes))
+		for _, workPackageHash := range workPackageHashes {
+			history[i][workPackageHash] = struct{}{}
+		}
+	}
+	return history
+}
+
+type TestVector struct {
+	Input     Input  `json:"input"`
+	PreState  State  `json:"pre_state"`
+	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
+}
+
+type Input struct {
+	Slot    jamtime.TimeSlot        `json:"slot"`
+	Reports []test_utils.WorkReport `json:"reports"`
+}
+
+type State struct {
+	Slot        jamtime.TimeSlot                         `json:"slot"`
+	Entropy     common.Hash                              `json:"entropy"`
+	ReadyQueue  [jamtime.TimeSlotsPerEpoch][]ReadyRecord `json:"ready_queue"`
+	Accumulated [jamtime.TimeSlotsPerEpoch][]common.Hash `json:"accumulated"`
+	Privileges  Privileges                               `json:"privileges"`
+	Accounts    []Account                                `json:"accounts"`
+}
+
+type Account struct {
+	Id   service.ServiceId `json:"id"`
+	Data struct {
+		Service   test_utils.ServiceInfo `json:"service"`
+		Preimages []Preimage             `json:"preimages"`
+	} `json:"data"`
+}
+
+type Preimage struct {
+	Hash common.Hash `json:"hash"`
+	Blob common.Blob `json:"blob"`
+}
+
+type ReadyRecord struct {
+	Report       test_utils.WorkReport `json:"report"`
+	Dependencies []common.Hash         `json:"dependencies"`
+}
+
+type Privileges struct {
+	Bless     service.ServiceId `json:"bless"`
+	Assign    service.ServiceId `json:"assign"`
+	Designate service.ServiceId `json:"designate"`
+	AlwaysAcc []struct {
+		Id  service.ServiceId `json:"id"`
+		Gas service.Gas       `json:"gas"`
+	} `json:"always_acc"`
+}
+
+type Output struct {
+	Ok  common.Hash `json:"ok"`
+	Err string      `json:"err"`
+}
+
+// The accumulation has no error codes.
+func (o *Output) UnmarshalJAM(d *codec.Decoder) (err error) {
+	o.Err, err = test_utils.DecodeOutput(d, &o.Ok, nil)
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build tiny
+
+package accumulate_test
+
+const (
+	testSpec         = "tiny"
+	vectorFolderPath = "../../@jamtestvectors-davxy/accumulate/tiny"
+)
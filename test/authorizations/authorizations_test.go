@@ -36,10 +36,16 @@ func TestAuthorizationsStateTransition(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
 				timeSlot := testVector.Input.Slot
-				authorizerHashes := make(map[uint8]common.Hash)
+				authorizerHashes := make(map[uint32]common.Hash)
 				for _, auth := range testVector.Input.Auths {
-					authorizerHashes[auth.Core] = auth.AuthHash
+					authorizerHashes[uint32(auth.Core)] = auth.AuthHash
 				}
 
 				authorizerPools := toAuthorizersPools(testVector.PreState.AuthPools)
@@ -57,7 +63,7 @@ func TestAuthorizationsStateTransition(t *testing.T) {
 	})
 }
 
-func toAuthorizersPools(pools []AuthPool) authpool.AuthorizerPools {
+func toAuthorizersPools(pools [common.NumOfCores]AuthPool) authpool.AuthorizerPools {
 	authPools := authpool.AuthorizerPools{}
 	for coreIndex, pool := range pools {
 		coreAuthPool := make(authpool.AuthorizerPool, len(pool))
@@ -67,11 +73,10 @@ func toAuthorizersPools(pools []AuthPool) authpool.AuthorizerPools {
 	return authPools
 }
 
-func toAuthorizersQueues(queues []AuthQueue) authqueue.AuthorizerQueues {
+func toAuthorizersQueues(queues [common.NumOfCores]AuthQueue) authqueue.AuthorizerQueues {
 	authQueues := authqueue.AuthorizerQueues{}
 	for coreIndex, queue := range queues {
-		coreAuthQueue := authqueue.AuthorizerQueue{}
-		copy(coreAuthQueue[:], queue)
+		coreAuthQueue := authqueue.AuthorizerQueue(queue)
 		authQueues[coreIndex] = &coreAuthQueue
 	}
 	return authQueues
@@ -80,8 +85,8 @@ func toAuthorizersQueues(queues []AuthQueue) authqueue.AuthorizerQueues {
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
@@ -90,17 +95,17 @@ type Input struct {
 }
 
 type Auth struct {
-	Core     uint8       `json:"core"`
+	Core     uint16      `json:"core"`
 	AuthHash common.Hash `json:"auth_hash"`
 }
 
 type State struct {
-	AuthPools  []AuthPool  `json:"auth_pools"`
-	AuthQueues []AuthQueue `json:"auth_queues"`
+	AuthPools  [common.NumOfCores]AuthPool  `json:"auth_pools"`
+	AuthQueues [common.NumOfCores]AuthQueue `json:"auth_queues"`
 }
 
 type AuthPool []common.Hash
 
-type AuthQueue []common.Hash
+type AuthQueue [authqueue.AuthorizerQueueSize]common.Hash
 
 type Output struct{}
@@ -0,0 +1,180 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/work"
+	workitem "github.com/shunsukew/gojam/internal/work/item"
+	workpackage "github.com/shunsukew/gojam/internal/work/package"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vector files are named after the type they contain, optionally suffixed with an index. e.g. header_0.json
+var vectorIndexSuffix = regexp.MustCompile(`_\d+$`)
+
+var vectorTypes = map[string]func() interface{}{
+	"assurances_extrinsic": func() interface{} { return &test_utils.AssurancesExtrinsic{} },
+	"block":                func() interface{} { return &Block{} },
+	"disputes_extrinsic":   func() interface{} { return &test_utils.DisputesExtrinsic{} },
+	"extrinsic":            func() interface{} { return &test_utils.Extrinsic{} },
+	"guarantees_extrinsic": func() interface{} { return &test_utils.GuaranteesExtrinsic{} },
+	"header":               func() interface{} { return &Header{} },
+	"preimages_extrinsic":  func() interface{} { return &test_utils.PreimagesExtrinsic{} },
+	"refine_context":       func() interface{} { return &test_utils.RefineContext{} },
+	"tickets_extrinsic":    func() interface{} { return &test_utils.TicketsExtrinsic{} },
+	"work_item":            func() interface{} { return &WorkItem{} },
+	"work_package":         func() interface{} { return &WorkPackage{} },
+	"work_report":          func() interface{} { return &test_utils.WorkReport{} },
+	"work_result":          func() interface{} { return &test_utils.WorkResult{} },
+}
+
+// The types of the node the test vectors decode into, of which the encoding must reproduce the binary test vectors.
+var internalTypes = map[string]func() interface{}{
+	"assurances_extrinsic": func() interface{} { return &block.AssuarancesExtrinsic{} },
+	"block":                func() interface{} { return &block.Block{} },
+	"disputes_extrinsic":   func() interface{} { return &block.DisputesExtrinsic{} },
+	"extrinsic":            func() interface{} { return &block.Extrinsic{} },
+	"guarantees_extrinsic": func() interface{} { return &block.GuaranteesExtrinsic{} },
+	"header":               func() interface{} { return &block.Header{} },
+	"preimages_extrinsic":  func() interface{} { return &block.PreimagesExtrinsic{} },
+	"refine_context":       func() interface{} { return &work.RefinementContext{} },
+	"tickets_extrinsic":    func() interface{} { return &block.TicketsExtrinsic{} },
+	"work_item":            func() interface{} { return &workitem.Item{} },
+	"work_package":         func() interface{} { return &workpackage.Package{} },
+	"work_report":          func() interface{} { return &workreport.WorkReport{} },
+	"work_result":          func() interface{} { return &workreport.WorkResult{} },
+}
+
+func TestCodec(t *testing.T) {
+	t.Run(testSpec, func(t *testing.T) {
+		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+		if err != nil {
+			require.NoError(t, err, "failed to get JSON file paths")
+		}
+
+		for _, filePath := range filePaths {
+			testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+			t.Run(testCase, func(t *testing.T) {
+				name := vectorIndexSuffix.ReplaceAllString(strings.TrimSuffix(filepath.Base(filePath), ".json"), "")
+				newValue, ok := vectorTypes[name]
+				if !ok {
+					t.Skipf("unknown test vector type: %s", name)
+				}
+
+				file, err := os.ReadFile(filePath)
+				if err != nil {
+					require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+				}
+
+				fromJson := newValue()
+				err = json.Unmarshal(file, fromJson)
+				require.NoErrorf(t, err, "failed to unmarshal test vector: %s", filePath)
+
+				binFilePath := test_utils.BinFilePath(filePath)
+				binFile, err := os.ReadFile(binFilePath)
+				require.NoErrorf(t, err, "failed to read test vector file: %s", binFilePath)
+
+				fromBin := newValue()
+				err = codec.Decode(binFile, fromBin)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+
+				require.Equal(t, fromJson, fromBin, "expected JSON and binary test vectors to match")
+
+				encoded, err := codec.Encode(fromJson)
+				require.NoError(t, err, "failed to encode test vector")
+				require.Equal(t, binFile, encoded, "expected encoding to match binary test vector")
+
+				internal := internalTypes[name]()
+				err = codec.Decode(binFile, internal)
+				require.NoErrorf(t, err, "failed to decode test vector into %T: %s", internal, binFilePath)
+
+				encoded, err = codec.Encode(internal)
+				require.NoErrorf(t, err, "failed to encode %T", internal)
+				require.Equal(t, binFile, encoded, "expected encoding of %T to match binary test vector", internal)
+			})
+		}
+	})
+}
+
+type ImportSpec struct {
+	TreeRoot common.Hash `json:"tree_root"`
+	Index    uint16      `json:"index"`
+}
+
+type ExtrinsicSpec struct {
+	Hash common.Hash `json:"hash"`
+	Len  uint32      `json:"len"`
+}
+
+type WorkItem struct {
+	Service            uint32          `json:"service"`
+	CodeHash           common.Hash     `json:"code_hash"`
+	Payload            common.Blob     `json:"payload"`
+	RefineGasLimit     uint64          `json:"refine_gas_limit"`
+	AccumulateGasLimit uint64          `json:"accumulate_gas_limit"`
+	ImportSegments     []ImportSpec    `json:"import_segments"`
+	Extrinsic          []ExtrinsicSpec `json:"extrinsic"`
+	ExportCount        uint16          `json:"export_count"`
+}
+
+type Authorizer struct {
+	CodeHash common.Hash `json:"code_hash"`
+	Params   common.Blob `json:"params"`
+}
+
+type WorkPackage struct {
+	Authorization common.Blob              `json:"authorization"`
+	AuthCodeHost  uint32                   `json:"auth_code_host"`
+	Authorizer    Authorizer               `json:"authorizer"`
+	Context       test_utils.RefineContext `json:"context"`
+	Items         []WorkItem               `json:"items"`
+}
+
+type EpochMarkValidatorKeys struct {
+	Bandersnatch bandersnatch.PublicKey   `json:"bandersnatch"`
+	Ed25519      test_utils.Ed25519Public `json:"ed25519"`
+}
+
+type EpochMark struct {
+	Entropy        common.Hash                                    `json:"entropy"`
+	TicketsEntropy common.Hash                                    `json:"tickets_entropy"`
+	Validators     [common.NumOfValidators]EpochMarkValidatorKeys `json:"validators"`
+}
+
+type TicketBody struct {
+	Id      common.Hash `json:"id"`
+	Attempt uint8       `json:"attempt"`
+}
+
+type TicketsMark [jamtime.TimeSlotsPerEpoch]TicketBody
+
+type Header struct {
+	Parent          common.Hash                `json:"parent"`
+	ParentStateRoot common.Hash                `json:"parent_state_root"`
+	ExtrinsicHash   common.Hash                `json:"extrinsic_hash"`
+	Slot            uint32                     `json:"slot"`
+	EpochMark       *EpochMark                 `json:"epoch_mark" codec:"optional"`
+	TicketsMark     *TicketsMark               `json:"tickets_mark" codec:"optional"`
+	OffendersMark   []test_utils.Ed25519Public `json:"offenders_mark"`
+	AuthorIndex     uint16                     `json:"author_index"`
+	EntropySource   bandersnatch.IetfSignature `json:"entropy_source"`
+	Seal            bandersnatch.IetfSignature `json:"seal"`
+}
+
+type Block struct {
+	Header    Header               `json:"header"`
+	Extrinsic test_utils.Extrinsic `json:"extrinsic"`
+}
@@ -0,0 +1,8 @@
+//go:build tiny
+
+package codec_test
+
+const (
+	testSpec         = "Tiny"
+	vectorFolderPath = "../../@jamtestvectors-davxy/codec/tiny"
+)
@@ -10,10 +10,8 @@ import (
 
 	"github.com/shunsukew/gojam/internal/dispute"
 	"github.com/shunsukew/gojam/internal/jamtime"
-	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
-	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
-	"github.com/shunsukew/gojam/pkg/crypto/bls"
 	test_utils "github.com/shunsukew/gojam/test/utils"
 
 	"github.com/stretchr/testify/require"
@@ -40,14 +38,20 @@ func TestDisputeStateTransition(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
 				verticts := make([]*dispute.Verdict, len(testVector.Input.Disputes.Verdicts))
 				for i, v := range testVector.Input.Disputes.Verdicts {
 					judgements := &dispute.Judgements{}
 					for j, vote := range v.Votes {
 						(*judgements)[j] = &dispute.Judgement{
 							Vote:           vote.Vote,
-							ValidatorIndex: vote.Index,
-							Signature:      common.FromHex(vote.Signature),
+							ValidatorIndex: uint32(vote.Index),
+							Signature:      vote.Signature[:],
 						}
 					}
 					verticts[i] = &dispute.Verdict{
@@ -61,8 +65,8 @@ func TestDisputeStateTransition(t *testing.T) {
 				for i, c := range testVector.Input.Disputes.Culprits {
 					culprits[i] = &dispute.Culprit{
 						WorkReportHash: c.Target,
-						CulpritKey:     ed25519.PublicKey(common.FromHex(c.Key)),
-						Signature:      common.FromHex(c.Signature),
+						CulpritKey:     ed25519.PublicKey(c.Key[:]),
+						Signature:      c.Signature[:],
 					}
 				}
 
@@ -71,30 +75,14 @@ func TestDisputeStateTransition(t *testing.T) {
 					faults[i] = &dispute.Fault{
 						WorkReportHash: f.Target,
 						Vote:           f.Vote,
-						FaultKey:       ed25519.PublicKey(common.FromHex(f.Key)),
-						Signature:      common.FromHex(f.Signature),
+						FaultKey:       ed25519.PublicKey(f.Key[:]),
+						Signature:      f.Signature[:],
 					}
 				}
 
 				timeSlot := jamtime.TimeSlot(testVector.PreState.Tau)
-				activeValidators := make([]*keys.ValidatorKey, len(testVector.PreState.Kappa))
-				for i, v := range testVector.PreState.Kappa {
-					activeValidators[i] = &keys.ValidatorKey{
-						BandersnatchPublicKey: v.Bandersnatch,
-						Ed25519PublicKey:      ed25519.PublicKey(common.FromHex(v.Ed25519)),
-						BLSKey:                v.Bls,
-						Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(v.Metadata)),
-					}
-				}
-				archivedValidators := make([]*keys.ValidatorKey, len(testVector.PreState.Lambda))
-				for i, v := range testVector.PreState.Lambda {
-					archivedValidators[i] = &keys.ValidatorKey{
-						BandersnatchPublicKey: v.Bandersnatch,
-						Ed25519PublicKey:      ed25519.PublicKey(common.FromHex(v.Ed25519)),
-						BLSKey:                v.Bls,
-						Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(v.Metadata)),
-					}
-				}
+				activeValidators := test_utils.ToValidatorKeys(testVector.PreState.Kappa)[:]
+				archivedValidators := test_utils.ToValidatorKeys(testVector.PreState.Lambda)[:]
 
 				disputeState := toDisputeState(testVector.PreState)
 				expectedDisputeState := toDisputeState(testVector.PostState)
@@ -121,7 +109,7 @@ func TestDisputeStateTransition(t *testing.T) {
 
 				expectedOffendersMark := make([]ed25519.PublicKey, len(testVector.Output.Ok.OffendersMark))
 				for i, offender := range testVector.Output.Ok.OffendersMark {
-					expectedOffendersMark[i] = ed25519.PublicKey(common.FromHex(offender))
+					expectedOffendersMark[i] = ed25519.PublicKey(offender[:])
 				}
 				require.Equal(t, expectedOffendersMark, newOffenders, "Offenders mark mismatch")
 			})
@@ -137,76 +125,53 @@ func toDisputeState(state State) *dispute.DisputeState {
 		Offenders:     make([]ed25519.PublicKey, len(state.Psi.Offenders)),
 	}
 	for i, offender := range state.Psi.Offenders {
-		offenderKey := ed25519.PublicKey(common.FromHex(offender))
-		disputeState.Offenders[i] = offenderKey
+		disputeState.Offenders[i] = ed25519.PublicKey(offender[:])
 	}
 	return disputeState
 }
 
+// Error codes of the test vectors in the order of their discriminators.
+var errorCodes = []string{
+	"already_judged",
+	"bad_vote_split",
+	"verdicts_not_sorted_unique",
+	"judgements_not_sorted_unique",
+	"culprits_not_sorted_unique",
+	"faults_not_sorted_unique",
+	"not_enough_culprits",
+	"not_enough_faults",
+	"culprits_verdict_not_bad",
+	"fault_verdict_wrong",
+	"offender_already_reported",
+	"bad_judgement_age",
+	"bad_validator_index",
+	"bad_signature",
+}
+
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
-	Disputes Disputes `json:"disputes"`
-}
-
-type Disputes struct {
-	Verdicts []Verdict `json:"verdicts"`
-	Culprits []Culprit `json:"culprits"`
-	Faults   []Fault   `json:"faults"`
-}
-
-type Verdict struct {
-	Target common.Hash      `json:"target"`
-	Age    jamtime.TimeSlot `json:"age"`
-	Votes  []Vote           `json:"votes"`
-}
-
-type Vote struct {
-	Vote      bool   `json:"vote"`
-	Index     uint32 `json:"index"`
-	Signature string `json:"signature"`
-}
-
-type Culprit struct {
-	Target    common.Hash `json:"target"`
-	Key       string      `json:"key"`
-	Signature string      `json:"signature"`
-}
-
-type Fault struct {
-	Target    common.Hash `json:"target"`
-	Vote      bool        `json:"vote"`
-	Key       string      `json:"key"`
-	Signature string      `json:"signature"`
+	Disputes test_utils.DisputesExtrinsic `json:"disputes"`
 }
 
 type State struct {
-	Psi    Psi               `json:"psi"`
-	Rho    []*CoreAssignment `json:"rho"`
-	Tau    jamtime.TimeSlot  `json:"tau"`
-	Kappa  []ValidatorKey    `json:"kappa"`
-	Lambda []ValidatorKey    `json:"lambda"`
+	Psi    Psi                                `json:"psi"`
+	Rho    test_utils.AvailabilityAssignments `json:"rho"`
+	Tau    jamtime.TimeSlot                   `json:"tau"`
+	Kappa  test_utils.ValidatorsData          `json:"kappa"`
+	Lambda test_utils.ValidatorsData          `json:"lambda"`
 }
 
 type Psi struct {
-	Good      []common.Hash `json:"good"`
-	Bad       []common.Hash `json:"bad"`
-	Wonkey    []common.Hash `json:"wonky"`
-	Offenders []string      `json:"offenders"`
-}
-
-type CoreAssignment struct{}
-
-type ValidatorKey struct {
-	Bandersnatch bandersnatch.PublicKey `json:"bandersnatch"`
-	Ed25519      string                 `json:"ed25519"`
-	Bls          bls.BLSKey             `json:"bls"`
-	Metadata     string                 `json:"metadata"`
+	Good      []common.Hash              `json:"good"`
+	Bad       []common.Hash              `json:"bad"`
+	Wonkey    []common.Hash              `json:"wonky"`
+	Offenders []test_utils.Ed25519Public `json:"offenders"`
 }
 
 type Output struct {
@@ -214,6 +179,11 @@ type Output struct {
 	Err string `json:"err"`
 }
 
+func (o *Output) UnmarshalJAM(d *codec.Decoder) (err error) {
+	o.Err, err = test_utils.DecodeOutput(d, &o.Ok, errorCodes)
+	return err
+}
+
 type Ok struct {
-	OffendersMark []string `json:"offenders_mark"`
+	OffendersMark []test_utils.Ed25519Public `json:"offenders_mark"`
 }
@@ -0,0 +1,8 @@
+//go:build tiny
+
+package erasurecoding_test
+
+const (
+	testSpec         = "Tiny"
+	vectorFolderPath = "../../@jamtestvectors-davxy/erasure_coding/tiny"
+)
@@ -0,0 +1,62 @@
+package erasurecoding_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/erasure"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErasureCoding(t *testing.T) {
+	t.Run(testSpec, func(t *testing.T) {
+		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+		if err != nil {
+			require.NoError(t, err, "failed to get JSON file paths")
+		}
+
+		for _, filePath := range filePaths {
+			testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+			t.Run(testCase, func(t *testing.T) {
+				file, err := os.ReadFile(filePath)
+				if err != nil {
+					require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+				}
+
+				var testVector TestVector
+				err = json.Unmarshal(file, &testVector)
+				if err != nil {
+					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
+				}
+
+				data := common.FromHex(testVector.Data)
+				expectedShards := make([][]byte, len(testVector.Shards))
+				for i, shard := range testVector.Shards {
+					expectedShards[i] = common.FromHex(shard)
+				}
+
+				shards := erasure.Encode(data)
+				require.Equal(t, expectedShards, shards, "shards mismatch")
+
+				// Reconstruct from the recovery shards only.
+				subset := make(map[int][]byte, erasure.NumOfDataChunks)
+				for i := erasure.NumOfChunks - erasure.NumOfDataChunks; i < erasure.NumOfChunks; i++ {
+					subset[i] = expectedShards[i]
+				}
+				reconstructed, err := erasure.Reconstruct(subset)
+				require.NoError(t, err, "failed to reconstruct data")
+				require.Equal(t, data, reconstructed[:len(data)], "reconstructed data mismatch")
+			})
+		}
+	})
+}
+
+type TestVector struct {
+	Data   string   `json:"data"`
+	Shards []string `json:"shards"`
+}
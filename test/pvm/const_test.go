@@ -0,0 +1,4 @@
+package pvm_test
+
+// The PVM test vectors are not specific to the chain spec.
+const vectorFolderPath = "../../@jamtestvectors/pvm/programs"
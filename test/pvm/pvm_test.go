@@ -0,0 +1,102 @@
+package pvm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shunsukew/gojam/pkg/pvm"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPVM(t *testing.T) {
+	filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+	if err != nil {
+		require.NoError(t, err, "failed to get JSON file paths")
+	}
+
+	for _, filePath := range filePaths {
+		testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+		t.Run(testCase, func(t *testing.T) {
+			file, err := os.ReadFile(filePath)
+			if err != nil {
+				require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+			}
+
+			var testVector TestVector
+			err = json.Unmarshal(file, &testVector)
+			if err != nil {
+				require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
+			}
+
+			program, err := pvm.ParseProgram(toBytes(testVector.Program))
+			require.NoError(t, err, "failed to parse program")
+
+			memory := pvm.NewMemory()
+			for _, page := range testVector.InitialPageMap {
+				access := pvm.ReadOnly
+				if page.IsWritable {
+					access = pvm.Writable
+				}
+				memory.SetAccess(page.Address, page.Length, access)
+			}
+			for _, chunk := range testVector.InitialMemory {
+				memory.Set(chunk.Address, toBytes(chunk.Contents))
+			}
+
+			machine := pvm.NewMachine(program, testVector.InitialPC, testVector.InitialGas, testVector.InitialRegs, memory)
+			reason, value := machine.Run()
+
+			require.Equal(t, testVector.ExpectedStatus, reason.String(), "exit status mismatch")
+			if reason == pvm.PageFault {
+				require.Equal(t, testVector.ExpectedPageFaultAddress, value, "page fault address mismatch")
+			}
+			require.Equal(t, testVector.ExpectedRegs, machine.Registers, "registers mismatch")
+			require.Equal(t, testVector.ExpectedPC, machine.PC, "pc mismatch")
+			require.Equal(t, testVector.ExpectedGas, machine.Gas, "gas mismatch")
+			for _, chunk := range testVector.ExpectedMemory {
+				require.Equal(t, toBytes(chunk.Contents), memory.Get(chunk.Address, uint32(len(chunk.Contents))), "memory mismatch at %#x", chunk.Address)
+			}
+		})
+	}
+}
+
+// Octet sequences are given as arrays of numbers rather than base64 strings.
+func toBytes(octets []uint16) []byte {
+	b := make([]byte, len(octets))
+	for i, octet := range octets {
+		b[i] = byte(octet)
+	}
+	return b
+}
+
+type TestVector struct {
+	Name                     string        `json:"name"`
+	InitialRegs              pvm.Registers `json:"initial-regs"`
+	InitialPC                uint32        `json:"initial-pc"`
+	InitialPageMap           []Page        `json:"initial-page-map"`
+	InitialMemory            []MemoryChunk `json:"initial-memory"`
+	InitialGas               pvm.Gas       `json:"initial-gas"`
+	Program                  []uint16      `json:"program"`
+	ExpectedStatus           string        `json:"expected-status"`
+	ExpectedRegs             pvm.Registers `json:"expected-regs"`
+	ExpectedPC               uint32        `json:"expected-pc"`
+	ExpectedMemory           []MemoryChunk `json:"expected-memory"`
+	ExpectedGas              pvm.Gas       `json:"expected-gas"`
+	ExpectedPageFaultAddress uint64        `json:"expected-page-fault-address"`
+}
+
+type Page struct {
+	Address    uint32 `json:"address"`
+	Length     uint32 `json:"length"`
+	IsWritable bool   `json:"is-writable"`
+}
+
+type MemoryChunk struct {
+	Address  uint32   `json:"address"`
+	Contents []uint16 `json:"contents"`
+}
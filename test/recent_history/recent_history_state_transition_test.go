@@ -40,6 +40,12 @@ func TestRecentHistoryStateTransition(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
 				recentHistory := toRecentHistory(testVector.PreState)
 				expectedRecentHistory := toRecentHistory(testVector.PostState)
 
@@ -77,7 +83,7 @@ func toRecentHistory(state State) *history.RecentHistory {
 		recentBlock := &history.RecentBlock{
 			HeaderHash:            block.HeaderHash,
 			StateRoot:             block.StateRoot,
-			AccumulationResultMMR: *(*mmr.MMR)(&block.MMR.Peaks),
+			AccumulationResultMMR: block.MMR.Peaks,
 			WorkPackageHashes:     make(map[common.Hash]common.Hash),
 		}
 		for _, reported := range block.Reported {
@@ -92,8 +98,8 @@ func toRecentHistory(state State) *history.RecentHistory {
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
@@ -120,7 +126,7 @@ type RecentBlock struct {
 }
 
 type MMR struct {
-	Peaks []*common.Hash `json:"peaks"`
+	Peaks mmr.MMR `json:"peaks"`
 }
 
 type Reported struct {
@@ -1,24 +1,21 @@
 package reports_test
 
 import (
-	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/shunsukew/gojam/internal/accumulate"
 	authpool "github.com/shunsukew/gojam/internal/authorizer/pool"
 	"github.com/shunsukew/gojam/internal/entropy"
 	"github.com/shunsukew/gojam/internal/history"
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/service"
-	"github.com/shunsukew/gojam/internal/validator/keys"
-	"github.com/shunsukew/gojam/internal/work"
 	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
-	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
-	"github.com/shunsukew/gojam/pkg/crypto/bls"
 	"github.com/shunsukew/gojam/pkg/mmr"
 	test_utils "github.com/shunsukew/gojam/test/utils"
 
@@ -53,23 +50,33 @@ func TestWorkReportGuarantee(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
-				pendingWorkReportsState := toPendingWorkReports(testVector.PreState.AvailAssignments)
-				expectedPendingWorkReportsState := toPendingWorkReports(testVector.PostState.AvailAssignments)
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
+				pendingWorkReportsState := test_utils.ToPendingWorkReports(testVector.PreState.AvailAssignments)
+				expectedPendingWorkReportsState := test_utils.ToPendingWorkReports(testVector.PostState.AvailAssignments)
 				expectedOutput := testVector.Output
 
 				_, err = pendingWorkReportsState.GuaranteeNewWorkReports(
-					toGuarantees(testVector.Input.Guarantees),
+					test_utils.ToGuarantees(testVector.Input.Guarantees),
 					testVector.Input.Slot,
 					toEntropyPool(testVector.PreState.Entropy),
-					toValidatorKeys(testVector.PreState.CurrentValidators),
-					toValidatorKeys(testVector.PreState.PrevValidators),
+					test_utils.ToValidatorKeys(testVector.PreState.CurrentValidators),
+					test_utils.ToValidatorKeys(testVector.PreState.PrevValidators),
 					toAuthorizerPools(testVector.PreState.AuthPools),
 					toServices(testVector.PreState.Accounts),
 					toRecentHistory(testVector.PreState.RecentBlocks),
-					nil,
+					&accumulate.AccumulationQueue{},
+					&accumulate.AccumulationHistory{},
 				)
 				if expectedOutput.Err != "" {
 					require.Error(t, err, "error expected: %v", expectedOutput.Err)
+					if expectedOutput.Err == "bad_signature" {
+						require.ErrorIs(t, err, workreport.ErrBadSignature)
+					}
 					return
 				}
 
@@ -80,91 +87,12 @@ func TestWorkReportGuarantee(t *testing.T) {
 	})
 }
 
-func toGuarantees(input []Guarantee) workreport.Guarantees {
-	guarantees := make(workreport.Guarantees, len(input))
-	for i, g := range input {
-		guarantees[i] = &workreport.Guarantee{
-			Timeslot: g.Slot,
-			Credentials: func() []*workreport.Credential {
-				credentials := make([]*workreport.Credential, len(g.Signatures))
-				for j, sig := range g.Signatures {
-					credentials[j] = &workreport.Credential{
-						ValidatorIndex: sig.ValidatorIndex,
-						Signature:      common.FromHex(sig.Signature),
-					}
-				}
-				return credentials
-			}(),
-			WorkReport: &workreport.WorkReport{
-				AvailabilitySpecification: &workreport.AvailabilitySpecification{
-					WorkPackageHash:  g.Report.PackageSpec.Hash,
-					WorkBundleLength: g.Report.PackageSpec.Length,
-					ErasureRoot:      g.Report.PackageSpec.ErasureRoot,
-					SegmentRoot:      g.Report.PackageSpec.ExportsRoot,
-					SegmentCount:     g.Report.PackageSpec.ExportsCount,
-				},
-				RefinementContext: &work.RefinementContext{
-					AnchorHeaderHash:              g.Report.Context.Anchor,
-					AnchorStateRoot:               g.Report.Context.StateRoot,
-					AnchorBeefyRoot:               g.Report.Context.BeefyRoot,
-					LookupAnchorHeaderHash:        g.Report.Context.LookupAnchor,
-					LookupAnchorTimeSlot:          g.Report.Context.LookupAnchorSlot,
-					PreRequisiteWorkPackageHashes: g.Report.Context.PreRequisites,
-				},
-				CoreIndex:      g.Report.CoreIndex,
-				AuthorizerHash: g.Report.AuthorizerHash,
-				Output:         common.FromHex(g.Report.AuthOutput),
-				SegmentRootLookup: func() map[common.Hash]common.Hash {
-					lookup := make(map[common.Hash]common.Hash, len(g.Report.SegmentRootLookup))
-					for _, item := range g.Report.SegmentRootLookup {
-						lookup[item.WorkPackageHash] = item.SegmentTreeRoot
-					}
-					return lookup
-				}(),
-				WorkResults: func() []*workreport.WorkResult {
-					results := make([]*workreport.WorkResult, len(g.Report.Results))
-					for j, result := range g.Report.Results {
-						results[j] = &workreport.WorkResult{
-							ServiceId:       result.ServiceId,
-							ServiceCodeHash: result.CodeHash,
-							PayloadHash:     result.PayloadHash,
-							Gas:             result.AccumulateGas,
-							ExecResult: &workreport.ExecResult{
-								Output: common.FromHex(result.Result.Ok),
-								// TODO: Check exec error, in jam test vectors, no vector has been prepared yet.
-							},
-						}
-					}
-					return results
-				}(),
-			},
-		}
-	}
-	return guarantees
-}
-
-func toValidatorKeys(input []ValidatorKey) *[common.NumOfValidators]*keys.ValidatorKey {
-	validatorKeys := &[common.NumOfValidators]*keys.ValidatorKey{}
-	for i, v := range input {
-		validatorKeys[i] = &keys.ValidatorKey{
-			BandersnatchPublicKey: v.Bandersnatch,
-			Ed25519PublicKey:      ed25519.PublicKey(common.FromHex(v.Ed25519)),
-			BLSKey:                v.Bls,
-			Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(v.Metadata)),
-		}
-	}
-	return validatorKeys
-}
-
-func toEntropyPool(input []common.Hash) *entropy.EntropyPool {
-	entropyPool := entropy.EntropyPool{}
-	for i := range len(entropyPool) {
-		entropyPool[i] = input[i]
-	}
+func toEntropyPool(input [4]common.Hash) *entropy.EntropyPool {
+	entropyPool := entropy.EntropyPool(input)
 	return &entropyPool
 }
 
-func toAuthorizerPools(input []AuthPool) *authpool.AuthorizerPools {
+func toAuthorizerPools(input [common.NumOfCores]AuthPool) *authpool.AuthorizerPools {
 	authorizerPools := authpool.AuthorizerPools{}
 	for i, pool := range input {
 		authorizerPools[i] = make([]common.Hash, len(pool))
@@ -173,65 +101,6 @@ func toAuthorizerPools(input []AuthPool) *authpool.AuthorizerPools {
 	return &authorizerPools
 }
 
-func toPendingWorkReports(availAssignments []*AvailAssignment) *workreport.PendingWorkReports {
-	pendingWorkReports := &workreport.PendingWorkReports{}
-	for i, assignment := range availAssignments {
-		if assignment == nil {
-			continue
-		}
-
-		pendingWorkReport := &workreport.PendingWorkReport{
-			ReportedAt: assignment.Timeout, // TODO: Test vector should rename field from `timeout` to `reported_at`. Otherwise, really confusing. Actual timeout of reports in test vectors are `timeout` val + PendingWorkReportTimeout 5 slots.
-			WorkReport: &workreport.WorkReport{
-				AvailabilitySpecification: &workreport.AvailabilitySpecification{
-					WorkPackageHash:  assignment.Report.PackageSpec.Hash,
-					WorkBundleLength: assignment.Report.PackageSpec.Length,
-					ErasureRoot:      assignment.Report.PackageSpec.ErasureRoot,
-					SegmentRoot:      assignment.Report.PackageSpec.ExportsRoot,
-					SegmentCount:     assignment.Report.PackageSpec.ExportsCount,
-				},
-				RefinementContext: &work.RefinementContext{
-					AnchorHeaderHash:              assignment.Report.Context.Anchor,
-					AnchorStateRoot:               assignment.Report.Context.StateRoot,
-					AnchorBeefyRoot:               assignment.Report.Context.BeefyRoot,
-					LookupAnchorHeaderHash:        assignment.Report.Context.LookupAnchor,
-					LookupAnchorTimeSlot:          assignment.Report.Context.LookupAnchorSlot,
-					PreRequisiteWorkPackageHashes: assignment.Report.Context.PreRequisites,
-				},
-				CoreIndex:      assignment.Report.CoreIndex,
-				AuthorizerHash: assignment.Report.AuthorizerHash,
-				Output:         common.FromHex(assignment.Report.AuthOutput),
-				SegmentRootLookup: func() map[common.Hash]common.Hash {
-					lookup := make(map[common.Hash]common.Hash, len(assignment.Report.SegmentRootLookup))
-					for _, item := range assignment.Report.SegmentRootLookup {
-						lookup[item.WorkPackageHash] = item.SegmentTreeRoot
-					}
-					return lookup
-				}(),
-				WorkResults: func() []*workreport.WorkResult {
-					results := make([]*workreport.WorkResult, len(assignment.Report.Results))
-					for j, result := range assignment.Report.Results {
-						results[j] = &workreport.WorkResult{
-							ServiceId:       result.ServiceId,
-							ServiceCodeHash: result.CodeHash,
-							PayloadHash:     result.PayloadHash,
-							Gas:             result.AccumulateGas,
-							ExecResult: &workreport.ExecResult{
-								Output: common.FromHex(result.Result.Ok),
-							},
-						}
-					}
-					return results
-				}(),
-			},
-		}
-
-		pendingWorkReports[i] = pendingWorkReport
-	}
-
-	return pendingWorkReports
-}
-
 func toServices(input []Account) *service.Services {
 	services := &service.Services{}
 	for _, account := range input {
@@ -251,7 +120,7 @@ func toRecentHistory(input []RecentBlock) *history.RecentHistory {
 		recentBlock := &history.RecentBlock{
 			HeaderHash:            block.HeaderHash,
 			StateRoot:             block.StateRoot,
-			AccumulationResultMMR: *(*mmr.MMR)(&block.MMR.Peaks),
+			AccumulationResultMMR: block.MMR.Peaks,
 			WorkPackageHashes:     make(map[common.Hash]common.Hash),
 		}
 		for _, reported := range block.Reported {
@@ -262,108 +131,57 @@ func toRecentHistory(input []RecentBlock) *history.RecentHistory {
 	return recentHistory
 }
 
+// Error codes of the test vectors in the order of their discriminators.
+var errorCodes = []string{
+	"bad_core_index",
+	"future_report_slot",
+	"report_epoch_before_last",
+	"insufficient_guarantees",
+	"out_of_order_guarantee",
+	"not_sorted_or_unique_guarantors",
+	"wrong_assignment",
+	"core_engaged",
+	"anchor_not_recent",
+	"bad_service_id",
+	"bad_code_hash",
+	"dependency_missing",
+	"duplicate_package",
+	"bad_state_root",
+	"bad_beefy_mmr_root",
+	"core_unauthorized",
+	"bad_validator_index",
+	"work_report_gas_too_high",
+	"service_item_gas_too_low",
+	"too_many_dependencies",
+	"segment_root_lookup_invalid",
+	"bad_signature",
+	"work_report_too_big",
+}
+
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
-	Guarantees    []Guarantee      `json:"guarantees"`
-	Slot          jamtime.TimeSlot `json:"slot"`
-	KnownPackages []common.Hash    `json:"known_packages"`
-}
-
-type Guarantee struct {
-	Report     Report           `json:"report"`
-	Slot       jamtime.TimeSlot `json:"slot"`
-	Signatures []Signature      `json:"signatures"`
-}
-
-type Signature struct {
-	ValidatorIndex uint32 `json:"validator_index"`
-	Signature      string `json:"signature"`
+	Guarantees    test_utils.GuaranteesExtrinsic `json:"guarantees"`
+	Slot          jamtime.TimeSlot               `json:"slot"`
+	KnownPackages []common.Hash                  `json:"known_packages"`
 }
 
 type State struct {
-	AvailAssignments  []*AvailAssignment  `json:"avail_assignments"`
-	CurrentValidators []ValidatorKey      `json:"curr_validators"`
-	PrevValidators    []ValidatorKey      `json:"prev_validators"`
-	Entropy           []common.Hash       `json:"entropy"`
-	Offenders         []string            `json:"offenders"`
-	RecentBlocks      []RecentBlock       `json:"recent_blocks"`
-	AuthPools         []AuthPool          `json:"auth_pools"`
-	Accounts          []Account           `json:"accounts"`
-	CoreStatistics    []CoreStatistics    `json:"core_statistics"`
-	ServiceStatistics []ServiceStatistics `json:"service_statistics"`
-}
-
-type AvailAssignment struct {
-	Report  Report           `json:"report"`
-	Timeout jamtime.TimeSlot `json:"timeout"`
-}
-
-type Report struct {
-	PackageSpec       PackageSpec             `json:"package_spec"`
-	Context           Context                 `json:"context"`
-	CoreIndex         uint32                  `json:"core_index"`
-	AuthorizerHash    common.Hash             `json:"authorizer_hash"`
-	AuthOutput        string                  `json:"auth_output"`
-	SegmentRootLookup []SegmentRootLookupItem `json:"segment_root_lookup"`
-	Results           []WorkResult            `json:"results"`
-	AuthGasUsed       uint64                  `json:"auth_gas_used"`
-}
-
-type PackageSpec struct {
-	Hash         common.Hash `json:"hash"`
-	Length       uint32      `json:"length"`
-	ErasureRoot  common.Hash `json:"erasure_root"`
-	ExportsRoot  common.Hash `json:"exports_root"`
-	ExportsCount uint        `json:"exports_count"`
-}
-
-type Context struct {
-	Anchor           common.Hash      `json:"anchor"`
-	StateRoot        common.Hash      `json:"state_root"`
-	BeefyRoot        common.Hash      `json:"beefy_root"`
-	LookupAnchor     common.Hash      `json:"lookup_anchor"`
-	LookupAnchorSlot jamtime.TimeSlot `json:"lookup_anchor_slot"`
-	PreRequisites    []common.Hash    `json:"prerequisites"`
-}
-
-type SegmentRootLookupItem struct {
-	WorkPackageHash common.Hash `json:"work_package_hash"`
-	SegmentTreeRoot common.Hash `json:"segment_tree_root"`
-}
-
-type WorkResult struct {
-	ServiceId     service.ServiceId `json:"service_id"`
-	CodeHash      common.Hash       `json:"code_hash"`
-	PayloadHash   common.Hash       `json:"payload_hash"`
-	AccumulateGas service.Gas       `json:"accumulate_gas"`
-	Result        Result            `json:"result"`
-	RefineLoad    RefineLoad        `json:"refine_load"`
-	AuthGasUsed   uint64            `json:"auth_gas_used"`
-}
-
-type Result struct {
-	Ok string `json:"ok"`
-}
-
-type RefineLoad struct {
-	GasUsed        uint64 `json:"gas_used"`
-	Imports        uint32 `json:"imports"`
-	ExtrinsicCount uint32 `json:"extrinsic_count"`
-	ExtrinsicSize  uint32 `json:"extrinsic_size"`
-	Exports        uint32 `json:"exports"`
-}
-
-type ValidatorKey struct {
-	Bandersnatch bandersnatch.PublicKey `json:"bandersnatch"`
-	Ed25519      string                 `json:"ed25519"`
-	Bls          bls.BLSKey             `json:"bls"`
-	Metadata     string                 `json:"metadata"`
+	AvailAssignments  test_utils.AvailabilityAssignments    `json:"avail_assignments"`
+	CurrentValidators test_utils.ValidatorsData             `json:"curr_validators"`
+	PrevValidators    test_utils.ValidatorsData             `json:"prev_validators"`
+	Entropy           [4]common.Hash                        `json:"entropy"`
+	Offenders         []test_utils.Ed25519Public            `json:"offenders"`
+	RecentBlocks      []RecentBlock                         `json:"recent_blocks"`
+	AuthPools         [common.NumOfCores]AuthPool           `json:"auth_pools"`
+	Accounts          []Account                             `json:"accounts"`
+	CoreStatistics    [common.NumOfCores]CoreActivityRecord `json:"core_statistics"`
+	ServiceStatistics []test_utils.ServiceStatisticsItem    `json:"service_statistics"`
 }
 
 type RecentBlock struct {
@@ -374,7 +192,7 @@ type RecentBlock struct {
 }
 
 type MMR struct {
-	Peaks []*common.Hash `json:"peaks"`
+	Peaks mmr.MMR `json:"peaks"`
 }
 
 type Reported struct {
@@ -387,46 +205,19 @@ type AuthPool []common.Hash
 type Account struct {
 	Id   service.ServiceId `json:"id"`
 	Data struct {
-		Service Service `json:"service"`
+		Service test_utils.ServiceInfo `json:"service"`
 	} `json:"data"`
 }
 
-type Service struct {
-	CodeHash   common.Hash     `json:"code_hash"`
-	Balance    service.Balance `json:"balance"`
-	MinItemGas service.Gas     `json:"min_item_gas"`
-	MinMemoGas service.Gas     `json:"min_memo_gas"`
-	Bytes      uint32          `json:"bytes"`
-	Items      uint32          `json:"items"`
-}
-
-type CoreStatistics struct {
-	DALoad         uint32 `json:"da_load"`
-	Popularity     uint32 `json:"popularity"`
-	Imports        uint32 `json:"imports"`
-	Exports        uint32 `json:"exports"`
-	ExtrinsicSize  uint32 `json:"extrinsic_size"`
-	ExtrinsicCount uint32 `json:"extrinsic_count"`
-	BundleSize     uint32 `json:"bundle_size"`
-	GasUsed        uint64 `json:"gas_used"`
-}
-
-type ServiceStatistics struct {
-	Id     service.ServiceId `json:"id"`
-	Record struct {
-		ProvidedCount     uint32 `json:"provided_count"`
-		ProvidedSize      uint32 `json:"provided_size"`
-		RefinementCount   uint32 `json:"refinement_count"`
-		RefinementGasUsed uint64 `json:"refinement_gas_used"`
-		Imports           uint32 `json:"imports"`
-		Exports           uint32 `json:"exports"`
-		ExtrinsicSize     uint32 `json:"extrinsic_size"`
-		ExtrinsicCount    uint32 `json:"extrinsic_count"`
-		AccumulateCount   uint32 `json:"accumulate_count"`
-		AccumulateGasUsed uint64 `json:"accumulate_gas_used"`
-		OnTransferCount   uint32 `json:"on_transfer_count"`
-		OnTransferGasUsed uint64 `json:"on_transfer_gas_used"`
-	} `json:"record"`
+type CoreActivityRecord struct {
+	DALoad         uint32      `json:"da_load" codec:"compact"`
+	Popularity     uint32      `json:"popularity" codec:"compact"`
+	Imports        uint32      `json:"imports" codec:"compact"`
+	Exports        uint32      `json:"exports" codec:"compact"`
+	ExtrinsicSize  uint32      `json:"extrinsic_size" codec:"compact"`
+	ExtrinsicCount uint32      `json:"extrinsic_count" codec:"compact"`
+	BundleSize     uint32      `json:"bundle_size" codec:"compact"`
+	GasUsed        service.Gas `json:"gas_used" codec:"compact"`
 }
 
 type Output struct {
@@ -434,10 +225,12 @@ type Output struct {
 	Err string `json:"err"`
 }
 
+func (o *Output) UnmarshalJAM(d *codec.Decoder) (err error) {
+	o.Err, err = test_utils.DecodeOutput(d, &o.Ok, errorCodes)
+	return err
+}
+
 type Ok struct {
-	Reported []struct {
-		WorkPackageHash common.Hash `json:"work_package_hash"`
-		SegmentTreeRoot common.Hash `json:"segment_tree_root"`
-	} `json:"reported"`
-	Reporters []string `json:"reporters"`
+	Reported  []test_utils.SegmentRootLookupItem `json:"reported"`
+	Reporters []test_utils.Ed25519Public         `json:"reporters"`
 }
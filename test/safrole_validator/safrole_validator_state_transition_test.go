@@ -8,23 +8,19 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/shunsukew/gojam/internal/block"
 	e "github.com/shunsukew/gojam/internal/entropy"
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/validator"
-	"github.com/shunsukew/gojam/internal/validator/keys"
 	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
-	"github.com/shunsukew/gojam/pkg/crypto/bls"
 	test_utils "github.com/shunsukew/gojam/test/utils"
 	"github.com/stretchr/testify/require"
 )
 
-func hexToEd25519PublicKey(hex string) ed25519.PublicKey {
-	return ed25519.PublicKey(common.FromHex(hex))
-}
-
 func TestSafroleAndValidatorStateTransition(t *testing.T) {
 	t.Run(testSpec, func(t *testing.T) {
 		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
@@ -46,14 +42,17 @@ func TestSafroleAndValidatorStateTransition(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
 				// Prepare inputs of state transition function
 				currentTimeSlot := testVector.Input.Slot
 				prevTimeSlot := testVector.PreState.Tau
 				entropy := bandersnatch.VrfOutput(testVector.Input.Entropy)
-				entropyPool := e.EntropyPool{}
-				for i := range len(entropyPool) {
-					entropyPool[i] = testVector.PreState.Eta[i]
-				}
+				entropyPool := e.EntropyPool(testVector.PreState.Eta)
 				tickets := make([]safrole.TicketProof, len(testVector.Input.Extrinsic))
 				for i, extrinsic := range testVector.Input.Extrinsic {
 					tickets[i] = safrole.TicketProof{
@@ -63,7 +62,7 @@ func TestSafroleAndValidatorStateTransition(t *testing.T) {
 				}
 				offenders := make([]ed25519.PublicKey, len(testVector.PreState.PostOffenders))
 				for i, offender := range testVector.PreState.PostOffenders {
-					offenders[i] = hexToEd25519PublicKey(offender)
+					offenders[i] = ed25519.PublicKey(offender[:])
 				}
 
 				validatorState, err := toValidatorState(testVector.PreState)
@@ -79,10 +78,6 @@ func TestSafroleAndValidatorStateTransition(t *testing.T) {
 				expectedOutput := testVector.Output
 				var expectedEpochMarker *block.EpochMarker
 				if expectedOutput.Ok.EpochMark != nil {
-					bandersnatchKeys := [common.NumOfValidators]bandersnatch.PublicKey{}
-					for i, validator := range expectedOutput.Ok.EpochMark.Validators {
-						bandersnatchKeys[i] = bandersnatch.PublicKey(common.FromHex(validator.Bandersnatch))
-					}
 					expectedEpochMarker = &block.EpochMarker{
 						Entropies: struct {
 							Next    common.Hash
@@ -91,7 +86,10 @@ func TestSafroleAndValidatorStateTransition(t *testing.T) {
 							Next:    expectedOutput.Ok.EpochMark.Entropy,
 							Current: expectedOutput.Ok.EpochMark.TicketEntropy,
 						},
-						BandersnatchPubKeys: bandersnatchKeys,
+					}
+					for i, validator := range expectedOutput.Ok.EpochMark.Validators {
+						expectedEpochMarker.Validators[i].Bandersnatch = validator.Bandersnatch
+						expectedEpochMarker.Validators[i].Ed25519 = ed25519.PublicKey(validator.Ed25519[:])
 					}
 				}
 
@@ -144,43 +142,6 @@ func TestSafroleAndValidatorStateTransition(t *testing.T) {
 func toValidatorState(
 	state State,
 ) (*validator.ValidatorState, error) {
-	pendingValidators := [common.NumOfValidators]*keys.ValidatorKey{}
-	for i, validator := range state.GammaK {
-		pendingValidators[i] = &keys.ValidatorKey{
-			BandersnatchPublicKey: validator.Bandersnatch,
-			Ed25519PublicKey:      hexToEd25519PublicKey(validator.Ed25519),
-			BLSKey:                validator.Bls,
-			Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(validator.Metadata)),
-		}
-	}
-	stagingValidators := [common.NumOfValidators]*keys.ValidatorKey{}
-	for i, validator := range state.Iota {
-		stagingValidators[i] = &keys.ValidatorKey{
-			BandersnatchPublicKey: validator.Bandersnatch,
-			Ed25519PublicKey:      hexToEd25519PublicKey(validator.Ed25519),
-			BLSKey:                validator.Bls,
-			Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(validator.Metadata)),
-		}
-	}
-	activeValidators := [common.NumOfValidators]*keys.ValidatorKey{}
-	for i, validator := range state.Kappa {
-		activeValidators[i] = &keys.ValidatorKey{
-			BandersnatchPublicKey: validator.Bandersnatch,
-			Ed25519PublicKey:      hexToEd25519PublicKey(validator.Ed25519),
-			BLSKey:                validator.Bls,
-			Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(validator.Metadata)),
-		}
-	}
-	archivedValidators := [common.NumOfValidators]*keys.ValidatorKey{}
-	for i, validator := range state.Lambda {
-		archivedValidators[i] = &keys.ValidatorKey{
-			BandersnatchPublicKey: validator.Bandersnatch,
-			Ed25519PublicKey:      hexToEd25519PublicKey(validator.Ed25519),
-			BLSKey:                validator.Bls,
-			Metadata:              [keys.ValidatorKeyMetadataSize]byte(common.FromHex(validator.Metadata)),
-		}
-	}
-
 	// sealing key series
 	var sealingKeySeries safrole.SealingKeySeriesKind
 	if len(state.GammaS.Tickets) != 0 {
@@ -196,7 +157,7 @@ func toValidatorState(
 		// fallback mode
 		fallbackKeys := safrole.FallbackKeys{}
 		for i, key := range state.GammaS.Keys {
-			fallbackKeys[i] = bandersnatch.PublicKey(common.FromHex(key))
+			fallbackKeys[i] = key
 		}
 		sealingKeySeries = &fallbackKeys
 	}
@@ -210,7 +171,7 @@ func toValidatorState(
 	}
 
 	safroleState := &safrole.SafroleState{
-		PendingValidators:  &pendingValidators,
+		PendingValidators:  test_utils.ToValidatorKeys(state.GammaK),
 		EpochRoot:          &state.GammaZ,
 		SealingKeySeries:   sealingKeySeries,
 		TicketsAccumulator: ticketAccumulator,
@@ -218,48 +179,47 @@ func toValidatorState(
 
 	return &validator.ValidatorState{
 		SafroleState:       safroleState,
-		StagingValidators:  &stagingValidators,
-		ActiveValidators:   &activeValidators,
-		ArchivedValidators: &archivedValidators,
+		StagingValidators:  test_utils.ToValidatorKeys(state.Iota),
+		ActiveValidators:   test_utils.ToValidatorKeys(state.Kappa),
+		ArchivedValidators: test_utils.ToValidatorKeys(state.Lambda),
 	}, nil
 }
 
+// Error codes of the test vectors in the order of their discriminators.
+var errorCodes = []string{
+	"bad_slot",
+	"unexpected_ticket",
+	"bad_ticket_order",
+	"bad_ticket_proof",
+	"bad_ticket_attempt",
+	"reserved",
+	"duplicate_ticket",
+}
+
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
-	Slot      jamtime.TimeSlot `json:"slot"`
-	Entropy   common.Hash      `json:"entropy"`
-	Extrinsic []Extrinsic      `json:"extrinsic"`
-}
-
-type Extrinsic struct {
-	Attempt   uint8                  `json:"attempt"`
-	Signature bandersnatch.Signature `json:"signature"`
+	Slot      jamtime.TimeSlot            `json:"slot"`
+	Entropy   common.Hash                 `json:"entropy"`
+	Extrinsic test_utils.TicketsExtrinsic `json:"extrinsic"`
 }
 
 type State struct {
-	Tau           jamtime.TimeSlot            `json:"tau"`
-	Eta           []common.Hash               `json:"eta"`
-	Lambda        []ValidatorKey              `json:"lambda"`
-	Kappa         []ValidatorKey              `json:"kappa"`
-	GammaK        []ValidatorKey              `json:"gamma_k"`
-	Iota          []ValidatorKey              `json:"iota"`
-	GammaA        []Ticket                    `json:"gamma_a"`
-	GammaS        SealingKeySeries            `json:"gamma_s"`
-	GammaZ        bandersnatch.RingCommitment `json:"gamma_z"`
-	PostOffenders []string                    `json:"post_offenders"`
-}
-
-type ValidatorKey struct {
-	Bandersnatch bandersnatch.PublicKey `json:"bandersnatch"`
-	Ed25519      string                 `json:"ed25519"`
-	Bls          bls.BLSKey             `json:"bls"`
-	Metadata     string                 `json:"metadata"`
+	Tau           jamtime.TimeSlot               `json:"tau"`
+	Eta           [e.EntropyPoolSize]common.Hash `json:"eta"`
+	Lambda        test_utils.ValidatorsData      `json:"lambda"`
+	Kappa         test_utils.ValidatorsData      `json:"kappa"`
+	GammaK        test_utils.ValidatorsData      `json:"gamma_k"`
+	Iota          test_utils.ValidatorsData      `json:"iota"`
+	GammaA        []Ticket                       `json:"gamma_a"`
+	GammaS        SealingKeySeries               `json:"gamma_s"`
+	GammaZ        bandersnatch.RingCommitment    `json:"gamma_z"`
+	PostOffenders []test_utils.Ed25519Public     `json:"post_offenders"`
 }
 
 type Ticket struct {
@@ -267,9 +227,38 @@ type Ticket struct {
 	Attempt uint8                  `json:"attempt"`
 }
 
+type TicketsMark [jamtime.TimeSlotsPerEpoch]Ticket
+
+// Either the tickets or the fallback keys, 0 followed by the tickets or 1 followed by the keys.
 type SealingKeySeries struct {
-	Tickets []Ticket `json:"tickets"`
-	Keys    []string `json:"keys"`
+	Tickets []Ticket                 `json:"tickets"`
+	Keys    []bandersnatch.PublicKey `json:"keys"`
+}
+
+func (s *SealingKeySeries) UnmarshalJAM(d *codec.Decoder) error {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case 0:
+		var tickets TicketsMark
+		if err := d.Decode(&tickets); err != nil {
+			return err
+		}
+		s.Tickets = tickets[:]
+	case 1:
+		var keys [jamtime.TimeSlotsPerEpoch]bandersnatch.PublicKey
+		if err := d.Decode(&keys); err != nil {
+			return err
+		}
+		s.Keys = keys[:]
+	default:
+		return errors.Errorf("unknown sealing key series discriminator: %d", kind)
+	}
+
+	return nil
 }
 
 type Output struct {
@@ -277,18 +266,23 @@ type Output struct {
 	Err string `json:"err"`
 }
 
+func (o *Output) UnmarshalJAM(d *codec.Decoder) (err error) {
+	o.Err, err = test_utils.DecodeOutput(d, &o.Ok, errorCodes)
+	return err
+}
+
 type Ok struct {
-	EpochMark   *EpochMarker `json:"epoch_mark"`
-	TicketsMark []Ticket     `json:"tickets_mark"`
+	EpochMark   *EpochMarker `json:"epoch_mark" codec:"optional"`
+	TicketsMark *TicketsMark `json:"tickets_mark" codec:"optional"`
 }
 
 type EpochMarker struct {
-	Entropy       common.Hash            `json:"entropy"`
-	TicketEntropy common.Hash            `json:"tickets_entropy"`
-	Validators    []EpochMarkerValidator `json:"validators"`
+	Entropy       common.Hash                                  `json:"entropy"`
+	TicketEntropy common.Hash                                  `json:"tickets_entropy"`
+	Validators    [common.NumOfValidators]EpochMarkerValidator `json:"validators"`
 }
 
 type EpochMarkerValidator struct {
-	Bandersnatch string `json:"bandersnatch"`
-	Ed25519      string `json:"ed25519"`
+	Bandersnatch bandersnatch.PublicKey   `json:"bandersnatch"`
+	Ed25519      test_utils.Ed25519Public `json:"ed25519"`
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/shunsukew/gojam/internal/jamtime"
 	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
 	"github.com/shunsukew/gojam/pkg/common"
 	testutils "github.com/shunsukew/gojam/test/utils"
 	"github.com/stretchr/testify/require"
@@ -35,31 +36,86 @@ func TestServiceAccountsStateTransition(t *testing.T) {
 					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
 				}
 
+				binFilePath := testutils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = testutils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
+				services := toServices(testVector.PreState.Accounts)
+				expectedServices := toServices(testVector.PostState.Accounts)
+
+				preimages := make([]*service.PreimageRequest, len(testVector.Input.Preimages))
+				for i, preimage := range testVector.Input.Preimages {
+					preimages[i] = &service.PreimageRequest{
+						ServiceId: preimage.Requester,
+						Preimage:  preimage.Blob,
+					}
+				}
+
+				_, err = services.Update(toServices(testVector.PreState.Accounts), testVector.Input.Slot, preimages)
+				if testVector.Output.Err != "" {
+					require.ErrorIs(t, err, errorCodes[testVector.Output.Err], "error expected: %v", testVector.Output.Err)
+					require.Equal(t, expectedServices, services, "services must not change on error")
+					return
+				}
+
+				require.NoError(t, err, "failed to update services")
+				require.Equal(t, expectedServices, services, "services mismatch")
 			})
 		}
 	})
 }
 
+var errorCodes = map[string]error{
+	"preimages_not_sorted_unique": service.ErrPreimagesNotSortedUnique,
+	"preimage_unneeded":           service.ErrPreimageUnneeded,
+}
+
+// Error codes of the test vectors in the order of their discriminators.
+var errorCodeNames = []string{
+	"preimage_unneeded",
+	"preimages_not_sorted_unique",
+}
+
+func toServices(accounts []Account) *service.Services {
+	services := &service.Services{}
+	for _, account := range accounts {
+		serviceAccount := &service.ServiceAccount{
+			Preimages:    make(map[common.Hash]common.Blob, len(account.Data.Preimages)),
+			PreimageMeta: make(map[service.PreimageMeta]service.PreimageAvailabilityHistory, len(account.Data.LookupMeta)),
+		}
+		for _, preimage := range account.Data.Preimages {
+			serviceAccount.Preimages[preimage.Hash] = preimage.Blob
+		}
+		for _, item := range account.Data.LookupMeta {
+			history := make(service.PreimageAvailabilityHistory, len(item.Value))
+			copy(history, item.Value)
+			serviceAccount.PreimageMeta[service.PreimageMeta{
+				Hash:       item.Key.Hash,
+				BlobLength: item.Key.Length,
+			}] = history
+		}
+		services.Save(account.Id, serviceAccount)
+	}
+	return services
+}
+
 type TestVector struct {
 	Input     Input  `json:"input"`
 	PreState  State  `json:"pre_state"`
-	PostState State  `json:"post_state"`
 	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
 }
 
 type Input struct {
-	Preimages []PreimageInput  `json:"preimages"`
-	Slot      jamtime.TimeSlot `json:"slot"`
-}
-
-type PreimageInput struct {
-	Requester uint32      `json:"requester"`
-	Blob      common.Blob `json:"blob"`
+	Preimages testutils.PreimagesExtrinsic `json:"preimages"`
+	Slot      jamtime.TimeSlot             `json:"slot"`
 }
 
 type State struct {
-	Accounts   []Account        `json:"accounts"`
-	Statistics []StatisticsItem `json:"statistics"`
+	Accounts   []Account                         `json:"accounts"`
+	Statistics []testutils.ServiceStatisticsItem `json:"statistics"`
 }
 
 type Account struct {
@@ -79,31 +135,18 @@ type Preimage struct {
 
 type LookupMetaItem struct {
 	Key struct {
-		Hash   common.Hash `json:"hash"`
-		Length uint32      `json:"length"`
+		Hash   common.Hash       `json:"hash"`
+		Length common.BlobLength `json:"length"`
 	} `json:"key"`
-	Value []uint32 `json:"value"`
-}
-
-type StatisticsItem struct {
-	Id     service.ServiceId `json:"id"`
-	Record struct {
-		ProvidedCount     uint32 `json:"provided_count"`
-		ProvidedSize      uint32 `json:"provided_size"`
-		RefinementCount   uint32 `json:"refinement_count"`
-		RefinementGasUsed uint32 `json:"refinement_gas_used"`
-		Imports           uint32 `json:"imports"`
-		Exports           uint32 `json:"exports"`
-		ExtrinsicSize     uint32 `json:"extrinsic_size"`
-		ExtrinsicCount    uint32 `json:"extrinsic_count"`
-		AccumulateCount   uint32 `json:"accumulate_count"`
-		AccumulateGasUsed uint32 `json:"accumulate_gas_used"`
-		OnTransferCount   uint32 `json:"on_transfer_count"`
-		OnTransferGasUsed uint32 `json:"on_transfer_gas_used"`
-	} `json:"record"`
+	Value []jamtime.TimeSlot `json:"value"`
 }
 
 type Output struct {
 	Ok  *struct{} `json:"ok"`
 	Err string    `json:"err"`
 }
+
+func (o *Output) UnmarshalJAM(d *codec.Decoder) (err error) {
+	o.Err, err = testutils.DecodeOutput(d, nil, errorCodeNames)
+	return err
+}
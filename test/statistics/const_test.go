@@ -0,0 +1,8 @@
+//go:build !tiny
+
+package statistics_test
+
+const (
+	testSpec         = "Full"
+	vectorFolderPath = "../../@jamtestvectors-davxy/statistics/full"
+)
@@ -0,0 +1,8 @@
+//go:build tiny
+
+package statistics_test
+
+const (
+	testSpec         = "Tiny"
+	vectorFolderPath = "../../@jamtestvectors-davxy/statistics/tiny"
+)
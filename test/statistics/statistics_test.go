@@ -0,0 +1,148 @@
+package statistics_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shunsukew/gojam/internal/block"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/statistics"
+	"github.com/shunsukew/gojam/internal/validator/safrole"
+	"github.com/shunsukew/gojam/pkg/common"
+	test_utils "github.com/shunsukew/gojam/test/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorStatisticsStateTransition(t *testing.T) {
+	t.Run(testSpec, func(t *testing.T) {
+		filePaths, err := test_utils.GetJsonFilePaths(vectorFolderPath)
+		if err != nil {
+			require.NoError(t, err, "failed to get JSON file paths")
+		}
+
+		for _, filePath := range filePaths {
+			testCase := fmt.Sprintf("Test %s", filepath.Base(filePath))
+			t.Run(testCase, func(t *testing.T) {
+				file, err := os.ReadFile(filePath)
+				if err != nil {
+					require.NoErrorf(t, err, "failed to read test vector file: %s", filePath)
+				}
+
+				var testVector TestVector
+				err = json.Unmarshal(file, &testVector)
+				if err != nil {
+					require.NoError(t, err, "failed to unmarshal test vector: %s", filePath)
+				}
+
+				binFilePath := test_utils.BinFilePath(filePath)
+				var binTestVector TestVector
+				err = test_utils.DecodeBinFile(binFilePath, &binTestVector)
+				require.NoErrorf(t, err, "failed to decode test vector: %s", binFilePath)
+				require.Equal(t, testVector, binTestVector, "expected JSON and binary test vectors to match")
+
+				currentValidators := test_utils.ToValidatorKeys(testVector.PreState.CurrentValidators)
+				extrinsic := toExtrinsic(testVector.Input.Extrinsic)
+
+				// R: The guarantors of the incoming work reports, identified by the credential validator indices in κ′.
+				reporters := make([]ed25519.PublicKey, 0)
+				for _, guarantee := range extrinsic.Guarantees {
+					for _, credential := range guarantee.Credentials {
+						reporters = append(reporters, currentValidators[credential.ValidatorIndex].Ed25519PublicKey)
+					}
+				}
+
+				activityStatistics := &statistics.ActivityStatistics{
+					Current: toValidatorStatistics(testVector.PreState.CurrentStatistics),
+					Last:    toValidatorStatistics(testVector.PreState.LastStatistics),
+				}
+
+				err = activityStatistics.UpdateValidators(
+					testVector.Input.Slot,
+					testVector.PreState.Slot,
+					testVector.Input.AuthorIndex,
+					extrinsic,
+					reporters,
+					currentValidators,
+				)
+				require.NoError(t, err, "failed to update validator statistics")
+
+				require.Equal(t, toValidatorStatistics(testVector.PostState.CurrentStatistics), activityStatistics.Current, "current epoch statistics mismatch")
+				require.Equal(t, toValidatorStatistics(testVector.PostState.LastStatistics), activityStatistics.Last, "last epoch statistics mismatch")
+			})
+		}
+	})
+}
+
+func toValidatorStatistics(input [common.NumOfValidators]ValidatorRecord) statistics.ValidatorStatistics {
+	var validatorStatistics statistics.ValidatorStatistics
+	for i, record := range input {
+		validatorStatistics[i] = statistics.ValidatorRecord{
+			Blocks:        record.Blocks,
+			Tickets:       record.Tickets,
+			Preimages:     record.Preimages,
+			PreimagesSize: record.PreimagesSize,
+			Guarantees:    record.Guarantees,
+			Assurances:    record.Assurances,
+		}
+	}
+	return validatorStatistics
+}
+
+// toExtrinsic converts the parts of the extrinsic the validator statistics depend on.
+func toExtrinsic(input test_utils.Extrinsic) *block.Extrinsic {
+	extrinsic := &block.Extrinsic{}
+
+	for _, ticket := range input.Tickets {
+		extrinsic.Tickets = append(extrinsic.Tickets, safrole.TicketProof{
+			EntryIndex:  ticket.Attempt,
+			TicketProof: ticket.Signature,
+		})
+	}
+
+	for _, preimage := range input.Preimages {
+		extrinsic.Preimages = append(extrinsic.Preimages, block.Preimage{
+			Requester: preimage.Requester,
+			Blob:      preimage.Blob,
+		})
+	}
+
+	extrinsic.Guarantees = test_utils.ToGuarantees(input.Guarantees)
+	extrinsic.Assurances = test_utils.ToAssurances(input.Assurances)
+
+	return extrinsic
+}
+
+type TestVector struct {
+	Input     Input  `json:"input"`
+	PreState  State  `json:"pre_state"`
+	Output    Output `json:"output"`
+	PostState State  `json:"post_state"`
+}
+
+type Input struct {
+	Slot        jamtime.TimeSlot     `json:"slot"`
+	AuthorIndex uint16               `json:"author_index"`
+	Extrinsic   test_utils.Extrinsic `json:"extrinsic"`
+}
+
+type State struct {
+	CurrentStatistics [common.NumOfValidators]ValidatorRecord `json:"vals_curr_stats"`
+	LastStatistics    [common.NumOfValidators]ValidatorRecord `json:"vals_last_stats"`
+	Slot              jamtime.TimeSlot                        `json:"slot"`
+	CurrentValidators test_utils.ValidatorsData               `json:"curr_validators"`
+}
+
+type ValidatorRecord struct {
+	Blocks        uint32 `json:"blocks"`
+	Tickets       uint32 `json:"tickets"`
+	Preimages     uint32 `json:"pre_images"`
+	PreimagesSize uint32 `json:"pre_images_size"`
+	Guarantees    uint32 `json:"guarantees"`
+	Assurances    uint32 `json:"assurances"`
+}
+
+type Output struct{}
@@ -0,0 +1,129 @@
+package test_utils
+
+import (
+	"crypto/ed25519"
+
+	"github.com/shunsukew/gojam/internal/validator/keys"
+	"github.com/shunsukew/gojam/internal/work"
+	workreport "github.com/shunsukew/gojam/internal/work/report"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+)
+
+func ToWorkReport(report WorkReport) *workreport.WorkReport {
+	segmentRootLookup := make(map[common.Hash]common.Hash, len(report.SegmentRootLookup))
+	for _, item := range report.SegmentRootLookup {
+		segmentRootLookup[item.WorkPackageHash] = item.SegmentTreeRoot
+	}
+
+	results := make([]*workreport.WorkResult, len(report.Results))
+	for i, result := range report.Results {
+		execResult := &workreport.ExecResult{Output: result.Result.Ok}
+		if result.Result.Kind != 0 {
+			execResult = &workreport.ExecResult{Error: workreport.ExecError(result.Result.Kind - 1)}
+		}
+
+		results[i] = &workreport.WorkResult{
+			ServiceId:       result.ServiceId,
+			ServiceCodeHash: result.CodeHash,
+			PayloadHash:     result.PayloadHash,
+			Gas:             result.AccumulateGas,
+			ExecResult:      execResult,
+			RefineLoad: workreport.RefineLoad{
+				GasUsed:        result.RefineLoad.GasUsed,
+				Imports:        result.RefineLoad.Imports,
+				ExtrinsicCount: result.RefineLoad.ExtrinsicCount,
+				ExtrinsicSize:  result.RefineLoad.ExtrinsicSize,
+				Exports:        result.RefineLoad.Exports,
+			},
+		}
+	}
+
+	return &workreport.WorkReport{
+		AvailabilitySpecification: &workreport.AvailabilitySpecification{
+			WorkPackageHash:  report.PackageSpec.Hash,
+			WorkBundleLength: report.PackageSpec.Length,
+			ErasureRoot:      report.PackageSpec.ErasureRoot,
+			SegmentRoot:      report.PackageSpec.ExportsRoot,
+			SegmentCount:     uint(report.PackageSpec.ExportsCount),
+		},
+		RefinementContext: &work.RefinementContext{
+			AnchorHeaderHash:              report.Context.Anchor,
+			AnchorStateRoot:               report.Context.StateRoot,
+			AnchorBeefyRoot:               report.Context.BeefyRoot,
+			LookupAnchorHeaderHash:        report.Context.LookupAnchor,
+			LookupAnchorTimeSlot:          report.Context.LookupAnchorSlot,
+			PreRequisiteWorkPackageHashes: report.Context.Prerequisites,
+		},
+		CoreIndex:         uint32(report.CoreIndex),
+		AuthorizerHash:    report.AuthorizerHash,
+		Output:            report.AuthOutput,
+		SegmentRootLookup: segmentRootLookup,
+		WorkResults:       results,
+		AuthGasUsed:       report.AuthGasUsed,
+	}
+}
+
+func ToValidatorKeys(validators ValidatorsData) *[common.NumOfValidators]*keys.ValidatorKey {
+	validatorKeys := &[common.NumOfValidators]*keys.ValidatorKey{}
+	for i, validator := range validators {
+		validatorKeys[i] = &keys.ValidatorKey{
+			BandersnatchPublicKey: validator.Bandersnatch,
+			Ed25519PublicKey:      ed25519.PublicKey(validator.Ed25519[:]),
+			BLSKey:                validator.Bls,
+			Metadata:              validator.Metadata,
+		}
+	}
+	return validatorKeys
+}
+
+func ToPendingWorkReports(assignments AvailabilityAssignments) *workreport.PendingWorkReports {
+	pendingWorkReports := &workreport.PendingWorkReports{}
+	for i, assignment := range assignments {
+		if assignment == nil {
+			continue
+		}
+
+		pendingWorkReports[i] = &workreport.PendingWorkReport{
+			ReportedAt: assignment.Timeout, // TODO: Test vector should rename field from `timeout` to `reported_at`. Otherwise, really confusing. Actual timeout of reports in test vectors are `timeout` val + PendingWorkReportTimeout 5 slots.
+			WorkReport: ToWorkReport(assignment.Report),
+		}
+	}
+	return pendingWorkReports
+}
+
+func ToGuarantees(input GuaranteesExtrinsic) workreport.Guarantees {
+	guarantees := make(workreport.Guarantees, len(input))
+	for i, guarantee := range input {
+		credentials := make([]*workreport.Credential, len(guarantee.Signatures))
+		for j, signature := range guarantee.Signatures {
+			credentials[j] = &workreport.Credential{
+				ValidatorIndex: uint32(signature.ValidatorIndex),
+				Signature:      signature.Signature[:],
+			}
+		}
+
+		guarantees[i] = &workreport.Guarantee{
+			WorkReport:  ToWorkReport(guarantee.Report),
+			Timeslot:    guarantee.Slot,
+			Credentials: credentials,
+		}
+	}
+	return guarantees
+}
+
+func ToAssurances(input AssurancesExtrinsic) workreport.Assurances {
+	assurances := make(workreport.Assurances, len(input))
+	for i, assurance := range input {
+		var availabilities [common.NumOfCores]bool
+		copy(availabilities[:], codec.DecodeBitSequence(assurance.Bitfield[:], common.NumOfCores))
+
+		assurances[i] = &workreport.Assurance{
+			AnchorParentHash:         assurance.Anchor,
+			WorkReportAvailabilities: availabilities,
+			ValidatorIndex:           uint32(assurance.ValidatorIndex),
+			Signature:                assurance.Signature[:],
+		}
+	}
+	return assurances
+}
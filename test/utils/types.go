@@ -0,0 +1,335 @@
+package test_utils
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/shunsukew/gojam/internal/jamtime"
+	"github.com/shunsukew/gojam/internal/service"
+	"github.com/shunsukew/gojam/pkg/codec"
+	"github.com/shunsukew/gojam/pkg/common"
+	"github.com/shunsukew/gojam/pkg/crypto/bandersnatch"
+	"github.com/shunsukew/gojam/pkg/crypto/bls"
+)
+
+// Types of the test vectors shared among the suites. They are laid out as the ASN.1 schema of the test vectors,
+// so that they decode from both the JSON and the codec-encoded test vectors.
+
+type Ed25519Public [32]byte
+
+func (k *Ed25519Public) UnmarshalJSON(data []byte) error {
+	return unmarshalFixedHex(data, k[:])
+}
+
+type Ed25519Signature [64]byte
+
+func (s *Ed25519Signature) UnmarshalJSON(data []byte) error {
+	return unmarshalFixedHex(data, s[:])
+}
+
+type ValidatorMetadata [128]byte
+
+func (m *ValidatorMetadata) UnmarshalJSON(data []byte) error {
+	return unmarshalFixedHex(data, m[:])
+}
+
+type AvailabilityBitfield [(common.NumOfCores + 7) / 8]byte
+
+func (b *AvailabilityBitfield) UnmarshalJSON(data []byte) error {
+	return unmarshalFixedHex(data, b[:])
+}
+
+func unmarshalFixedHex(data []byte, dst []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	bytes := common.FromHex(s)
+	if len(bytes) != len(dst) {
+		return errors.Errorf("invalid length, expected %d, got %d", len(dst), len(bytes))
+	}
+	copy(dst, bytes)
+
+	return nil
+}
+
+type ValidatorKey struct {
+	Bandersnatch bandersnatch.PublicKey `json:"bandersnatch"`
+	Ed25519      Ed25519Public          `json:"ed25519"`
+	Bls          bls.BLSKey             `json:"bls"`
+	Metadata     ValidatorMetadata      `json:"metadata"`
+}
+
+type ValidatorsData [common.NumOfValidators]ValidatorKey
+
+type RefineContext struct {
+	Anchor           common.Hash      `json:"anchor"`
+	StateRoot        common.Hash      `json:"state_root"`
+	BeefyRoot        common.Hash      `json:"beefy_root"`
+	LookupAnchor     common.Hash      `json:"lookup_anchor"`
+	LookupAnchorSlot jamtime.TimeSlot `json:"lookup_anchor_slot"`
+	Prerequisites    []common.Hash    `json:"prerequisites"`
+}
+
+// Work execution result names in the order of their discriminators.
+var workExecResultKinds = []string{"ok", "out_of_gas", "panic", "bad_exports", "bad_code", "code_oversize"}
+
+type WorkExecResult struct {
+	Kind uint8
+	Ok   common.Blob
+}
+
+func (r *WorkExecResult) UnmarshalJSON(data []byte) error {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	for kind, name := range workExecResultKinds {
+		value, ok := result[name]
+		if !ok {
+			continue
+		}
+
+		r.Kind = uint8(kind)
+		if kind == 0 {
+			return json.Unmarshal(value, &r.Ok)
+		}
+		return nil
+	}
+
+	return errors.Errorf("unknown work exec result: %s", string(data))
+}
+
+func (r WorkExecResult) MarshalJAM() ([]byte, error) {
+	if r.Kind == 0 {
+		return append([]byte{0}, codec.EncodeLengthPrefixed(r.Ok)...), nil
+	}
+	return []byte{r.Kind}, nil
+}
+
+func (r *WorkExecResult) UnmarshalJAM(d *codec.Decoder) error {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return err
+	}
+	if int(kind) >= len(workExecResultKinds) {
+		return errors.Errorf("unknown work exec result discriminator: %d", kind)
+	}
+
+	r.Kind = kind
+	if kind == 0 {
+		ok, err := d.DecodeLengthPrefixed()
+		if err != nil {
+			return err
+		}
+		r.Ok = ok
+	}
+
+	return nil
+}
+
+type RefineLoad struct {
+	GasUsed        service.Gas `json:"gas_used" codec:"compact"`
+	Imports        uint32      `json:"imports" codec:"compact"`
+	ExtrinsicCount uint32      `json:"extrinsic_count" codec:"compact"`
+	ExtrinsicSize  uint32      `json:"extrinsic_size" codec:"compact"`
+	Exports        uint32      `json:"exports" codec:"compact"`
+}
+
+type WorkResult struct {
+	ServiceId     service.ServiceId `json:"service_id"`
+	CodeHash      common.Hash       `json:"code_hash"`
+	PayloadHash   common.Hash       `json:"payload_hash"`
+	AccumulateGas service.Gas       `json:"accumulate_gas"`
+	Result        WorkExecResult    `json:"result"`
+	RefineLoad    RefineLoad        `json:"refine_load"`
+}
+
+type WorkPackageSpec struct {
+	Hash         common.Hash `json:"hash"`
+	Length       uint32      `json:"length"`
+	ErasureRoot  common.Hash `json:"erasure_root"`
+	ExportsRoot  common.Hash `json:"exports_root"`
+	ExportsCount uint16      `json:"exports_count"`
+}
+
+type SegmentRootLookupItem struct {
+	WorkPackageHash common.Hash `json:"work_package_hash"`
+	SegmentTreeRoot common.Hash `json:"segment_tree_root"`
+}
+
+type WorkReport struct {
+	PackageSpec       WorkPackageSpec         `json:"package_spec"`
+	Context           RefineContext           `json:"context"`
+	CoreIndex         uint16                  `json:"core_index"`
+	AuthorizerHash    common.Hash             `json:"authorizer_hash"`
+	AuthOutput        common.Blob             `json:"auth_output"`
+	SegmentRootLookup []SegmentRootLookupItem `json:"segment_root_lookup"`
+	Results           []WorkResult            `json:"results"`
+	AuthGasUsed       service.Gas             `json:"auth_gas_used" codec:"compact"`
+}
+
+type AvailabilityAssignment struct {
+	Report  WorkReport       `json:"report"`
+	Timeout jamtime.TimeSlot `json:"timeout"`
+}
+
+// The pending work report of each core, null if the core is not engaged.
+type AvailabilityAssignments [common.NumOfCores]*AvailabilityAssignment
+
+func (a *AvailabilityAssignments) UnmarshalJAM(d *codec.Decoder) error {
+	return DecodeOptionals(d, a[:])
+}
+
+type TicketEnvelope struct {
+	Attempt   uint8                  `json:"attempt"`
+	Signature bandersnatch.Signature `json:"signature"`
+}
+
+type TicketsExtrinsic []TicketEnvelope
+
+type Preimage struct {
+	Requester service.ServiceId `json:"requester"`
+	Blob      common.Blob       `json:"blob"`
+}
+
+type PreimagesExtrinsic []Preimage
+
+type ValidatorSignature struct {
+	ValidatorIndex uint16           `json:"validator_index"`
+	Signature      Ed25519Signature `json:"signature"`
+}
+
+type ReportGuarantee struct {
+	Report     WorkReport           `json:"report"`
+	Slot       jamtime.TimeSlot     `json:"slot"`
+	Signatures []ValidatorSignature `json:"signatures"`
+}
+
+type GuaranteesExtrinsic []ReportGuarantee
+
+type AvailAssurance struct {
+	Anchor         common.Hash          `json:"anchor"`
+	Bitfield       AvailabilityBitfield `json:"bitfield"`
+	ValidatorIndex uint16               `json:"validator_index"`
+	Signature      Ed25519Signature     `json:"signature"`
+}
+
+type AssurancesExtrinsic []AvailAssurance
+
+type Judgement struct {
+	Vote      bool             `json:"vote"`
+	Index     uint16           `json:"index"`
+	Signature Ed25519Signature `json:"signature"`
+}
+
+type Verdict struct {
+	Target common.Hash                                    `json:"target"`
+	Age    uint32                                         `json:"age"`
+	Votes  [common.NumOfSuperMajorityValidators]Judgement `json:"votes"`
+}
+
+type Culprit struct {
+	Target    common.Hash      `json:"target"`
+	Key       Ed25519Public    `json:"key"`
+	Signature Ed25519Signature `json:"signature"`
+}
+
+type Fault struct {
+	Target    common.Hash      `json:"target"`
+	Vote      bool             `json:"vote"`
+	Key       Ed25519Public    `json:"key"`
+	Signature Ed25519Signature `json:"signature"`
+}
+
+type DisputesExtrinsic struct {
+	Verdicts []Verdict `json:"verdicts"`
+	Culprits []Culprit `json:"culprits"`
+	Faults   []Fault   `json:"faults"`
+}
+
+type Extrinsic struct {
+	Tickets    TicketsExtrinsic    `json:"tickets"`
+	Preimages  PreimagesExtrinsic  `json:"preimages"`
+	Guarantees GuaranteesExtrinsic `json:"guarantees"`
+	Assurances AssurancesExtrinsic `json:"assurances"`
+	Disputes   DisputesExtrinsic   `json:"disputes"`
+}
+
+type ServiceInfo struct {
+	CodeHash   common.Hash     `json:"code_hash"`
+	Balance    service.Balance `json:"balance"`
+	MinItemGas service.Gas     `json:"min_item_gas"`
+	MinMemoGas service.Gas     `json:"min_memo_gas"`
+	Bytes      uint64          `json:"bytes"`
+	Items      uint32          `json:"items"`
+}
+
+type ServiceActivityRecord struct {
+	ProvidedCount     uint32      `json:"provided_count" codec:"compact"`
+	ProvidedSize      uint32      `json:"provided_size" codec:"compact"`
+	RefinementCount   uint32      `json:"refinement_count" codec:"compact"`
+	RefinementGasUsed service.Gas `json:"refinement_gas_used" codec:"compact"`
+	Imports           uint32      `json:"imports" codec:"compact"`
+	Exports           uint32      `json:"exports" codec:"compact"`
+	ExtrinsicSize     uint32      `json:"extrinsic_size" codec:"compact"`
+	ExtrinsicCount    uint32      `json:"extrinsic_count" codec:"compact"`
+	AccumulateCount   uint32      `json:"accumulate_count" codec:"compact"`
+	AccumulateGasUsed service.Gas `json:"accumulate_gas_used" codec:"compact"`
+	OnTransferCount   uint32      `json:"on_transfer_count" codec:"compact"`
+	OnTransferGasUsed service.Gas `json:"on_transfer_gas_used" codec:"compact"`
+}
+
+type ServiceStatisticsItem struct {
+	Id     service.ServiceId     `json:"id"`
+	Record ServiceActivityRecord `json:"record"`
+}
+
+// DecodeOptionals decodes a sequence of optional items ¿x into the pointers, nil where the item is absent.
+func DecodeOptionals[T any](d *codec.Decoder, items []*T) error {
+	for i := range items {
+		var item struct {
+			Value *T `codec:"optional"`
+		}
+		if err := d.Decode(&item); err != nil {
+			return errors.WithMessagef(err, "index %d", i)
+		}
+		items[i] = item.Value
+	}
+
+	return nil
+}
+
+// DecodeOutput decodes the output of a state transition test vector, 0 followed by the ok value,
+// or 1 followed by the index of the error code, and returns the name of the error code if any.
+// Errors of the outputs without error codes carry no index.
+func DecodeOutput(d *codec.Decoder, ok interface{}, errorCodes []string) (string, error) {
+	discriminator, err := d.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	switch discriminator {
+	case 0:
+		if ok == nil {
+			return "", nil
+		}
+		return "", d.Decode(ok)
+	case 1:
+		if errorCodes == nil {
+			return "", nil
+		}
+		code, err := d.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if int(code) >= len(errorCodes) {
+			return "", errors.Errorf("unknown error code: %d", code)
+		}
+		return errorCodes[code], nil
+	default:
+		return "", errors.Errorf("unknown output discriminator: %d", discriminator)
+	}
+}
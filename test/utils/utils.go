@@ -3,6 +3,9 @@ package test_utils
 import (
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/shunsukew/gojam/pkg/codec"
 )
 
 func GetJsonFilePaths(path string) ([]string, error) {
@@ -18,3 +21,18 @@ func GetJsonFilePaths(path string) ([]string, error) {
 	})
 	return files, err
 }
+
+// BinFilePath returns the path of the codec-encoded test vector shipped alongside the given JSON test vector.
+func BinFilePath(jsonFilePath string) string {
+	return strings.TrimSuffix(jsonFilePath, filepath.Ext(jsonFilePath)) + ".bin"
+}
+
+// DecodeBinFile reads the codec-encoded test vector and decodes it into v with the JAM codec.
+func DecodeBinFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return codec.Decode(data, v)
+}